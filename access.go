@@ -0,0 +1,37 @@
+package pdf
+
+// AccessibilityInfo tells whether a document is tagged. Marked, Suspects and
+// UserProperties are the entries of the /MarkInfo dictionary of the catalog.
+type AccessibilityInfo struct {
+	Marked         bool
+	Suspects       bool
+	UserProperties bool
+	Lang           string
+	StructTree     bool
+	RoleMap        bool
+}
+
+// Tagged reports whether the document claims to be tagged and has the
+// structure tree to back it.
+func (a AccessibilityInfo) Tagged() bool {
+	return a.Marked && a.StructTree
+}
+
+func (d *Document) AccessibilityInfo() AccessibilityInfo {
+	var ai AccessibilityInfo
+	root := d.getCatalog()
+	if root.isZero() {
+		return ai
+	}
+	ai.Lang = d.GetLang()
+	if mark, _ := d.resolve(root.getValue("markinfo")).(Dict); mark != nil {
+		ai.Marked = mark.GetBool("marked")
+		ai.Suspects = mark.GetBool("suspects")
+		ai.UserProperties = mark.GetBool("userproperties")
+	}
+	if tree, _ := d.resolve(root.getValue("structtreeroot")).(Dict); tree != nil {
+		ai.StructTree = true
+		ai.RoleMap = tree.Has("rolemap")
+	}
+	return ai
+}
@@ -0,0 +1,247 @@
+package pdf
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	ActionCatalog = "Catalog"
+	ActionNames   = "Names"
+	ActionPage    = "Page"
+	ActionAnnot   = "Annot"
+	ActionField   = "Field"
+	ActionOutline = "Outline"
+)
+
+// Action tells where an action was found (Source) and what runs it (Trigger).
+type Action struct {
+	Type    string
+	Source  string
+	Trigger string
+	Name    string
+	Page    int
+	Oid     string
+	Target  string
+}
+
+// Risky reports whether the action runs code or reaches outside of the document.
+func (a Action) Risky() bool {
+	switch a.Type {
+	case "JavaScript", "Launch", "SubmitForm", "ImportData", "URI", "GoToR", "GoToE":
+		return true
+	default:
+		return false
+	}
+}
+
+var triggerNames = map[string]string{
+	"e":  "E",
+	"x":  "X",
+	"d":  "D",
+	"u":  "U",
+	"fo": "Fo",
+	"bl": "Bl",
+	"po": "PO",
+	"pc": "PC",
+	"pv": "PV",
+	"pi": "PI",
+	"o":  "O",
+	"c":  "C",
+	"k":  "K",
+	"f":  "F",
+	"v":  "V",
+	"wc": "WC",
+	"ws": "WS",
+	"ds": "DS",
+	"wp": "WP",
+	"dp": "DP",
+}
+
+// GetActions lists the actions found anywhere in the document.
+func (d *Document) GetActions() []Action {
+	root := d.getCatalog()
+	if root.isZero() {
+		return nil
+	}
+	var (
+		list []Action
+		add  = func(v Value, base Action) {
+			list = d.appendActions(list, v, base, make(map[string]bool))
+		}
+		addObject = func(obj Object, base Action) {
+			base.Trigger = "A"
+			add(obj.getValue("a"), base)
+			d.addTriggers(obj.Dict, base, add)
+		}
+	)
+	add(root.getValue("openaction"), Action{Source: ActionCatalog, Trigger: "OpenAction", Oid: root.Oid})
+	d.addTriggers(root.Dict, Action{Source: ActionCatalog, Oid: root.Oid}, add)
+
+	if names, _ := d.resolve(root.getValue("names")).(Dict); names != nil {
+		d.walkNameTree(names.getValue("javascript"), func(name string, v Value) {
+			add(v, Action{Source: ActionNames, Trigger: "JavaScript", Name: name, Oid: root.Oid})
+		})
+	}
+	fields := d.getFieldNames()
+	d.WalkPages(func(n int, page Object) bool {
+		d.addTriggers(page.Dict, Action{Source: ActionPage, Page: n, Oid: page.Oid}, add)
+		for _, annot := range d.getPageAnnots(page) {
+			base := Action{Source: ActionAnnot, Page: n, Oid: annot.Oid}
+			if name, ok := fields[annot.Oid]; ok {
+				base.Source, base.Name = ActionField, name
+				delete(fields, annot.Oid)
+			}
+			addObject(annot, base)
+		}
+		return true
+	})
+	for _, oid := range sortedOids(fields) {
+		obj := d.getObjectWithOid(oid, false)
+		addObject(obj, Action{Source: ActionField, Name: fields[oid], Oid: oid})
+	}
+	d.walkOutlineItems(root, func(item Object) {
+		addObject(item, Action{Source: ActionOutline, Oid: item.Oid})
+	})
+	return list
+}
+
+func (d *Document) GetJavaScript() []Action {
+	var list []Action
+	for _, a := range d.GetActions() {
+		if a.Type == "JavaScript" {
+			list = append(list, a)
+		}
+	}
+	return list
+}
+
+// getFieldNames gives the full name of the fields by object id.
+func (d *Document) getFieldNames() map[string]string {
+	var (
+		names = make(map[string]string)
+		walk  func(string, string)
+	)
+	walk = func(oid, parent string) {
+		if _, ok := names[oid]; ok {
+			return
+		}
+		obj := d.getObjectWithOid(oid, false)
+		if obj.isZero() {
+			return
+		}
+		name := parent
+		if str := d.resolveString(obj.getValue("t")); str != "" {
+			if name != "" {
+				name += "."
+			}
+			name += str
+		}
+		names[oid] = name
+		for _, k := range obj.GetStringArray("kids") {
+			walk(k, name)
+		}
+	}
+	for _, oid := range d.getAcroForm().GetStringArray("fields") {
+		walk(oid, "")
+	}
+	return names
+}
+
+func sortedOids(set map[string]string) []string {
+	list := make([]string, 0, len(set))
+	for k := range set {
+		list = append(list, k)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return objectNumber(list[i]) < objectNumber(list[j])
+	})
+	return list
+}
+
+func (d *Document) addTriggers(dict Dict, base Action, add func(Value, Action)) {
+	aa, _ := d.resolve(dict.getValue("aa")).(Dict)
+	for _, k := range aa.keys() {
+		base.Trigger = triggerNames[k]
+		if base.Trigger == "" {
+			base.Trigger = strings.ToUpper(k)
+		}
+		add(aa[k], base)
+	}
+}
+
+func (d *Document) appendActions(list []Action, v Value, base Action, seen map[string]bool) []Action {
+	if oid, ok := toOid(v); ok {
+		if seen[oid] {
+			return list
+		}
+		seen[oid] = true
+		base.Oid = oid
+	}
+	switch v := d.resolve(v).(type) {
+	case Dict:
+		a := base
+		a.Type = v.GetString("s")
+		if a.Type == "" {
+			return list
+		}
+		switch a.Type {
+		case "JavaScript":
+			a.Target = d.getScript(v.getValue("js"))
+		case "URI":
+			a.Target = strings.TrimSpace(d.resolveString(v.getValue("uri")))
+		case "Launch":
+			a.Target = d.getFileSpec(v.getValue("f"))
+			if win, _ := d.resolve(v.getValue("win")).(Dict); a.Target == "" && win != nil {
+				a.Target = d.resolveString(win.getValue("f"))
+			}
+		case "SubmitForm", "ImportData", "GoToR", "GoToE":
+			a.Target = d.getFileSpec(v.getValue("f"))
+		}
+		list = append(list, a)
+		return d.appendActions(list, v.getValue("next"), base, seen)
+	case []interface{}:
+		for i := range v {
+			list = d.appendActions(list, v[i], base, seen)
+		}
+	}
+	return list
+}
+
+func (d *Document) getScript(v Value) string {
+	oid, ok := toOid(v)
+	if !ok {
+		str, _ := toString(v)
+		return str
+	}
+	obj := d.getObjectWithOid(oid, true)
+	if !obj.HasStream() {
+		str, _ := toString(obj.Data)
+		return str
+	}
+	body, _ := obj.Body()
+	return string(body)
+}
+
+func (d *Document) walkOutlineItems(root Object, fn func(Object)) {
+	var (
+		seen = make(map[string]bool)
+		walk func(string)
+	)
+	walk = func(oid string) {
+		for oid != "" && !seen[oid] {
+			seen[oid] = true
+			item := d.getObjectWithOid(oid, false)
+			if item.isZero() {
+				return
+			}
+			fn(item)
+			walk(item.GetString("first"))
+			oid = item.GetString("next")
+		}
+	}
+	outlines := d.getObjectWithOid(root.GetString("outlines"), false)
+	if !outlines.isZero() {
+		walk(outlines.GetString("first"))
+	}
+}
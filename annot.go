@@ -0,0 +1,93 @@
+package pdf
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	AnnotInvisible int64 = 1 << iota
+	AnnotHidden
+	AnnotPrint
+	AnnotNoZoom
+	AnnotNoRotate
+	AnnotNoView
+	AnnotReadOnly
+	AnnotLocked
+	AnnotToggleNoView
+	AnnotLockedContents
+)
+
+type Annotation struct {
+	Oid        string
+	Page       int
+	Subtype    string
+	Rect       Rect
+	Name       string
+	Contents   string
+	Author     string
+	Subject    string
+	Created    time.Time
+	Modified   time.Time
+	Flags      int64
+	Appearance string
+}
+
+func (a Annotation) Hidden() bool {
+	return a.Flags&(AnnotHidden|AnnotNoView) != 0
+}
+
+func (d *Document) GetAnnotations(page int) ([]Annotation, error) {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return nil, fmt.Errorf("page %d not found in document", page)
+	}
+	var list []Annotation
+	for _, annot := range d.getPageAnnots(obj) {
+		list = append(list, d.getAnnotation(annot, page))
+	}
+	return list, nil
+}
+
+func (d *Document) getPageAnnots(page Object) []Object {
+	var (
+		annots, _ = d.resolve(page.getValue("annots")).([]interface{})
+		list      []Object
+	)
+	for _, a := range annots {
+		oid, _ := toOid(a)
+		annot := d.getObjectWithOid(oid, false)
+		if annot.isZero() {
+			continue
+		}
+		list = append(list, annot)
+	}
+	return list
+}
+
+func (d *Document) getAnnotation(obj Object, page int) Annotation {
+	a := Annotation{
+		Oid:      obj.Oid,
+		Page:     page,
+		Subtype:  obj.Subtype(),
+		Rect:     Rect(getRect(d.resolve(obj.getValue("rect")))),
+		Name:     d.resolveString(obj.getValue("nm")),
+		Contents: d.resolveString(obj.getValue("contents")),
+		Subject:  d.resolveString(obj.getValue("subj")),
+	}
+	if a.Subtype != "Widget" {
+		a.Author = d.resolveString(obj.getValue("t"))
+	}
+	a.Flags, _ = d.resolve(obj.getValue("f")).(int64)
+	a.Created, _ = d.parseDate(d.resolveString(obj.getValue("creationdate")))
+	a.Modified, _ = d.parseDate(d.resolveString(obj.getValue("m")))
+
+	ap, _ := d.resolve(obj.getValue("ap")).(Dict)
+	switch n := ap.getValue("n").(type) {
+	case Reference:
+		a.Appearance = string(n)
+	case Dict:
+		a.Appearance = n.GetString(obj.GetString("as"))
+	}
+	return a
+}
@@ -0,0 +1,147 @@
+package pdf
+
+import (
+	"fmt"
+	"time"
+)
+
+type Attachment struct {
+	Name        string
+	Description string
+	MimeType    string
+	Size        int64
+	Created     time.Time
+	Modified    time.Time
+
+	oid string
+}
+
+func (d *Document) GetAttachments() []Attachment {
+	root := d.getCatalog()
+	if root.isZero() {
+		return nil
+	}
+	names, _ := d.resolve(root.getValue("names")).(Dict)
+	if names == nil {
+		return nil
+	}
+	var list []Attachment
+	d.walkNameTree(names.getValue("embeddedfiles"), func(name string, v Value) {
+		spec, _ := d.resolve(v).(Dict)
+		if spec == nil {
+			return
+		}
+		if a, ok := d.getAttachment(name, spec); ok {
+			list = append(list, a)
+		}
+	})
+	return list
+}
+
+func (d *Document) ReadAttachment(a Attachment) ([]byte, error) {
+	p, ok := d.lookupOid(a.oid)
+	if !ok || p.isEmbed() {
+		return nil, fmt.Errorf("attachment %s %w", a.Name, ErrMissing)
+	}
+	obj, err := d.readObjectAt(p.Offset, cryptKeys{}, true)
+	if err != nil {
+		return nil, err
+	}
+	if d.encrypt != "" {
+		name := d.eff
+		filters := obj.GetStringArray("filter")
+		if len(filters) == 0 {
+			filters = append(filters, obj.GetString("filter"))
+		}
+		var rest []string
+		for _, f := range filters {
+			if f == "Crypt" {
+				name = d.getStreamFilterName(obj)
+				continue
+			}
+			rest = append(rest, f)
+		}
+		key, err := d.filterKey(name, obj)
+		if err != nil {
+			return nil, err
+		}
+		if obj.Content, err = obj.Encoded(); err != nil {
+			return nil, err
+		}
+		obj.Content = decryptBytes(key, obj.Content)
+		obj.stream = nil
+		if len(rest) > 0 {
+			obj.Dict["filter"] = rest[0]
+		}
+	}
+	return obj.Body()
+}
+
+func (d *Document) getStreamFilterName(obj Object) string {
+	parms, _ := d.resolve(obj.getValue("decodeparms")).(Dict)
+	if name := parms.GetString("name"); name != "" {
+		return name
+	}
+	return identityFilter
+}
+
+func (d *Document) getAttachment(name string, spec Dict) (Attachment, bool) {
+	a := Attachment{
+		Name:        name,
+		Description: d.resolveString(spec.getValue("desc")),
+	}
+	if str := d.resolveString(spec.getValue("uf")); str != "" {
+		a.Name = str
+	} else if str := d.resolveString(spec.getValue("f")); str != "" {
+		a.Name = str
+	}
+	ef, _ := d.resolve(spec.getValue("ef")).(Dict)
+	if ef == nil {
+		return a, false
+	}
+	a.oid = ef.GetString("uf")
+	if a.oid == "" {
+		a.oid = ef.GetString("f")
+	}
+	obj := d.getObjectWithOid(a.oid, false)
+	if obj.isZero() {
+		return a, false
+	}
+	a.MimeType = obj.Subtype()
+	params, _ := d.resolve(obj.getValue("params")).(Dict)
+	if params != nil {
+		a.Size, _ = d.resolve(params.getValue("size")).(int64)
+		a.Created, _ = d.parseDate(d.resolveString(params.getValue("creationdate")))
+		a.Modified, _ = d.parseDate(d.resolveString(params.getValue("moddate")))
+	}
+	return a, true
+}
+
+func (d *Document) walkNameTree(v Value, fn func(string, Value)) {
+	var (
+		seen = make(map[string]bool)
+		walk func(Value)
+	)
+	walk = func(v Value) {
+		if oid, ok := toOid(v); ok {
+			if seen[oid] {
+				return
+			}
+			seen[oid] = true
+		}
+		node, _ := d.resolve(v).(Dict)
+		if node == nil {
+			return
+		}
+		names, _ := d.resolve(node.getValue("names")).([]interface{})
+		for i := 0; i+1 < len(names); i += 2 {
+			key, _ := toString(d.resolve(names[i]))
+			fn(key, names[i+1])
+		}
+		kids, _ := d.resolve(node.getValue("kids")).([]interface{})
+		for _, k := range kids {
+			walk(k)
+		}
+	}
+	walk(v)
+}
@@ -0,0 +1,168 @@
+package pdf
+
+import (
+	"container/list"
+	"sync"
+)
+
+const DefaultCacheSize = 16 << 20
+
+// WithCacheSize sets the number of bytes that the parsed objects and the
+// decoded bodies of object streams kept in memory can use. The least recently
+// used entries are evicted first. A size of zero or less disables the cache.
+func WithCacheSize(size int64) Option {
+	return func(d *Document) error {
+		d.cacheSize = size
+		return nil
+	}
+}
+
+type cacheEntry struct {
+	key   string
+	value interface{}
+	size  int64
+}
+
+type objectCache struct {
+	mu    sync.Mutex
+	limit int64
+	size  int64
+	list  *list.List
+	items map[string]*list.Element
+}
+
+func newObjectCache(limit int64) *objectCache {
+	if limit <= 0 {
+		return nil
+	}
+	return &objectCache{
+		limit: limit,
+		list:  list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *objectCache) get(key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.list.MoveToFront(e)
+	return e.Value.(*cacheEntry).value, true
+}
+
+func (c *objectCache) put(key string, value interface{}, size int64) {
+	if c == nil || size > c.limit {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+	e := c.list.PushFront(&cacheEntry{
+		key:   key,
+		value: value,
+		size:  size,
+	})
+	c.items[key] = e
+	c.size += size
+	for c.size > c.limit {
+		c.remove(c.list.Back())
+	}
+}
+
+func (c *objectCache) remove(e *list.Element) {
+	entry := c.list.Remove(e).(*cacheEntry)
+	delete(c.items, entry.key)
+	c.size -= entry.size
+}
+
+type objectStream struct {
+	Object
+	body []byte
+}
+
+func (d *Document) getCachedObject(oid string, full bool) (Object, bool) {
+	key := oid
+	if !full {
+		if v, ok := d.cache.get(key); ok {
+			return v.(Object), true
+		}
+		key += "/partial"
+	}
+	v, ok := d.cache.get(key)
+	if !ok {
+		return Object{}, false
+	}
+	return v.(Object), true
+}
+
+func (d *Document) cacheObject(obj Object, full bool) {
+	key := obj.Oid
+	if !full {
+		key += "/partial"
+	}
+	d.cache.put(key, obj, objectSize(obj))
+}
+
+// getObjectStream returns an object stream and its decoded body.
+func (d *Document) getObjectStream(oid string) (objectStream, error) {
+	if v, ok := d.cache.get(oid + "/body"); ok {
+		return v.(objectStream), nil
+	}
+	obj := d.getObjectWithOid(oid, true)
+	body, err := obj.Body()
+	if err != nil {
+		return objectStream{}, err
+	}
+	stm := objectStream{
+		Object: obj,
+		body:   body,
+	}
+	d.cache.put(oid+"/body", stm, int64(len(body))+objectSize(obj))
+	return stm, nil
+}
+
+// objectSize gives a rough estimate of the memory used by an object.
+func objectSize(obj Object) int64 {
+	size := int64(len(obj.Oid)+len(obj.Content)) + 64
+	if obj.Dict != nil {
+		size += valueSize(obj.Dict)
+	} else {
+		size += valueSize(obj.Data)
+	}
+	return size
+}
+
+func valueSize(v Value) int64 {
+	switch v := v.(type) {
+	case Dict:
+		var size int64
+		for k, e := range v {
+			size += int64(len(k)) + valueSize(e) + 16
+		}
+		return size
+	case []interface{}:
+		var size int64
+		for _, e := range v {
+			size += valueSize(e) + 16
+		}
+		return size
+	case StringValue:
+		return int64(len(v)) + 24
+	case string:
+		return int64(len(v)) + 16
+	case NameValue:
+		return int64(len(v)) + 16
+	case Reference:
+		return int64(len(v)) + 16
+	default:
+		return 16
+	}
+}
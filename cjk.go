@@ -0,0 +1,218 @@
+package pdf
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// WithCMapDir gives the directory of the Adobe predefined CMap files.
+func WithCMapDir(dir string) Option {
+	return func(d *Document) error {
+		d.cmapDir = dir
+		return nil
+	}
+}
+
+const maxUseCMap = 8
+
+type charset struct {
+	ranges []codeRange
+	enc    encoding.Encoding
+	high   bool
+}
+
+var (
+	eucRanges  = codeSpace("00", "80", "A1A1", "FEFE")
+	gbkRanges  = codeSpace("00", "80", "8140", "FEFE")
+	big5Ranges = codeSpace("00", "80", "A140", "FEFE")
+	rksjRanges = codeSpace("00", "80", "A0", "DF", "8140", "9FFC", "E040", "FCFC")
+	jisRanges  = codeSpace("2121", "7E7E")
+)
+
+// charsets gives the character set of the non Unicode CMaps, without -H or -V.
+var charsets = map[string]charset{
+	"GB-EUC":     {ranges: eucRanges, enc: simplifiedchinese.GBK},
+	"GBpc-EUC":   {ranges: eucRanges, enc: simplifiedchinese.GBK},
+	"GBK-EUC":    {ranges: gbkRanges, enc: simplifiedchinese.GBK},
+	"GBKp-EUC":   {ranges: gbkRanges, enc: simplifiedchinese.GBK},
+	"GBK2K":      {ranges: codeSpace("00", "80", "8140", "FEFE", "81308130", "FE39FE39"), enc: simplifiedchinese.GB18030},
+	"GB":         {ranges: jisRanges, enc: simplifiedchinese.GBK, high: true},
+	"B5pc":       {ranges: big5Ranges, enc: traditionalchinese.Big5},
+	"ETen-B5":    {ranges: big5Ranges, enc: traditionalchinese.Big5},
+	"ETenms-B5":  {ranges: big5Ranges, enc: traditionalchinese.Big5},
+	"HKscs-B5":   {ranges: big5Ranges, enc: traditionalchinese.Big5},
+	"83pv-RKSJ":  {ranges: rksjRanges, enc: japanese.ShiftJIS},
+	"90ms-RKSJ":  {ranges: rksjRanges, enc: japanese.ShiftJIS},
+	"90msp-RKSJ": {ranges: rksjRanges, enc: japanese.ShiftJIS},
+	"90pv-RKSJ":  {ranges: rksjRanges, enc: japanese.ShiftJIS},
+	"Add-RKSJ":   {ranges: rksjRanges, enc: japanese.ShiftJIS},
+	"Ext-RKSJ":   {ranges: rksjRanges, enc: japanese.ShiftJIS},
+	"EUC":        {ranges: codeSpace("00", "80", "8EA0", "8EDF", "A1A1", "FEFE"), enc: japanese.EUCJP},
+	"":           {ranges: jisRanges, enc: japanese.EUCJP, high: true},
+	"KSC-EUC":    {ranges: eucRanges, enc: korean.EUCKR},
+	"KSCpc-EUC":  {ranges: eucRanges, enc: korean.EUCKR},
+	"KSCms-UHC":  {ranges: codeSpace("00", "80", "8141", "FEFE"), enc: korean.EUCKR},
+	"KSC":        {ranges: jisRanges, enc: korean.EUCKR, high: true},
+}
+
+var predefined = struct {
+	sync.Mutex
+	cmaps map[string]*cmap
+}{
+	cmaps: make(map[string]*cmap),
+}
+
+// getPredefinedCMap loads the predefined CMap name once and keeps it.
+func (d *Document) getPredefinedCMap(name string) *cmap {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil
+	}
+	key := filepath.Join(d.cmapDir, name)
+
+	predefined.Lock()
+	defer predefined.Unlock()
+	if cm, ok := predefined.cmaps[key]; ok {
+		return cm
+	}
+	var (
+		cm           = readCMapFile(d.cmapDir, name, 0)
+		ranges, text = predefinedText(name)
+	)
+	switch {
+	case cm != nil:
+		if cm.text == nil {
+			cm.text = text
+		}
+	case text != nil:
+		cm = &cmap{
+			ranges: ranges,
+			text:   text,
+		}
+	}
+	predefined.cmaps[key] = cm
+	return cm
+}
+
+func readCMapFile(dir, name string, depth int) *cmap {
+	if dir == "" || depth >= maxUseCMap {
+		return nil
+	}
+	buf, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil
+	}
+	cm := parseCMap(buf)
+	if cm.usecmap != "" && cm.usecmap != name {
+		cm.inherit(readCMapFile(dir, cm.usecmap, depth+1))
+	}
+	if len(cm.ranges) == 0 {
+		return nil
+	}
+	return cm
+}
+
+func predefinedText(name string) ([]codeRange, func(uint32) (string, bool)) {
+	for _, mode := range []string{"H", "V"} {
+		if name == mode {
+			name = ""
+		}
+		name = strings.TrimSuffix(name, "-"+mode)
+	}
+	if strings.HasPrefix(name, "Uni") {
+		parts := strings.Split(name, "-")
+		if len(parts) < 2 {
+			return nil, nil
+		}
+		switch parts[1] {
+		case "UCS2":
+			return codeSpace("0000", "FFFF"), decodeUTF32
+		case "UTF16":
+			return codeSpace("0000", "D7FF", "E000", "FFFF", "D800DC00", "DBFFDFFF"), decodeUTF16
+		case "UTF32":
+			return codeSpace("00000000", "0010FFFF"), decodeUTF32
+		case "UTF8":
+			return codeSpace("00", "7F", "C080", "DFBF", "E08080", "EFBFBF", "F0808080", "F7BFBFBF"), decodeUTF8
+		}
+		return nil, nil
+	}
+	set, ok := charsets[strings.TrimSuffix(name, "-HW")]
+	if !ok {
+		return nil, nil
+	}
+	text := func(code uint32) (string, bool) {
+		buf := codeBytes(code)
+		if set.high {
+			for i := range buf {
+				buf[i] |= 0x80
+			}
+		}
+		str, err := set.enc.NewDecoder().Bytes(buf)
+		if err != nil || len(str) == 0 || strings.ContainsRune(string(str), utf8.RuneError) {
+			return "", false
+		}
+		return string(str), true
+	}
+	return set.ranges, text
+}
+
+func decodeUTF32(code uint32) (string, bool) {
+	r := rune(code)
+	if !utf8.ValidRune(r) {
+		return "", false
+	}
+	return string(r), true
+}
+
+func decodeUTF16(code uint32) (string, bool) {
+	if code <= 0xffff {
+		return decodeUTF32(code)
+	}
+	r := utf16.DecodeRune(rune(code>>16), rune(code&0xffff))
+	if r == utf8.RuneError {
+		return "", false
+	}
+	return string(r), true
+}
+
+func decodeUTF8(code uint32) (string, bool) {
+	buf := codeBytes(code)
+	r, n := utf8.DecodeRune(buf)
+	if r == utf8.RuneError || n != len(buf) {
+		return "", false
+	}
+	return string(r), true
+}
+
+// codeBytes returns the bytes of a code without its leading zeros.
+func codeBytes(code uint32) []byte {
+	var buf []byte
+	for ; code > 0; code >>= 8 {
+		buf = append([]byte{byte(code)}, buf...)
+	}
+	if len(buf) == 0 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+func codeSpace(bounds ...string) []codeRange {
+	var list []codeRange
+	for i := 0; i+1 < len(bounds); i += 2 {
+		r := codeRange{
+			lo: hexBytes("<" + bounds[i] + ">"),
+			hi: hexBytes("<" + bounds[i+1] + ">"),
+		}
+		list = append(list, r)
+	}
+	return list
+}
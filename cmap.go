@@ -0,0 +1,441 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+type codeRange struct {
+	lo []byte
+	hi []byte
+}
+
+func (c codeRange) match(str string) bool {
+	if len(str) < len(c.lo) {
+		return false
+	}
+	for i := range c.lo {
+		if str[i] < c.lo[i] || str[i] > c.hi[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type cmap struct {
+	ranges  []codeRange
+	codes   map[uint32]string
+	cids    map[uint32]uint32
+	text    func(uint32) (string, bool)
+	usecmap string
+}
+
+func identityCMap() *cmap {
+	r := codeRange{
+		lo: []byte{0x00, 0x00},
+		hi: []byte{0xff, 0xff},
+	}
+	return &cmap{
+		ranges: []codeRange{r},
+		codes:  make(map[uint32]string),
+	}
+}
+
+func (c *cmap) split(str string) (uint32, int) {
+	size := 0
+	for _, r := range c.ranges {
+		if r.match(str) {
+			size = len(r.lo)
+			break
+		}
+		if size == 0 || len(r.lo) < size {
+			size = len(r.lo)
+		}
+	}
+	if size == 0 {
+		size = 1
+	}
+	if size > len(str) {
+		size = len(str)
+	}
+	var code uint32
+	for i := 0; i < size; i++ {
+		code = code<<8 | uint32(str[i])
+	}
+	return code, size
+}
+
+func (c *cmap) lookup(code uint32) (string, bool) {
+	if str, ok := c.codes[code]; ok {
+		return str, ok
+	}
+	if c.text != nil {
+		return c.text(code)
+	}
+	return "", false
+}
+
+func (c *cmap) cid(code uint32) (uint32, bool) {
+	if c.cids == nil {
+		return code, c.text == nil
+	}
+	cid, ok := c.cids[code]
+	return cid, ok
+}
+
+func parseToUnicode(buf []byte) map[uint32]rune {
+	codes := make(map[uint32]rune)
+	for c, str := range parseCMap(buf).codes {
+		for _, r := range str {
+			codes[c] = r
+			break
+		}
+	}
+	return codes
+}
+
+func (c *cmap) setCID(code, cid uint32) {
+	if c.cids == nil {
+		c.cids = make(map[uint32]uint32)
+	}
+	c.cids[code] = cid
+}
+
+// inherit completes c with the CMap it uses.
+func (c *cmap) inherit(parent *cmap) {
+	if parent == nil {
+		return
+	}
+	c.ranges = append(c.ranges, parent.ranges...)
+	for code, cid := range parent.cids {
+		if _, ok := c.cids[code]; !ok {
+			c.setCID(code, cid)
+		}
+	}
+	if c.text == nil {
+		c.text = parent.text
+	}
+}
+
+func parseCMap(buf []byte) *cmap {
+	var (
+		cm = cmap{
+			codes: make(map[uint32]string),
+		}
+		toks = tokenizeCMap(buf)
+	)
+	for i := 0; i < len(toks); i++ {
+		switch toks[i] {
+		case "usecmap":
+			if i > 0 {
+				cm.usecmap = strings.TrimPrefix(toks[i-1], "/")
+			}
+		case "begincodespacerange":
+			for i++; i+1 < len(toks) && toks[i] != "endcodespacerange"; i += 2 {
+				lo, hi := hexBytes(toks[i]), hexBytes(toks[i+1])
+				if len(lo) == 0 || len(lo) != len(hi) {
+					continue
+				}
+				cm.ranges = append(cm.ranges, codeRange{lo: lo, hi: hi})
+			}
+		case "begincidchar":
+			for i++; i+1 < len(toks) && toks[i] != "endcidchar"; i += 2 {
+				if cid, err := strconv.ParseUint(toks[i+1], 10, 32); err == nil {
+					cm.setCID(hexCode(toks[i]), uint32(cid))
+				}
+			}
+		case "begincidrange":
+			for i++; i+2 < len(toks) && toks[i] != "endcidrange"; i += 3 {
+				lo, hi := hexCode(toks[i]), hexCode(toks[i+1])
+				cid, err := strconv.ParseUint(toks[i+2], 10, 32)
+				if err != nil || hi < lo || hi-lo >= 0x10000 {
+					continue
+				}
+				for n := uint32(0); n <= hi-lo; n++ {
+					cm.setCID(lo+n, uint32(cid)+n)
+				}
+			}
+		case "beginbfchar":
+			for i++; i+1 < len(toks) && toks[i] != "endbfchar"; i += 2 {
+				if dst := hexRunes(toks[i+1]); len(dst) > 0 {
+					cm.codes[hexCode(toks[i])] = string(dst)
+				}
+			}
+		case "beginbfrange":
+			for i++; i+2 < len(toks) && toks[i] != "endbfrange"; {
+				lo, hi := hexCode(toks[i]), hexCode(toks[i+1])
+				if toks[i+2] != "[" {
+					if dst := hexRunes(toks[i+2]); len(dst) > 0 && hi >= lo && hi-lo < 0x10000 {
+						last := len(dst) - 1
+						for n := uint32(0); n <= hi-lo; n++ {
+							dst[last] = dst[last] + rune(n)
+							cm.codes[lo+n] = string(dst)
+							dst[last] = dst[last] - rune(n)
+						}
+					}
+					i += 3
+					continue
+				}
+				j := i + 3
+				for c := lo; j < len(toks) && toks[j] != "]"; j, c = j+1, c+1 {
+					if dst := hexRunes(toks[j]); len(dst) > 0 {
+						cm.codes[c] = string(dst)
+					}
+				}
+				i = j + 1
+			}
+		}
+	}
+	return &cm
+}
+
+func tokenizeCMap(buf []byte) []string {
+	var toks []string
+	for i := 0; i < len(buf); {
+		switch b := buf[i]; {
+		case isBlank(b) || b == formfeed || b == 0:
+			i++
+		case b == percent:
+			for i < len(buf) && buf[i] != nl && buf[i] != cr {
+				i++
+			}
+		case b == langle && i+1 < len(buf) && buf[i+1] == langle:
+			toks = append(toks, "<<")
+			i += 2
+		case b == rangle && i+1 < len(buf) && buf[i+1] == rangle:
+			toks = append(toks, ">>")
+			i += 2
+		case b == langle:
+			j := i + 1
+			for j < len(buf) && buf[j] != rangle {
+				j++
+			}
+			toks = append(toks, string(buf[i:j]))
+			i = j + 1
+		case b == lparen:
+			j := scanLiteral(buf, i)
+			toks = append(toks, string(buf[i:j]))
+			i = j
+		case b == lsquare || b == rsquare:
+			toks = append(toks, string(b))
+			i++
+		default:
+			j := i + 1
+			for ; j < len(buf); j++ {
+				if isBlank(buf[j]) || isDelimiter(buf[j]) {
+					break
+				}
+			}
+			tok := string(buf[i:j])
+			toks = append(toks, tok)
+			i = j
+			if tok == string(begInline) {
+				if x := indexInlineEnd(buf[i:]); x >= 0 {
+					i += x
+				}
+			}
+		}
+	}
+	return toks
+}
+
+func hexBytes(tok string) []byte {
+	if len(tok) == 0 || tok[0] != langle {
+		return nil
+	}
+	var str []byte
+	for i := 1; i < len(tok); i++ {
+		if isHex(tok[i]) {
+			str = append(str, tok[i])
+		}
+	}
+	if len(str)%2 == 1 {
+		str = append(str, '0')
+	}
+	buf, _ := hex.DecodeString(string(str))
+	return buf
+}
+
+func hexCode(tok string) uint32 {
+	var code uint32
+	for _, b := range hexBytes(tok) {
+		code = code<<8 | uint32(b)
+	}
+	return code
+}
+
+func hexRunes(tok string) []rune {
+	buf := hexBytes(tok)
+	if len(buf) == 1 {
+		return []rune{rune(buf[0])}
+	}
+	chars := make([]uint16, 0, len(buf)/2)
+	for i := 0; i+1 < len(buf); i += 2 {
+		chars = append(chars, binary.BigEndian.Uint16(buf[i:]))
+	}
+	return utf16.Decode(chars)
+}
+
+func parseTrueTypeCmap(buf []byte) map[uint16]rune {
+	table := sfntTable(buf, "cmap")
+	if len(table) < 4 {
+		return nil
+	}
+	var (
+		count = int(binary.BigEndian.Uint16(table[2:]))
+		best  []byte
+		rank  int
+	)
+	for i := 0; i < count; i++ {
+		pos := 4 + i*8
+		if pos+8 > len(table) {
+			break
+		}
+		var (
+			platform = binary.BigEndian.Uint16(table[pos:])
+			encoding = binary.BigEndian.Uint16(table[pos+2:])
+			offset   = binary.BigEndian.Uint32(table[pos+4:])
+			r        int
+		)
+		switch {
+		case platform == 3 && encoding == 10:
+			r = 3
+		case platform == 3 && encoding == 1:
+			r = 2
+		case platform == 0:
+			r = 1
+		}
+		if r > rank && int(offset) < len(table) {
+			best, rank = table[offset:], r
+		}
+	}
+	return parseCmapSubtable(best)
+}
+
+func sfntTable(buf []byte, tag string) []byte {
+	if len(buf) < 12 {
+		return nil
+	}
+	count := int(binary.BigEndian.Uint16(buf[4:]))
+	for i := 0; i < count; i++ {
+		pos := 12 + i*16
+		if pos+16 > len(buf) {
+			return nil
+		}
+		if string(buf[pos:pos+4]) != tag {
+			continue
+		}
+		var (
+			offset = binary.BigEndian.Uint32(buf[pos+8:])
+			length = binary.BigEndian.Uint32(buf[pos+12:])
+		)
+		if uint64(offset)+uint64(length) > uint64(len(buf)) {
+			return nil
+		}
+		return buf[offset : offset+length]
+	}
+	return nil
+}
+
+func parseCmapSubtable(buf []byte) map[uint16]rune {
+	if len(buf) < 2 {
+		return nil
+	}
+	switch binary.BigEndian.Uint16(buf) {
+	case 0:
+		return parseCmapFormat0(buf)
+	case 4:
+		return parseCmapFormat4(buf)
+	case 12:
+		return parseCmapFormat12(buf)
+	default:
+		return nil
+	}
+}
+
+func parseCmapFormat0(buf []byte) map[uint16]rune {
+	if len(buf) < 6+256 {
+		return nil
+	}
+	glyphs := make(map[uint16]rune)
+	for c, gid := range buf[6 : 6+256] {
+		if _, ok := glyphs[uint16(gid)]; gid != 0 && !ok {
+			glyphs[uint16(gid)] = rune(c)
+		}
+	}
+	return glyphs
+}
+
+func parseCmapFormat4(buf []byte) map[uint16]rune {
+	if len(buf) < 14 {
+		return nil
+	}
+	var (
+		glyphs = make(map[uint16]rune)
+		segs   = int(binary.BigEndian.Uint16(buf[6:])) / 2
+		ends   = 14
+		starts = ends + segs*2 + 2
+		deltas = starts + segs*2
+		ranges = deltas + segs*2
+	)
+	if ranges+segs*2 > len(buf) {
+		return nil
+	}
+	for i := 0; i < segs; i++ {
+		var (
+			end   = int(binary.BigEndian.Uint16(buf[ends+i*2:]))
+			start = int(binary.BigEndian.Uint16(buf[starts+i*2:]))
+			delta = int(binary.BigEndian.Uint16(buf[deltas+i*2:]))
+			ro    = int(binary.BigEndian.Uint16(buf[ranges+i*2:]))
+		)
+		for c := start; c <= end && c != 0xffff; c++ {
+			gid := (c + delta) & 0xffff
+			if ro != 0 {
+				pos := ranges + i*2 + ro + 2*(c-start)
+				if pos+2 > len(buf) {
+					break
+				}
+				if gid = int(binary.BigEndian.Uint16(buf[pos:])); gid != 0 {
+					gid = (gid + delta) & 0xffff
+				}
+			}
+			if _, ok := glyphs[uint16(gid)]; gid != 0 && !ok {
+				glyphs[uint16(gid)] = rune(c)
+			}
+		}
+	}
+	return glyphs
+}
+
+func parseCmapFormat12(buf []byte) map[uint16]rune {
+	if len(buf) < 16 {
+		return nil
+	}
+	var (
+		glyphs = make(map[uint16]rune)
+		count  = int(binary.BigEndian.Uint32(buf[12:]))
+	)
+	for i := 0; i < count; i++ {
+		pos := 16 + i*12
+		if pos+12 > len(buf) {
+			break
+		}
+		var (
+			start = binary.BigEndian.Uint32(buf[pos:])
+			end   = binary.BigEndian.Uint32(buf[pos+4:])
+			gid   = binary.BigEndian.Uint32(buf[pos+8:])
+		)
+		if end < start || end-start >= 0x10000 {
+			continue
+		}
+		for n := uint32(0); n <= end-start; n++ {
+			if _, ok := glyphs[uint16(gid+n)]; gid+n != 0 && !ok {
+				glyphs[uint16(gid+n)] = rune(start + n)
+			}
+		}
+	}
+	return glyphs
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/midbel/pdf"
+)
+
+func main() {
+	text := flag.Bool("t", false, "show the lines of text that changed")
+	flag.Parse()
+
+	a, err := pdf.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer a.Close()
+	b, err := pdf.Open(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer b.Close()
+
+	changes, err := pdf.Diff(a, b)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, c := range changes {
+		printChange(c, *text)
+	}
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+func printChange(c pdf.Change, text bool) {
+	switch c.Section {
+	case "pages", "info":
+		fmt.Printf("%-8s %-8s %s: %q -> %q", c.Kind, c.Section, c.Key, c.Old, c.New)
+	default:
+		fmt.Printf("%-8s %-8s %s", c.Kind, c.Section, c.Key)
+	}
+	fmt.Println()
+	if text && c.Section == "text" {
+		printLines(lines(c.Old), lines(c.New))
+	}
+}
+
+func lines(str string) []string {
+	if str == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(str, "\n"), "\n")
+}
+
+// printLines prints the lines removed from old and added to curr.
+func printLines(old, curr []string) {
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(curr)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(curr) - 1; j >= 0; j-- {
+			switch {
+			case old[i] == curr[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var i, j int
+	for i < len(old) || j < len(curr) {
+		switch {
+		case i < len(old) && j < len(curr) && old[i] == curr[j]:
+			i++
+			j++
+		case j >= len(curr) || (i < len(old) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Printf("\t- %s\n", old[i])
+			i++
+		default:
+			fmt.Printf("\t+ %s\n", curr[j])
+			j++
+		}
+	}
+}
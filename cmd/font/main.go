@@ -9,6 +9,7 @@ import (
 )
 
 func main() {
+	page := flag.Int("p", 0, "list the fonts of the given page")
 	flag.Parse()
 
 	doc, err := pdf.Open(flag.Arg(0))
@@ -17,14 +18,29 @@ func main() {
 		os.Exit(1)
 	}
 	defer doc.Close()
-	for _, f := range doc.GetFonts() {
+
+	fonts := doc.GetFonts()
+	if *page > 0 {
+		if fonts, err = doc.GetPageFonts(*page); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	for _, f := range fonts {
 		printFont(f)
 	}
 }
 
-const row = "%-8s | %-36s | %-24s | %-24s | %5t | 0x%02x - 0x%02x"
+const row = "%-8s | %-36s | %-24s | %-24s | %5t | 0x%02x - 0x%02x | %s"
 
 func printFont(f pdf.Font) {
-	fmt.Printf(row, f.Name, f.Base, f.Sub, f.Encoding, f.Unicode, f.First, f.Last)
+	embedded := "not embedded"
+	if f.Embedded {
+		embedded = "embedded"
+	}
+	if f.Subset {
+		embedded += " subset"
+	}
+	fmt.Printf(row, f.Name, f.Base, f.Sub, f.Encoding, f.Unicode, f.First, f.Last, embedded)
 	fmt.Println()
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/midbel/pdf"
+)
+
+func main() {
+	var (
+		format   = "json"
+		diff     bool
+		password string
+	)
+	flag.StringVar(&format, "f", format, "output format (fdf, xfdf, json)")
+	flag.BoolVar(&diff, "diff", diff, "compare form data of two files")
+	flag.StringVar(&password, "password", password, "password")
+	flag.Parse()
+
+	var opts []pdf.Option
+	if password != "" {
+		opts = append(opts, pdf.WithPassword(password))
+	}
+	var err error
+	if diff {
+		err = diffForms(flag.Arg(0), flag.Arg(1), opts...)
+	} else {
+		err = dumpForm(flag.Arg(0), format, opts...)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func dumpForm(file, format string, opts ...pdf.Option) error {
+	var ff pdf.FormFormat
+	switch strings.ToLower(format) {
+	case "fdf":
+		ff = pdf.FormFDF
+	case "xfdf":
+		ff = pdf.FormXFDF
+	case "json", "":
+		ff = pdf.FormJSON
+	default:
+		return fmt.Errorf("%s: unsupported format", format)
+	}
+	doc, err := openDocument(file, opts...)
+	if err != nil {
+		return err
+	}
+	defer doc.Close()
+	return doc.ExportForm(os.Stdout, ff)
+}
+
+func diffForms(old, new string, opts ...pdf.Option) error {
+	before, err := readValues(old, opts...)
+	if err != nil {
+		return err
+	}
+	after, err := readValues(new, opts...)
+	if err != nil {
+		return err
+	}
+	for _, name := range before.names {
+		v, ok := after.values[name]
+		switch {
+		case !ok:
+			fmt.Printf("- %s: %s", name, before.values[name])
+			fmt.Println()
+		case v != before.values[name]:
+			fmt.Printf("~ %s: %s => %s", name, before.values[name], v)
+			fmt.Println()
+		}
+	}
+	for _, name := range after.names {
+		if _, ok := before.values[name]; !ok {
+			fmt.Printf("+ %s: %s", name, after.values[name])
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+type formValues struct {
+	names  []string
+	values map[string]string
+}
+
+func readValues(file string, opts ...pdf.Option) (formValues, error) {
+	doc, err := openDocument(file, opts...)
+	if err != nil {
+		return formValues{}, err
+	}
+	defer doc.Close()
+
+	fv := formValues{
+		values: make(map[string]string),
+	}
+	for _, f := range doc.GetFields() {
+		if f.Name == "" {
+			continue
+		}
+		if _, ok := fv.values[f.Name]; !ok {
+			fv.names = append(fv.names, f.Name)
+		}
+		fv.values[f.Name] = strings.Join(f.Values(), ", ")
+	}
+	return fv, nil
+}
+
+func openDocument(file string, opts ...pdf.Option) (*pdf.Document, error) {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		return pdf.OpenURL(file, opts...)
+	}
+	return pdf.Open(file, opts...)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/midbel/pdf"
+)
+
+func main() {
+	var (
+		dir = flag.String("d", ".", "output directory")
+		raw = flag.Bool("r", false, "keep raw color channels")
+	)
+	flag.Parse()
+	doc, err := pdf.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, pi := range doc.GetImages() {
+		if err := writeImage(doc, pi, *dir, *raw); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s", pi.Name, err)
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
+
+func writeImage(doc *pdf.Document, pi pdf.PageImage, dir string, raw bool) error {
+	o, err := doc.GetPageImageObject(pi.Page, pi.Index)
+	if err != nil {
+		return err
+	}
+	if raw {
+		return writeRaw(doc, o, pi, dir)
+	}
+	img, err := doc.DecodeImage(o, pdf.ImageRGB)
+	if errors.Is(err, pdf.ErrUnsupported) {
+		return writeRaw(doc, o, pi, dir)
+	}
+	if err != nil {
+		return err
+	}
+	w, err := os.Create(filepath.Join(dir, pi.Name+".png"))
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return png.Encode(w, img)
+}
+
+func writeRaw(doc *pdf.Document, o pdf.Object, pi pdf.PageImage, dir string) error {
+	ri, err := doc.ReadRawImage(o)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(dir, pi.Name+ri.Ext())
+	return os.WriteFile(file, ri.Data, 0644)
+}
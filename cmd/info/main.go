@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/x509"
 	"flag"
 	"fmt"
 	"os"
@@ -13,18 +14,52 @@ import (
 const timePattern = "2006-01-02 15:04:05"
 
 func main() {
+	var (
+		password = flag.String("password", "", "password")
+		verify   = flag.Bool("verify", false, "verify signatures")
+		roots    = flag.String("roots", "", "PEM file with trusted root certificates")
+		revision = flag.Int("revision", -1, "show information of the given revision")
+		strict   = flag.Bool("strict", false, "report every deviation from the specification")
+		lenient  = flag.Bool("lenient", false, "tolerate common deviations from the specification")
+	)
 	flag.Parse()
-	doc, err := pdf.Open(flag.Arg(0))
+
+	opts := []pdf.Option{pdf.WithLocked()}
+	switch {
+	case *strict:
+		opts = append(opts, pdf.WithParseMode(pdf.ParseStrict))
+	case *lenient:
+		opts = append(opts, pdf.WithParseMode(pdf.ParseLenient))
+	}
+	if *password != "" {
+		opts = append(opts, pdf.WithPassword(*password))
+	}
+	doc, err := openDocument(flag.Arg(0), opts...)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 	defer doc.Close()
 
-	info := doc.GetDocumentInfo()
+	if *revision >= 0 {
+		rev, err := doc.OpenRevision(*revision)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer rev.Close()
+		doc = rev
+	}
+
 	printLine("version", "PDF-"+doc.GetVersion())
+	if ei := doc.EncryptionInfo(); ei.Encrypted && !ei.Unlocked {
+		printSecurity(doc)
+		return
+	}
+	info := doc.GetDocumentInfo()
 	printLine("title", info.Title)
 	printLine("language", doc.GetLang())
+	printAccessibility(doc)
 	printLine("author", info.Author)
 	printLine("subject", info.Subject)
 	if !info.Created.IsZero() {
@@ -36,14 +71,192 @@ func main() {
 	if len(info.Keywords) > 0 {
 		printLine("keywords", strings.Join(info.Keywords, ", "))
 	}
+	var pool *x509.CertPool
+	if *roots != "" {
+		if pool, err = readRoots(*roots); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
 	for _, sig := range doc.GetSignatures() {
-		if sig.When.IsZero() {
+		if sig.DocTimeStamp {
+			printTimeStamp(sig)
+		} else if sig.When.IsZero() {
 			printLine("signed by", sig.Who)
 		} else {
 			printLine("signed by", fmt.Sprintf("%s (%s)", sig.Who, sig.When.Format(timePattern)))
 		}
+		if *verify || pool != nil {
+			printCheck(doc, sig, pool)
+		}
 	}
 	printLine("pages", strconv.FormatInt(doc.GetCount(), 10))
+	printLabels(doc)
+	printViewer(doc)
+	printRevisions(doc)
+	if dss, err := doc.GetDSS(); err == nil {
+		printLine("dss", fmt.Sprintf("%d certificates, %d CRLs, %d OCSP responses", len(dss.Certs), len(dss.CRLs), len(dss.OCSPs)))
+	}
+	printSecurity(doc)
+}
+
+func printSecurity(doc *pdf.Document) {
+	ei := doc.EncryptionInfo()
+	if !ei.Encrypted {
+		return
+	}
+	printLine("security", ei.Filter)
+	if ei.Unlocked {
+		printLine("password", ei.Password.String())
+	} else {
+		printLine("password", "locked")
+	}
+	printLine("algorithm", fmt.Sprintf("%s (%d bits, V%d R%d)", ei.Algorithm, ei.Length, ei.Version, ei.Revision))
+	if ei.EmbeddedOnly {
+		printLine("encrypts", "embedded files only")
+	}
+	var (
+		perm  = doc.Permissions()
+		perms = perm.List()
+	)
+	if len(perms) == 0 {
+		perms = append(perms, "none")
+	}
+	printLine("permissions", fmt.Sprintf("%s (P=%d)", strings.Join(perms, ", "), perm.Flags))
+}
+
+func printRevisions(doc *pdf.Document) {
+	list, err := doc.Revisions()
+	if err != nil || len(list) <= 1 {
+		return
+	}
+	var parts []string
+	for _, r := range list {
+		parts = append(parts, fmt.Sprintf("%d-%d", r.Start, r.End))
+	}
+	printLine("revisions", fmt.Sprintf("%d (%s)", len(list), strings.Join(parts, ", ")))
+}
+
+func printAccessibility(doc *pdf.Document) {
+	ai := doc.AccessibilityInfo()
+	if !ai.Marked && !ai.StructTree {
+		return
+	}
+	var list []string
+	if ai.Marked {
+		list = append(list, "marked")
+	}
+	if ai.Suspects {
+		list = append(list, "suspects")
+	}
+	if ai.StructTree {
+		list = append(list, "structure tree")
+	}
+	str := "yes"
+	if !ai.Tagged() {
+		str = "partial"
+	}
+	printLine("tagged", fmt.Sprintf("%s (%s)", str, strings.Join(list, ", ")))
+}
+
+func printViewer(doc *pdf.Document) {
+	vp := doc.GetViewerPreferences()
+	if vp.PageLayout != "SinglePage" {
+		printLine("layout", vp.PageLayout)
+	}
+	if vp.PageMode != "UseNone" {
+		printLine("page mode", vp.PageMode)
+	}
+	var list []string
+	for _, f := range []struct {
+		Name string
+		Set  bool
+	}{
+		{Name: "hide toolbar", Set: vp.HideToolbar},
+		{Name: "hide menubar", Set: vp.HideMenubar},
+		{Name: "hide window UI", Set: vp.HideWindowUI},
+		{Name: "fit window", Set: vp.FitWindow},
+		{Name: "center window", Set: vp.CenterWindow},
+		{Name: "display title", Set: vp.DisplayDocTitle},
+		{Name: "right to left", Set: vp.Direction == "R2L"},
+		{Name: "no print scaling", Set: vp.PrintScaling == "None"},
+	} {
+		if f.Set {
+			list = append(list, f.Name)
+		}
+	}
+	if vp.Duplex != "" {
+		list = append(list, "duplex "+vp.Duplex)
+	}
+	printLine("viewer", strings.Join(list, ", "))
+}
+
+func printLabels(doc *pdf.Document) {
+	var (
+		labels = doc.GetPageLabels()
+		count  = int(doc.GetCount())
+		parts  []string
+	)
+	for i, pl := range labels {
+		last := count
+		if i+1 < len(labels) {
+			last = labels[i+1].Page - 1
+		}
+		if pl.Page > last {
+			continue
+		}
+		str := doc.GetPageLabel(pl.Page)
+		if last > pl.Page {
+			str += " - " + doc.GetPageLabel(last)
+		}
+		parts = append(parts, str)
+	}
+	printLine("labels", strings.Join(parts, ", "))
+}
+
+func printTimeStamp(sig pdf.Signature) {
+	ts := sig.TimeStamp
+	if ts == nil {
+		printLine("timestamp", sig.When.Format(timePattern))
+		return
+	}
+	who := ts.Authority
+	if who == "" && ts.Signer != nil {
+		who = ts.Signer.Subject.String()
+	}
+	printLine("timestamp", fmt.Sprintf("%s (%s)", who, ts.Time.Format(timePattern)))
+}
+
+func printCheck(doc *pdf.Document, sig pdf.Signature, roots *x509.CertPool) {
+	check, err := doc.VerifySignature(sig, roots)
+	switch {
+	case err != nil:
+		printLine("signature", "invalid: "+err.Error())
+	case check.Modified:
+		printLine("signature", "valid, document modified after signing")
+		if cov, err := doc.GetSignatureCoverage(sig); err == nil {
+			for _, off := range cov.Appended {
+				printLine("appended", fmt.Sprintf("revision at offset %d", off))
+			}
+		}
+	default:
+		printLine("signature", "valid")
+	}
+	if check.Signer != nil {
+		printLine("certificate", check.Signer.Subject.String())
+	}
+}
+
+func readRoots(file string) (*x509.CertPool, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(buf) {
+		return nil, fmt.Errorf("%s: no certificates found", file)
+	}
+	return pool, nil
 }
 
 func printValue(key string, value pdf.Value) {
@@ -61,3 +274,10 @@ func printLine(key, value string) {
 	fmt.Printf("%-12s: %s", strings.Title(key), value)
 	fmt.Println()
 }
+
+func openDocument(file string, opts ...pdf.Option) (*pdf.Document, error) {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		return pdf.OpenURL(file, opts...)
+	}
+	return pdf.Open(file, opts...)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/midbel/pdf"
+)
+
+func main() {
+	flag.Parse()
+
+	doc, err := pdf.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	for _, a := range doc.GetJavaScript() {
+		printScript(a)
+	}
+}
+
+func printScript(a pdf.Action) {
+	parts := []string{a.Source, a.Trigger}
+	if a.Name != "" {
+		parts = append(parts, fmt.Sprintf("%q", a.Name))
+	}
+	if a.Page > 0 {
+		parts = append(parts, fmt.Sprintf("page %d", a.Page))
+	}
+	parts = append(parts, fmt.Sprintf("(%s)", strings.Replace(a.Oid, "/", " ", 1)))
+	fmt.Printf("// %s", strings.Join(parts, " "))
+	fmt.Println()
+	fmt.Println(strings.TrimSpace(a.Target))
+	fmt.Println()
+}
@@ -1,220 +1,240 @@
 package main
 
 import (
-	"errors"
+	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
 
 	"github.com/midbel/pdf"
+	"github.com/midbel/pdf/internal/pages"
 )
 
 func main() {
 	var (
-		raw bool
-		rg  Range
+		raw      bool
+		tocsv    bool
+		totsv    bool
+		rg       pages.Range
+		format   string
+		dir      string
+		template = "page-%03d.txt"
 	)
 	flag.BoolVar(&raw, "r", raw, "page source")
 	flag.Var(&rg, "p", "page range")
+	flag.BoolVar(&tocsv, "csv", tocsv, "print tables found in pages as CSV")
+	flag.BoolVar(&totsv, "tsv", totsv, "print tables found in pages as TSV")
+	flag.StringVar(&format, "f", format, "outline format (json, md)")
+	flag.StringVar(&dir, "d", dir, "write each page to its own file in directory")
+	flag.StringVar(&template, "template", template, "file name template of pages written with -d")
 	flag.Parse()
-	doc, err := pdf.Open(flag.Arg(0))
+	doc, err := openDocument(flag.Arg(0))
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 	defer doc.Close()
 
+	if err := rg.Resolve(doc.LabelToPage); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if rg.IsEmpty() && dir != "" {
+		rg.Set(":")
+	}
 	if rg.IsEmpty() {
-		printDocumentOutline(doc)
+		switch format {
+		case "":
+			printDocumentOutline(doc)
+		case "json":
+			err = printOutlineJSON(doc)
+		case "md", "markdown":
+			printOutlineMarkdown(doc)
+		default:
+			err = fmt.Errorf("%s: unsupported outline format", format)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		return
 	}
-	printPages(doc, rg, raw)
+	switch {
+	case tocsv:
+		err = printTables(doc, rg, ',')
+	case totsv:
+		err = printTables(doc, rg, '\t')
+	case dir != "":
+		err = writePages(doc, rg, raw, dir, template)
+	default:
+		err = printPages(doc, rg, raw)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
 
-func printPages(doc *pdf.Document, rg Range, raw bool) {
-	var (
-		page []byte
-		err  error
-	)
+func printPages(doc *pdf.Document, rg pages.Range, raw bool) error {
 	for _, p := range rg.Pages(doc.GetCount()) {
-		if raw {
-			page, err = doc.GetPageCode(p)
-		} else {
-			page, err = doc.GetPage(p)
-		}
+		page, err := readPage(doc, p, raw)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+			return err
 		}
 		os.Stdout.Write(page)
 	}
+	return nil
 }
 
-func printDocumentOutline(doc *pdf.Document) {
-	var print func(pdf.Outline, int)
-	print = func(o pdf.Outline, level int) {
-		fmt.Printf("%s%s", strings.Repeat(" ", level), o.Title)
-		fmt.Println()
-		for _, o := range o.Sub {
-			print(o, level+1)
+func printTables(doc *pdf.Document, rg pages.Range, comma rune) error {
+	ws := csv.NewWriter(os.Stdout)
+	ws.Comma = comma
+	for _, p := range rg.Pages(doc.GetCount()) {
+		tables, err := doc.GetTables(p)
+		if err != nil {
+			return err
+		}
+		for _, t := range tables {
+			if err := ws.WriteAll(t.Rows); err != nil {
+				return err
+			}
+			fmt.Println()
 		}
 	}
-	for _, o := range doc.GetOutlines() {
-		print(o, 0)
-	}
-}
-
-var ErrInvalid = errors.New("invalid page number")
-
-type Ranger interface {
-	Pages(int64) []int
+	return nil
 }
 
-func makeInterval(from, to string) (Ranger, error) {
-	fst, err := strconv.Atoi(from)
-	if err != nil && from != "" {
-		return nil, fmt.Errorf("%s: %w", from, ErrInvalid)
-	}
-	lst, err := strconv.Atoi(to)
-	if err != nil && to != "" {
-		return nil, fmt.Errorf("%s: %w", to, ErrInvalid)
+func writePages(doc *pdf.Document, rg pages.Range, raw bool, dir, template string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
 	}
-	if fst > 0 && lst > 0 && fst >= lst {
-		return nil, fmt.Errorf("invalid interval (%d - %d)", fst, lst)
-	}
-	i := Interval{
-		first: fst,
-		last:  lst,
+	for _, p := range rg.Pages(doc.GetCount()) {
+		page, err := readPage(doc, p, raw)
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(dir, fmt.Sprintf(template, p))
+		if err := os.WriteFile(file, page, 0644); err != nil {
+			return err
+		}
 	}
-	return i, nil
+	return nil
 }
 
-func (i Interval) Pages(n int64) []int {
-	if i.first == 0 {
-		i.first = 1
+func readPage(doc *pdf.Document, page int, raw bool) ([]byte, error) {
+	if raw {
+		return doc.GetPageCode(page)
 	}
-	if i.last == 0 {
-		i.last = int(n)
-	}
-	var ps []int
-	for j := i.first; j <= i.last; j++ {
-		ps = append(ps, j)
-	}
-	return ps
+	return doc.GetPage(page)
 }
 
-// a range is defined with
-// : = all pages
-// x: = from page X to end of document
-// :x = from begin of a document to page X
-// x:y = from page x to page y (offset can be negative)
-// x,y,z = list of page
-// possible to mix range and individual page
-type Range struct {
-	pages []Ranger
-}
-
-func (r *Range) Set(str string) (err error) {
-	if str == "" {
-		return nil
+func printDocumentOutline(doc *pdf.Document) {
+	var print func(pdf.Outline, int)
+	print = func(o pdf.Outline, level int) {
+		fmt.Printf("%s%s", strings.Repeat(" ", level), o.Title)
+		switch {
+		case o.Page > 0:
+			fmt.Printf(" (%d)", o.Page)
+		case o.URI != "":
+			fmt.Printf(" <%s>", o.URI)
+		case o.File != "":
+			fmt.Printf(" [%s]", o.File)
+		}
+		fmt.Println()
+		for _, o := range o.Sub {
+			print(o, level+1)
+		}
 	}
-	if str == ":" {
-		r.pages = append(r.pages, all())
-		return nil
+	for _, o := range doc.GetOutlines() {
+		print(o, 0)
 	}
-	r.pages, err = parseRange(str)
-	return err
 }
 
-func (r *Range) String() string {
-	if len(r.pages) == 0 {
-		return "outline"
+type tocEntry struct {
+	Title  string `json:"title"`
+	Depth  int    `json:"depth"`
+	Page   int    `json:"page,omitempty"`
+	Dest   string `json:"destination,omitempty"`
+	Action string `json:"action,omitempty"`
+	URI    string `json:"uri,omitempty"`
+	File   string `json:"file,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Bold   bool   `json:"bold,omitempty"`
+	Italic bool   `json:"italic,omitempty"`
+	Open   bool   `json:"open,omitempty"`
+}
+
+func flattenOutlines(list []pdf.Outline, depth int) []tocEntry {
+	var es []tocEntry
+	for _, o := range list {
+		e := tocEntry{
+			Title:  o.Title,
+			Depth:  depth,
+			Page:   o.Page,
+			Dest:   o.Dest,
+			Action: o.Action,
+			URI:    o.URI,
+			File:   o.File,
+			Bold:   o.Bold(),
+			Italic: o.Italic(),
+			Open:   o.Open,
+		}
+		if o.Color != [3]float64{} {
+			e.Color = fmt.Sprintf("#%02x%02x%02x", colorByte(o.Color[0]), colorByte(o.Color[1]), colorByte(o.Color[2]))
+		}
+		es = append(es, e)
+		es = append(es, flattenOutlines(o.Sub, depth+1)...)
 	}
-	return "page"
+	return es
 }
 
-func (r *Range) Pages(n int64) []int {
-	var ps []int
-	for _, p := range r.pages {
-		ps = append(ps, p.Pages(n)...)
+func colorByte(f float64) int {
+	switch {
+	case f <= 0:
+		return 0
+	case f >= 1:
+		return 0xff
+	default:
+		return int(f*0xff + 0.5)
 	}
-	return ps
 }
 
-func (r *Range) IsEmpty() bool {
-	return len(r.pages) == 0
+func printOutlineJSON(doc *pdf.Document) error {
+	es := flattenOutlines(doc.GetOutlines(), 0)
+	if es == nil {
+		es = []tocEntry{}
+	}
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+	return e.Encode(es)
 }
 
-const (
-	colon = ':'
-	comma = ','
+var (
+	mdTitle = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`)
+	mdLink  = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
 )
 
-func parseRange(str string) ([]Ranger, error) {
-	var (
-		pages []Ranger
-		i     int
-	)
-	for j := 0; j < len(str); j++ {
-		switch b := str[j]; b {
-		case comma:
-			g, err := makeSingle(str[i:j])
-			if err != nil {
-				return nil, err
-			}
-			pages, i = append(pages, g), j+1
-		case colon:
-			k := j + 1
-			for ; k < len(str); k++ {
-				if str[k] == comma {
-					break
-				}
-				if str[k] == colon {
-					return nil, fmt.Errorf("syntax error: unexpected colon")
-				}
-			}
-			g, err := makeInterval(str[i:j], str[j+1:k])
-			if err != nil {
-				return nil, err
-			}
-			j = k + 1
-			pages, i = append(pages, g), j
-		default:
-		}
-	}
-	if i < len(str) {
-		g, err := makeSingle(str[i:])
-		if err != nil {
-			return nil, err
+func printOutlineMarkdown(doc *pdf.Document) {
+	for _, e := range flattenOutlines(doc.GetOutlines(), 0) {
+		fmt.Printf("%s- ", strings.Repeat("  ", e.Depth))
+		title := mdTitle.Replace(e.Title)
+		if e.Page > 0 {
+			fmt.Printf("[%s](#page=%d)", title, e.Page)
+		} else if e.URI != "" {
+			fmt.Printf("[%s](%s)", title, mdLink.Replace(e.URI))
+		} else {
+			fmt.Print(title)
 		}
-		pages = append(pages, g)
+		fmt.Println()
 	}
-	return pages, nil
-}
-
-type Single struct {
-	page int
 }
 
-func makeSingle(str string) (Ranger, error) {
-	n, err := strconv.Atoi(str)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", str, ErrInvalid)
+func openDocument(file string) (*pdf.Document, error) {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		return pdf.OpenURL(file)
 	}
-	return Single{page: n}, nil
-}
-
-func (s Single) Pages(_ int64) []int {
-	return []int{s.page}
-}
-
-type Interval struct {
-	first int
-	last  int
-}
-
-func all() Ranger {
-	return Interval{}
+	return pdf.Open(file)
 }
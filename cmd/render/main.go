@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/midbel/pdf"
+	"github.com/midbel/pdf/internal/pages"
+)
+
+func main() {
+	var (
+		rg       pages.Range
+		dpi      = 96.0
+		dir      = "."
+		template = "page-%03d.png"
+	)
+	flag.Var(&rg, "p", "page range")
+	flag.Float64Var(&dpi, "r", dpi, "resolution in dots per inch")
+	flag.StringVar(&dir, "d", dir, "output directory")
+	flag.StringVar(&template, "template", template, "file name template of rendered pages")
+	flag.Parse()
+
+	doc, err := openDocument(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	if err := rg.Resolve(doc.LabelToPage); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if rg.IsEmpty() {
+		rg.Set(":")
+	}
+	opts := pdf.RenderOptions{
+		DPI: dpi,
+	}
+	for _, p := range rg.Pages(doc.GetCount()) {
+		file := filepath.Join(dir, fmt.Sprintf(template, p))
+		if err := renderPage(doc, p, file, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "page %d: %s", p, err)
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
+
+func renderPage(doc *pdf.Document, page int, file string, opts pdf.RenderOptions) error {
+	img, err := doc.RenderPage(page, opts)
+	if err != nil {
+		return err
+	}
+	w, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return png.Encode(w, img)
+}
+
+func openDocument(file string) (*pdf.Document, error) {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		return pdf.OpenURL(file)
+	}
+	return pdf.Open(file)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/midbel/pdf"
+)
+
+func main() {
+	var (
+		output = flag.String("o", "", "output file")
+		quiet  = flag.Bool("q", false, "do not print the report")
+	)
+	flag.Parse()
+
+	if err := repair(flag.Arg(0), *output, *quiet); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func repair(file, output string, quiet bool) error {
+	doc, err := openDocument(file, pdf.WithRecovery())
+	if err != nil {
+		return err
+	}
+	defer doc.Close()
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	report, err := doc.Repair(w)
+	if err != nil {
+		return err
+	}
+	if !quiet {
+		printReport(report)
+	}
+	return nil
+}
+
+func printReport(report pdf.RepairReport) {
+	fmt.Fprintf(os.Stderr, "%d objects written", report.Objects)
+	fmt.Fprintln(os.Stderr)
+	for _, str := range report.Fixed {
+		fmt.Fprintf(os.Stderr, "fixed: %s", str)
+		fmt.Fprintln(os.Stderr)
+	}
+	for _, str := range report.Dropped {
+		fmt.Fprintf(os.Stderr, "dropped: %s", str)
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func openDocument(file string, opts ...pdf.Option) (*pdf.Document, error) {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		return pdf.OpenURL(file, opts...)
+	}
+	return pdf.Open(file, opts...)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/midbel/pdf"
+)
+
+func main() {
+	flag.Parse()
+
+	doc, err := pdf.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	st, err := doc.Stats()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%-12s: %d\n", "Size", st.Size)
+	fmt.Printf("%-12s: %d\n", "Revisions", st.Revisions)
+	fmt.Printf("%-12s: %d\n", "Objects", st.Objects)
+	fmt.Printf("%-12s: %d\n", "Pages", st.Pages)
+	fmt.Printf("%-12s: %d\n", "Fonts", st.Fonts)
+	fmt.Printf("%-12s: %d\n", "Images", st.Images)
+	fmt.Println()
+	printUsage("type", st.Types, st.Size)
+	fmt.Println()
+	printUsage("filter", st.Filters, st.Size)
+}
+
+const row = "%-24s | %8s | %12s | %6s"
+
+func printUsage(title string, set map[string]pdf.Usage, total int64) {
+	var keys []string
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := set[keys[i]], set[keys[j]]
+		if a.Bytes == b.Bytes {
+			return keys[i] < keys[j]
+		}
+		return a.Bytes > b.Bytes
+	})
+	fmt.Printf(row, title, "count", "bytes", "%")
+	fmt.Println()
+	for _, k := range keys {
+		u := set[k]
+		if k == "" {
+			k = "-"
+		}
+		var pct float64
+		if total > 0 {
+			pct = float64(u.Bytes) * 100 / float64(total)
+		}
+		fmt.Printf(row, k, fmt.Sprint(u.Count), fmt.Sprint(u.Bytes), fmt.Sprintf("%.1f", pct))
+		fmt.Println()
+	}
+}
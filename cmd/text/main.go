@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/midbel/pdf"
+	"github.com/midbel/pdf/internal/pages"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+func main() {
+	var (
+		rg     pages.Range
+		layout bool
+		raw    bool
+		tagged bool
+		html   bool
+		json   bool
+		enc    = "utf-8"
+	)
+	flag.Var(&rg, "p", "page range")
+	flag.BoolVar(&layout, "layout", layout, "preserve the physical layout of the text")
+	flag.BoolVar(&raw, "raw", raw, "keep text in content stream order")
+	flag.BoolVar(&tagged, "tagged", tagged, "follow the structure tree of tagged documents")
+	flag.BoolVar(&html, "html", html, "write pages as HTML")
+	flag.BoolVar(&json, "json", json, "write text runs of pages as JSON lines")
+	flag.StringVar(&enc, "enc", enc, "output encoding")
+	flag.Parse()
+
+	w, err := getWriter(os.Stdout, enc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	doc, err := openDocument(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+	defer w.Close()
+
+	if err := rg.Resolve(doc.LabelToPage); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if rg.IsEmpty() {
+		rg.Set(":")
+	}
+	if html || json {
+		format := pdf.FormatHTML
+		if json {
+			format = pdf.FormatJSON
+		}
+		err := doc.ExportText(w, format, rg.Pages(doc.GetCount())...)
+		if err != nil {
+			w.Close()
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	mode := pdf.TextReading
+	switch {
+	case layout:
+		mode = pdf.TextLayout
+	case raw:
+		mode = pdf.TextRaw
+	case tagged:
+		mode = pdf.TextStructure
+	}
+	for _, p := range rg.Pages(doc.GetCount()) {
+		text, err := doc.GetTextWith(p, mode)
+		if err != nil {
+			w.Close()
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		io.WriteString(w, text)
+		io.WriteString(w, "\f")
+	}
+}
+
+func getWriter(w io.Writer, name string) (io.WriteCloser, error) {
+	e, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unsupported encoding", name)
+	}
+	return transform.NewWriter(w, encoding.ReplaceUnsupported(e.NewEncoder())), nil
+}
+
+func openDocument(file string) (*pdf.Document, error) {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		return pdf.OpenURL(file)
+	}
+	return pdf.Open(file)
+}
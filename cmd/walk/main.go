@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -11,10 +12,29 @@ import (
 
 func main() {
 	var (
-		all = flag.Bool("a", false, "all")
-		raw = flag.Bool("r", false, "raw")
+		all   = flag.Bool("a", false, "all")
+		raw   = flag.Bool("r", false, "raw")
+		asjs  = flag.Bool("json", false, "write objects as JSON lines")
+		xref  = flag.Bool("xref", false, "print the cross reference table")
+		graph = flag.Bool("graph", false, "write the graph of references in the dot language")
+		order = flag.String("o", "xref", "walk order (xref, number, offset, reachable)")
 	)
 	flag.Parse()
+
+	var wo pdf.WalkOrder
+	switch *order {
+	case "xref", "":
+		wo = pdf.WalkXRef
+	case "number":
+		wo = pdf.WalkNumber
+	case "offset":
+		wo = pdf.WalkOffset
+	case "reachable":
+		wo = pdf.WalkReachable
+	default:
+		fmt.Fprintf(os.Stderr, "%s: unknown walk order\n", *order)
+		os.Exit(2)
+	}
 	doc, err := pdf.Open(flag.Arg(0))
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -22,11 +42,31 @@ func main() {
 	}
 	defer doc.Close()
 
-	doc.Walk(func(o pdf.Object) bool {
+	if *xref {
+		printXRef(doc.XRef())
+		return
+	}
+	if *graph {
+		doc.Graph().WriteDOT(os.Stdout)
+		return
+	}
+	print := func(o pdf.Object) {
 		printObject(o, *raw)
+	}
+	if *asjs {
+		e := json.NewEncoder(os.Stdout)
+		print = func(o pdf.Object) {
+			if o.Dict.IsEmpty() && o.Data == nil {
+				return
+			}
+			e.Encode(o)
+		}
+	}
+	doc.WalkWith(wo, func(o pdf.Object) bool {
+		print(o)
 		if *all {
 			for _, o := range o.GetEmbeddedObjects() {
-				printObject(o, *raw)
+				print(o)
 			}
 		}
 		return true
@@ -42,7 +82,7 @@ func printObject(o pdf.Object, raw bool) {
 		return
 	}
 	fmt.Println()
-	if raw && len(o.Content) > 0 {
+	if raw && o.HasStream() {
 		body, err := o.Body()
 		if err != nil {
 			return
@@ -50,3 +90,18 @@ func printObject(o pdf.Object, raw bool) {
 		fmt.Println(hexdump.Dump(body))
 	}
 }
+
+func printXRef(list []pdf.XRefEntry) {
+	for _, e := range list {
+		var where string
+		switch {
+		case e.Free:
+			where = "free"
+		case e.Compressed():
+			where = fmt.Sprintf("%s[%d]", e.Owner, e.Offset)
+		default:
+			where = fmt.Sprintf("%d", e.Offset)
+		}
+		fmt.Printf("%d/%d %s rev:%d\n", e.Number, e.Generation, where, e.Revision)
+	}
+}
@@ -0,0 +1,191 @@
+package pdf
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+type Comment struct {
+	Annotation
+	Quote   string
+	Note    string
+	Replies []Comment
+}
+
+func (d *Document) GetComments(page int) ([]Comment, error) {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return nil, fmt.Errorf("page %d not found in document", page)
+	}
+	var (
+		annots   = d.getPageAnnots(obj)
+		comments = make(map[string]*Comment)
+		parents  = make(map[string]string)
+		roots    []string
+	)
+	for _, annot := range annots {
+		if !isMarkupAnnot(annot.Subtype()) {
+			continue
+		}
+		c := Comment{
+			Annotation: d.getAnnotation(annot, page),
+			Note:       d.getPopupNote(annot),
+		}
+		if isTextMarkup(c.Subtype) {
+			c.Quote = d.getQuotedText(annot, page)
+		}
+		comments[annot.Oid] = &c
+		if irt := annot.GetString("irt"); isOid(irt) {
+			parents[annot.Oid] = irt
+		}
+	}
+	children := make(map[string][]string)
+	for _, annot := range annots {
+		if _, ok := comments[annot.Oid]; !ok {
+			continue
+		}
+		irt, ok := parents[annot.Oid]
+		if _, found := comments[irt]; ok && found && irt != annot.Oid {
+			children[irt] = append(children[irt], annot.Oid)
+			continue
+		}
+		roots = append(roots, annot.Oid)
+	}
+	var thread func(oid string, seen map[string]bool) Comment
+	thread = func(oid string, seen map[string]bool) Comment {
+		seen[oid] = true
+		c := *comments[oid]
+		for _, kid := range children[oid] {
+			if seen[kid] {
+				continue
+			}
+			c.Replies = append(c.Replies, thread(kid, seen))
+		}
+		return c
+	}
+	var (
+		list []Comment
+		seen = make(map[string]bool)
+	)
+	for _, oid := range roots {
+		list = append(list, thread(oid, seen))
+	}
+	return list, nil
+}
+
+func (d *Document) ExportComments(w io.Writer, pages ...int) error {
+	type comment struct {
+		Oid      string     `json:"oid"`
+		Page     int        `json:"page"`
+		Subtype  string     `json:"subtype"`
+		Box      [4]float64 `json:"bbox"`
+		Author   string     `json:"author,omitempty"`
+		Subject  string     `json:"subject,omitempty"`
+		Contents string     `json:"contents,omitempty"`
+		Quote    string     `json:"quote,omitempty"`
+		Note     string     `json:"note,omitempty"`
+		Modified *time.Time `json:"modified,omitempty"`
+		Replies  []comment  `json:"replies,omitempty"`
+	}
+	var convert func(c Comment) comment
+	convert = func(c Comment) comment {
+		x := comment{
+			Oid:      c.Oid,
+			Page:     c.Page,
+			Subtype:  c.Subtype,
+			Box:      c.Rect,
+			Author:   c.Author,
+			Subject:  c.Subject,
+			Contents: c.Contents,
+			Quote:    c.Quote,
+			Note:     c.Note,
+		}
+		if !c.Modified.IsZero() {
+			x.Modified = &c.Modified
+		}
+		for _, r := range c.Replies {
+			x.Replies = append(x.Replies, convert(r))
+		}
+		return x
+	}
+	if len(pages) == 0 {
+		for i := 1; i <= int(d.GetCount()); i++ {
+			pages = append(pages, i)
+		}
+	}
+	var (
+		ws = bufio.NewWriter(w)
+		e  = json.NewEncoder(ws)
+	)
+	for _, p := range pages {
+		list, err := d.GetComments(p)
+		if err != nil {
+			return err
+		}
+		for _, c := range list {
+			if err := e.Encode(convert(c)); err != nil {
+				return err
+			}
+		}
+	}
+	return ws.Flush()
+}
+
+func (d *Document) getPopupNote(annot Object) string {
+	popup := d.getObjectWithOid(annot.GetString("popup"), false)
+	if !popup.isZero() {
+		if str := d.resolveString(popup.getValue("contents")); str != "" {
+			return str
+		}
+	}
+	return d.resolveString(annot.getValue("contents"))
+}
+
+func (d *Document) getQuotedText(annot Object, page int) string {
+	quads, _ := d.resolve(annot.getValue("quadpoints")).([]interface{})
+	var parts []string
+	for i := 0; i+8 <= len(quads); i += 8 {
+		rect := Rect{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+		for j := i; j < i+8; j += 2 {
+			var (
+				x = getNumber(d.resolve(quads[j]))
+				y = getNumber(d.resolve(quads[j+1]))
+			)
+			rect[0], rect[2] = math.Min(rect[0], x), math.Max(rect[2], x)
+			rect[1], rect[3] = math.Min(rect[1], y), math.Max(rect[3], y)
+		}
+		str, err := d.GetTextInRect(page, rect)
+		if err != nil {
+			break
+		}
+		if str = strings.Join(strings.Fields(str), " "); str != "" {
+			parts = append(parts, str)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func isTextMarkup(subtype string) bool {
+	switch subtype {
+	case "Highlight", "Underline", "StrikeOut", "Squiggly":
+		return true
+	default:
+		return false
+	}
+}
+
+func isMarkupAnnot(subtype string) bool {
+	switch subtype {
+	case "Text", "FreeText", "Line", "Square", "Circle", "Polygon", "PolyLine",
+		"Highlight", "Underline", "Squiggly", "StrikeOut", "Caret", "Stamp",
+		"Ink", "FileAttachment", "Sound", "Redact":
+		return true
+	default:
+		return false
+	}
+}
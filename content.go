@@ -0,0 +1,170 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Operator struct {
+	Name   string
+	Args   []Token
+	Inline *InlineImage
+}
+
+type Handler func(w io.Writer, op Operator) error
+
+type ContentParser struct {
+	handlers map[string]Handler
+	ctx      context.Context
+
+	state GraphicsState
+	stack []GraphicsState
+	text  *textState
+
+	line    string
+	written int
+}
+
+func NewContentParser() *ContentParser {
+	return &ContentParser{
+		handlers: make(map[string]Handler),
+		state:    defaultGraphicsState(),
+	}
+}
+
+func (p *ContentParser) Register(op string, fn Handler) {
+	p.handlers[op] = fn
+}
+
+// ParseContext is like Parse but stops as soon as ctx is done.
+func (p *ContentParser) ParseContext(ctx context.Context, body []byte, w io.Writer) error {
+	p.ctx = ctx
+	defer func() {
+		p.ctx = nil
+	}()
+	return p.Parse(body, w)
+}
+
+func (p *ContentParser) Parse(body []byte, w io.Writer) error {
+	var (
+		r     = NewReader(body)
+		ws    = writeCounter{inner: w, count: &p.written}
+		stack []Token
+	)
+	for r.Len() > 0 {
+		if p.ctx != nil {
+			if err := p.ctx.Err(); err != nil {
+				return err
+			}
+		}
+		tok := readToken(r)
+		if tok.Type == EOF {
+			break
+		}
+		if tok.Type == Ident && tok.Literal == "BI" {
+			img, err := readInlineImage(r)
+			if err != nil {
+				return err
+			}
+			if err := p.apply(ws, Operator{Name: tok.Literal, Inline: &img}); err != nil {
+				return err
+			}
+			stack = stack[:0]
+			continue
+		}
+		_, custom := p.handlers[tok.Literal]
+		if !tok.IsOperator() && !(custom && tok.Type == Ident) {
+			stack = append(stack, tok)
+			continue
+		}
+		op := Operator{
+			Name: tok.Literal,
+			Args: stack,
+		}
+		p.update(op)
+		if err := p.apply(ws, op); err != nil {
+			return err
+		}
+		stack = stack[:0]
+	}
+	return nil
+}
+
+func (p *ContentParser) apply(w io.Writer, op Operator) error {
+	if fn, ok := p.handlers[op.Name]; ok {
+		return fn(w, op)
+	}
+	if p.text != nil {
+		p.text.apply(w, op)
+		return nil
+	}
+	switch op.Name {
+	case "Tj", "TJ":
+		var str []string
+		for i := range op.Args {
+			if op.Args[i].Type == String {
+				str = append(str, op.Args[i].Literal)
+			}
+		}
+		s := strings.Join(str, "")
+		if p.line == "" && p.written > 0 {
+			io.WriteString(w, "\n")
+		}
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			io.WriteString(w, s)
+		}
+	case "Tm", "Td", "TD":
+		if len(op.Args) == 0 {
+			break
+		}
+		offset := op.Args[len(op.Args)-1].Literal
+		if p.line != offset && offset != "0" {
+			io.WriteString(w, "\n")
+		}
+		p.line = offset
+	}
+	return nil
+}
+
+func (d *Document) GetPageWith(n int, p *ContentParser) ([]byte, error) {
+	body, err := d.GetPageCode(n)
+	if err != nil {
+		return nil, err
+	}
+	var w bytes.Buffer
+	err = p.Parse(body, &w)
+	return w.Bytes(), err
+}
+
+func (d *Document) GetXObjectCode(page int, name string) ([]byte, error) {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return nil, fmt.Errorf("page %d not found in document", page)
+	}
+	var (
+		res     = d.getPageResources(obj)
+		xobj, _ = d.resolve(res.getValue("xobject")).(Dict)
+	)
+	obj = d.getObjectWithOid(xobj.GetString(name), true)
+	if obj.isZero() {
+		return nil, fmt.Errorf("xobject %s %w", name, ErrMissing)
+	}
+	if obj.Subtype() != "Form" {
+		return nil, fmt.Errorf("xobject %s: not a form", name)
+	}
+	return obj.Body()
+}
+
+type writeCounter struct {
+	inner io.Writer
+	count *int
+}
+
+func (w writeCounter) Write(b []byte) (int, error) {
+	n, err := w.inner.Write(b)
+	*w.count += n
+	return n, err
+}
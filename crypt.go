@@ -0,0 +1,643 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+const identityFilter = "Identity"
+
+var salt = []byte("sAlT")
+
+var (
+	ErrPasswordRequired = errors.New("password required")
+	ErrInvalidPassword  = errors.New("invalid password")
+)
+
+type Password int
+
+const (
+	PasswordNone Password = iota
+	PasswordUser
+	PasswordOwner
+)
+
+func (p Password) String() string {
+	switch p {
+	case PasswordUser:
+		return "user"
+	case PasswordOwner:
+		return "owner"
+	default:
+		return "none"
+	}
+}
+
+type EncryptionInfo struct {
+	Encrypted    bool
+	Unlocked     bool
+	Password     Password
+	Filter       string
+	Algorithm    string
+	Version      int
+	Revision     int
+	Length       int
+	StreamFilter string
+	StringFilter string
+	FileFilter   string
+	EmbeddedOnly bool
+}
+
+type Permissions struct {
+	CanPrint                   bool
+	CanModify                  bool
+	CanCopy                    bool
+	CanAnnotate                bool
+	CanFillForms               bool
+	CanExtractForAccessibility bool
+	CanAssemble                bool
+	HighResPrint               bool
+
+	Flags int32
+}
+
+func (p Permissions) List() []string {
+	var list []string
+	add := func(ok bool, str string) {
+		if ok {
+			list = append(list, str)
+		}
+	}
+	add(p.CanPrint, "print")
+	add(p.HighResPrint, "high resolution print")
+	add(p.CanModify, "modify")
+	add(p.CanCopy, "copy")
+	add(p.CanAnnotate, "annotate")
+	add(p.CanFillForms, "fill forms")
+	add(p.CanExtractForAccessibility, "accessibility")
+	add(p.CanAssemble, "assemble")
+	return list
+}
+
+type cryptKey struct {
+	key []byte
+	aes bool
+}
+
+type cryptKeys struct {
+	str *cryptKey
+	stm *cryptKey
+}
+
+func (k cryptKeys) derive(oid, rev int) cryptKeys {
+	return cryptKeys{
+		str: getEncryptionKey(k.str, oid, rev),
+		stm: getEncryptionKey(k.stm, oid, rev),
+	}
+}
+
+type cryptFilter struct {
+	Method string
+	Length int64
+}
+
+type standardHandler struct {
+	Revision int64
+	Length   int64
+	Owner    []byte
+	User     []byte
+	OwnerKey []byte
+	UserKey  []byte
+	Perm     uint32
+	Metadata bool
+	ID       []byte
+}
+
+func (d *Document) IsEncrypted() bool {
+	return d.encrypt != ""
+}
+
+func (d *Document) EncryptionInfo() EncryptionInfo {
+	var info EncryptionInfo
+	if d.encrypt == "" {
+		return info
+	}
+	obj := d.getObjectWithOid(d.encrypt, false)
+	info.Encrypted = true
+	info.Filter = obj.GetString("filter")
+	info.Version = int(obj.GetInt("v"))
+	info.Revision = int(obj.GetInt("r"))
+	info.Length = int(obj.GetInt("length"))
+	switch {
+	case info.Revision >= 5 || info.Version >= 5:
+		info.Length = 256
+	case info.Length == 0 && info.Revision >= 4:
+		info.Length = 128
+	case info.Length == 0:
+		info.Length = 40
+	}
+	info.Unlocked = len(d.decrypt) > 0
+	info.Password = d.access
+	info.Algorithm = d.getAlgorithm(info.Version)
+	info.StreamFilter = d.stmf
+	info.StringFilter = d.strf
+	info.FileFilter = d.eff
+	info.EmbeddedOnly = d.embeddedOnly()
+	return info
+}
+
+func (d *Document) Permissions() Permissions {
+	if d.encrypt == "" {
+		return Permissions{
+			CanPrint:                   true,
+			CanModify:                  true,
+			CanCopy:                    true,
+			CanAnnotate:                true,
+			CanFillForms:               true,
+			CanExtractForAccessibility: true,
+			CanAssemble:                true,
+			HighResPrint:               true,
+			Flags:                      -1,
+		}
+	}
+	var (
+		obj  = d.getObjectWithOid(d.encrypt, false)
+		bits = uint32(obj.GetInt("p"))
+		has  = func(n uint) bool { return bits&(1<<(n-1)) != 0 }
+		perm = Permissions{
+			CanPrint:    has(3),
+			CanModify:   has(4),
+			CanCopy:     has(5),
+			CanAnnotate: has(6),
+			Flags:       int32(bits),
+		}
+	)
+	if obj.GetInt("r") <= 2 {
+		perm.CanFillForms = perm.CanAnnotate
+		perm.CanExtractForAccessibility = perm.CanCopy
+		perm.CanAssemble = perm.CanModify
+		perm.HighResPrint = perm.CanPrint
+		return perm
+	}
+	perm.CanFillForms = has(9)
+	perm.CanExtractForAccessibility = has(10)
+	perm.CanAssemble = has(11)
+	perm.HighResPrint = perm.CanPrint && has(12)
+	return perm
+}
+
+func (d *Document) getAlgorithm(version int) string {
+	switch version {
+	case 1, 2, 3:
+		return "RC4"
+	case 4:
+		cf, ok := d.filters[strings.ToLower(d.stmf)]
+		if !ok {
+			cf = d.filters[strings.ToLower(d.eff)]
+		}
+		switch cf.Method {
+		case "AESV2":
+			return "AES-128"
+		case "AESV3":
+			return "AES-256"
+		case "V2":
+			return "RC4"
+		default:
+			return identityFilter
+		}
+	case 5:
+		return "AES-256"
+	default:
+		return ""
+	}
+}
+
+func (d *Document) embeddedOnly() bool {
+	return d.encrypt != "" && d.stmf == identityFilter && d.strf == identityFilter && d.eff != identityFilter
+}
+
+func (d *Document) setupFilters(obj Object) {
+	if obj.GetInt("v") < 4 {
+		return
+	}
+	d.filters = make(map[string]cryptFilter)
+	for name, v := range obj.GetDict("cf") {
+		cf, ok := v.(Dict)
+		if !ok {
+			continue
+		}
+		d.filters[name] = cryptFilter{
+			Method: cf.GetString("cfm"),
+			Length: cf.GetInt("length"),
+		}
+	}
+	d.stmf = identityFilter
+	if str := obj.GetString("stmf"); str != "" {
+		d.stmf = str
+	}
+	d.strf = identityFilter
+	if str := obj.GetString("strf"); str != "" {
+		d.strf = str
+	}
+	d.eff = d.stmf
+	if str := obj.GetString("eff"); str != "" {
+		d.eff = str
+	}
+}
+
+func (d *Document) filterKey(name string, obj Object) (*cryptKey, error) {
+	if name == identityFilter {
+		return nil, nil
+	}
+	if len(d.decrypt) == 0 {
+		return nil, ErrPasswordRequired
+	}
+	cf, ok := d.filters[strings.ToLower(name)]
+	if !ok && name != "" {
+		return nil, fmt.Errorf("crypt filter %s %w", name, ErrMissing)
+	}
+	switch cf.Method {
+	case "", "V2", "AESV2", "AESV3":
+	case "None":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("crypt filter %s: %w", cf.Method, ErrUnsupported)
+	}
+	oid, rev := obj.ObjectId()
+	return getEncryptionKey(d.fileKey(name), oid, rev), nil
+}
+
+func (d *Document) fileKey(filter string) *cryptKey {
+	if len(d.decrypt) == 0 || filter == identityFilter {
+		return nil
+	}
+	cf := d.filters[strings.ToLower(filter)]
+	if cf.Method == "None" {
+		return nil
+	}
+	return &cryptKey{
+		key: d.decrypt,
+		aes: cf.Method == "AESV2" || cf.Method == "AESV3",
+	}
+}
+
+func (d *Document) streamKey(obj Object, key *cryptKey) *cryptKey {
+	if key == nil {
+		return nil
+	}
+	if obj.IsMeta() && !d.metadata {
+		return nil
+	}
+	filters := obj.GetStringArray("filter")
+	if len(filters) == 0 {
+		filters = append(filters, obj.GetString("filter"))
+	}
+	for _, f := range filters {
+		if f != "Crypt" {
+			continue
+		}
+		name := d.getStreamFilterName(obj)
+		if _, ok := d.filters[strings.ToLower(name)]; !ok && name != identityFilter {
+			break
+		}
+		oid, rev := obj.ObjectId()
+		return getEncryptionKey(d.fileKey(name), oid, rev)
+	}
+	return key
+}
+
+func decryptAES(key, buf []byte) []byte {
+	if len(buf) < aes.BlockSize || len(buf)%aes.BlockSize != 0 {
+		return nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil
+	}
+	var (
+		iv  = buf[:aes.BlockSize]
+		out = make([]byte, len(buf)-aes.BlockSize)
+	)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, buf[aes.BlockSize:])
+	return unpadAES(out)
+}
+
+func unpadAES(out []byte) []byte {
+	if n := len(out); n > 0 {
+		if pad := int(out[n-1]); pad > 0 && pad <= aes.BlockSize && pad <= n {
+			out = out[:n-pad]
+		}
+	}
+	return out
+}
+
+const aesChunk = 256 * aes.BlockSize
+
+// aesReader holds back the last block, it has the padding to remove.
+type aesReader struct {
+	inner io.Reader
+	mode  cipher.BlockMode
+	in    []byte
+	out   []byte
+	tail  []byte
+	ready []byte
+	err   error
+}
+
+func newAESReader(key []byte, r io.Reader) io.Reader {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return errReader{err: fmt.Errorf("aes: %s", err)}
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(r, iv); err != nil {
+		return errReader{err: fmt.Errorf("aes: fail to read iv: %s", err)}
+	}
+	return &aesReader{
+		inner: r,
+		mode:  cipher.NewCBCDecrypter(block, iv),
+		in:    make([]byte, aesChunk),
+		out:   make([]byte, aesChunk+aes.BlockSize),
+	}
+}
+
+func (a *aesReader) Read(b []byte) (int, error) {
+	for len(a.ready) == 0 {
+		if a.err != nil {
+			return 0, a.err
+		}
+		a.fill()
+	}
+	n := copy(b, a.ready)
+	a.ready = a.ready[n:]
+	return n, nil
+}
+
+func (a *aesReader) fill() {
+	n, err := io.ReadFull(a.inner, a.in)
+	if n%aes.BlockSize != 0 {
+		a.err = fmt.Errorf("aes: stream length is not a multiple of the block size")
+		return
+	}
+	held := copy(a.out, a.tail)
+	a.mode.CryptBlocks(a.out[held:held+n], a.in[:n])
+	all := a.out[:held+n]
+	switch err {
+	case nil:
+		a.ready = all[:len(all)-aes.BlockSize]
+		a.tail = all[len(all)-aes.BlockSize:]
+	case io.EOF, io.ErrUnexpectedEOF:
+		a.ready = unpadAES(all)
+		a.tail = nil
+		a.err = io.EOF
+	default:
+		a.err = err
+	}
+}
+
+func (d *Document) setupKey() error {
+	if d.encrypt == "" {
+		return nil
+	}
+	obj := d.getObjectWithOid(d.encrypt, false)
+	switch filter := obj.GetString("filter"); filter {
+	case "Standard", "":
+	case pubsecFilter:
+		d.setupFilters(obj)
+		return d.setupPubSec(obj)
+	default:
+		return fmt.Errorf("security handler %s: %w", filter, ErrUnsupported)
+	}
+	var (
+		sh = standardHandler{
+			Revision: obj.GetInt("r"),
+			Length:   obj.GetInt("length"),
+			Owner:    obj.GetBytes("o"),
+			User:     obj.GetBytes("u"),
+			OwnerKey: obj.GetBytes("oe"),
+			UserKey:  obj.GetBytes("ue"),
+			Perm:     uint32(obj.GetInt("p")),
+			Metadata: true,
+		}
+	)
+	if obj.Has("encryptmetadata") {
+		sh.Metadata = obj.GetBool("encryptmetadata")
+	}
+	switch {
+	case sh.Revision == 2:
+		sh.Length = 40
+	case sh.Revision >= 5:
+		sh.Length = 256
+	case sh.Length == 0 && sh.Revision >= 4:
+		sh.Length = 128
+	case sh.Length == 0:
+		sh.Length = 40
+	}
+	if len(d.fileid) > 0 {
+		sh.ID = []byte(d.fileid[0])
+	}
+	d.metadata = sh.Metadata
+	d.setupFilters(obj)
+	if key, access, ok := sh.authenticate(d.password); ok {
+		d.decrypt = key
+		d.access = access
+		return nil
+	}
+	if d.embeddedOnly() && len(d.password) == 0 {
+		return nil
+	}
+	if len(d.password) == 0 {
+		return ErrPasswordRequired
+	}
+	return ErrInvalidPassword
+}
+
+func (sh standardHandler) authenticate(password []byte) ([]byte, Password, bool) {
+	if sh.Revision >= 5 {
+		return sh.authenticateV5(password)
+	}
+	if key, ok := sh.authenticateUser(password); ok {
+		return key, PasswordUser, ok
+	}
+	key, ok := sh.authenticateUser(sh.userFromOwner(password))
+	return key, PasswordOwner, ok
+}
+
+func (sh standardHandler) authenticateUser(password []byte) ([]byte, bool) {
+	var (
+		key  = sh.computeKey(password)
+		user []byte
+	)
+	if sh.Revision <= 2 {
+		user = make([]byte, len(padding))
+		c, _ := rc4.NewCipher(key)
+		c.XORKeyStream(user, padding)
+		return key, bytes.Equal(sh.User, user)
+	}
+	sum := md5.New()
+	sum.Write(padding)
+	sum.Write(sh.ID)
+	user = sum.Sum(nil)
+
+	tmp := make([]byte, len(key))
+	for i := 0; i < 20; i++ {
+		for j := range tmp {
+			tmp[j] = key[j] ^ byte(i)
+		}
+		c, _ := rc4.NewCipher(tmp)
+		c.XORKeyStream(user, user)
+	}
+	return key, bytes.HasPrefix(sh.User, user)
+}
+
+func (sh standardHandler) computeKey(password []byte) []byte {
+	var (
+		size = sh.Length / 8
+		sum  = md5.New()
+	)
+	sum.Write(padPassword(password))
+	sum.Write(sh.Owner)
+	sum.Write([]byte{byte(sh.Perm), byte(sh.Perm >> 8), byte(sh.Perm >> 16), byte(sh.Perm >> 24)})
+	sum.Write(sh.ID)
+	if sh.Revision >= 4 && !sh.Metadata {
+		sum.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+	key := sum.Sum(nil)
+	if sh.Revision >= 3 {
+		for i := 0; i < 50; i++ {
+			sum.Reset()
+			sum.Write(key[:size])
+			key = sum.Sum(key[:0])
+		}
+	}
+	return key[:size]
+}
+
+func (sh standardHandler) userFromOwner(password []byte) []byte {
+	var (
+		sum  = md5.Sum(padPassword(password))
+		size = sh.Length / 8
+	)
+	if sh.Revision >= 3 {
+		for i := 0; i < 50; i++ {
+			sum = md5.Sum(sum[:])
+		}
+	}
+	key := sum[:size]
+
+	user := make([]byte, len(sh.Owner))
+	copy(user, sh.Owner)
+	if sh.Revision <= 2 {
+		c, _ := rc4.NewCipher(key)
+		c.XORKeyStream(user, user)
+		return user
+	}
+	tmp := make([]byte, len(key))
+	for i := 19; i >= 0; i-- {
+		for j := range tmp {
+			tmp[j] = key[j] ^ byte(i)
+		}
+		c, _ := rc4.NewCipher(tmp)
+		c.XORKeyStream(user, user)
+	}
+	return user
+}
+
+func (sh standardHandler) authenticateV5(password []byte) ([]byte, Password, bool) {
+	if len(sh.User) < 48 || len(sh.Owner) < 48 {
+		return nil, PasswordNone, false
+	}
+	if len(password) > 127 {
+		password = password[:127]
+	}
+	var (
+		user  = sh.User[:48]
+		owner = sh.Owner[:48]
+	)
+	if bytes.Equal(sh.hashV5(password, owner[32:40], user), owner[:32]) {
+		key, ok := unwrapKey(sh.hashV5(password, owner[40:48], user), sh.OwnerKey)
+		return key, PasswordOwner, ok
+	}
+	if bytes.Equal(sh.hashV5(password, user[32:40], nil), user[:32]) {
+		key, ok := unwrapKey(sh.hashV5(password, user[40:48], nil), sh.UserKey)
+		return key, PasswordUser, ok
+	}
+	return nil, PasswordNone, false
+}
+
+func (sh standardHandler) hashV5(password, salt, user []byte) []byte {
+	sum := sha256.New()
+	sum.Write(password)
+	sum.Write(salt)
+	sum.Write(user)
+	key := sum.Sum(nil)
+	if sh.Revision == 5 {
+		return key
+	}
+	var last byte
+	for i := 0; i < 64 || int(last) > i-32; i++ {
+		var buf []byte
+		for j := 0; j < 64; j++ {
+			buf = append(buf, password...)
+			buf = append(buf, key...)
+			buf = append(buf, user...)
+		}
+		block, _ := aes.NewCipher(key[:16])
+		cipher.NewCBCEncrypter(block, key[16:32]).CryptBlocks(buf, buf)
+
+		var (
+			mod int
+			h   hash.Hash
+		)
+		for _, b := range buf[:16] {
+			mod += int(b)
+		}
+		switch mod % 3 {
+		case 0:
+			h = sha256.New()
+		case 1:
+			h = sha512.New384()
+		default:
+			h = sha512.New()
+		}
+		h.Write(buf)
+		key = h.Sum(nil)
+		last = buf[len(buf)-1]
+	}
+	return key[:32]
+}
+
+func unwrapKey(key, wrapped []byte) ([]byte, bool) {
+	if len(wrapped) != 32 {
+		return nil, false
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, false
+	}
+	var (
+		iv  = make([]byte, aes.BlockSize)
+		out = make([]byte, len(wrapped))
+	)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, wrapped)
+	return out, true
+}
+
+func padPassword(password []byte) []byte {
+	buf := make([]byte, 0, len(padding))
+	if len(password) > len(padding) {
+		password = password[:len(padding)]
+	}
+	buf = append(buf, password...)
+	return append(buf, padding[:len(padding)-len(password)]...)
+}
@@ -4,17 +4,58 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 type Value interface{}
 
+// NameValue is a name object, without its leading slash.
+type NameValue string
+
+// Reference is an indirect reference formatted as "number/generation".
+type Reference string
+
+// StringValue is a string as found in the file once decrypted.
+type StringValue []byte
+
+func (s StringValue) String() string {
+	return convertString(string(s))
+}
+
+// toString gives the value of names, references and strings as a Go string.
+func toString(v Value) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case NameValue:
+		return string(v), true
+	case Reference:
+		return string(v), true
+	case StringValue:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// toOid returns the object id of v when it is an indirect reference.
+func toOid(v Value) (string, bool) {
+	switch v := v.(type) {
+	case Reference:
+		return string(v), true
+	case string:
+		return v, isOid(v)
+	default:
+		return "", false
+	}
+}
+
 type Dict map[string]Value
 
 func (d Dict) Linearized() bool {
-	v := d.GetString("linearized")
-	return v != ""
+	return d.Has("linearized")
 }
 
 func (d Dict) Type() string {
@@ -69,20 +110,59 @@ func (d Dict) GetBool(key string) bool {
 }
 
 func (d Dict) GetBytes(key string) []byte {
+	if s, ok := d.getValue(key).(StringValue); ok {
+		return []byte(s)
+	}
 	v := d.GetString(key)
 	return []byte(v)
 }
 
 func (d Dict) GetString(key string) string {
-	v, _ := d.getValue(key).(string)
+	v, _ := toString(d.getValue(key))
 	return v
 }
 
+func (d Dict) GetName(key string) string {
+	v, _ := d.getValue(key).(NameValue)
+	return string(v)
+}
+
 func (d Dict) GetInt(key string) int64 {
 	i, _ := d.getValue(key).(int64)
 	return i
 }
 
+func (d Dict) GetFloat(key string) float64 {
+	return getNumber(d.getValue(key))
+}
+
+func (d Dict) GetFloatArray(key string) []float64 {
+	var list []float64
+	for _, v := range d.GetArray(key) {
+		switch v.(type) {
+		case int64, float64:
+			list = append(list, getNumber(v))
+		}
+	}
+	return list
+}
+
+// GetRect returns the rectangle of key with its lower left corner first.
+func (d Dict) GetRect(key string) Rect {
+	return Rect(getRect(d.getValue(key)))
+}
+
+// GetMatrix returns the identity matrix when key is missing or invalid.
+func (d Dict) GetMatrix(key string) Matrix {
+	list := d.GetFloatArray(key)
+	if len(list) != len(identityMatrix) {
+		return Matrix(identityMatrix)
+	}
+	var m Matrix
+	copy(m[:], list)
+	return m
+}
+
 func (d Dict) GetUint(key string) uint64 {
 	i := d.GetInt(key)
 	return uint64(i)
@@ -115,7 +195,7 @@ func (d Dict) GetStringArray(key string) []string {
 		str []string
 	)
 	for _, v := range arr {
-		s, ok := v.(string)
+		s, ok := toString(v)
 		if ok {
 			str = append(str, s)
 		}
@@ -123,11 +203,34 @@ func (d Dict) GetStringArray(key string) []string {
 	return str
 }
 
+// getRawStrings is like GetStringArray but keeps the bytes as found in the file.
+func (d Dict) getRawStrings(key string) []string {
+	var str []string
+	for _, v := range d.GetArray(key) {
+		if s, ok := v.(StringValue); ok {
+			str = append(str, string(s))
+		} else if s, ok := toString(v); ok {
+			str = append(str, s)
+		}
+	}
+	return str
+}
+
+// keys returns the keys of the dictionary in sorted order.
+func (d Dict) keys() []string {
+	list := make([]string, 0, len(d))
+	for k := range d {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
+
 func (d Dict) getValue(key string) Value {
 	return d[strings.ToLower(key)]
 }
 
-func parseValueAsDict(r *Reader, key []byte) (Dict, error) {
+func parseValueAsDict(r *Reader, key *cryptKey) (Dict, error) {
 	val, err := parseValue(r, key)
 	if err != nil {
 		return nil, err
@@ -139,7 +242,7 @@ func parseValueAsDict(r *Reader, key []byte) (Dict, error) {
 	return dict, nil
 }
 
-func parseValue(r *Reader, key []byte) (Value, error) {
+func parseValue(r *Reader, key *cryptKey) (Value, error) {
 	skipBlank(r)
 	switch b, _ := r.ReadByte(); {
 	case b == langle:
@@ -155,7 +258,8 @@ func parseValue(r *Reader, key []byte) (Value, error) {
 		return parseArray(r, key)
 	case b == slash:
 		r.UnreadByte()
-		return parseName(r)
+		name, err := parseName(r)
+		return NameValue(name), err
 	case isLetter(b):
 		r.UnreadByte()
 		return parseIdent(r)
@@ -167,7 +271,7 @@ func parseValue(r *Reader, key []byte) (Value, error) {
 	}
 }
 
-func parseArray(r *Reader, key []byte) (Value, error) {
+func parseArray(r *Reader, key *cryptKey) (Value, error) {
 	var (
 		arr []interface{}
 		err error
@@ -195,7 +299,7 @@ func parseArray(r *Reader, key []byte) (Value, error) {
 	return arr, nil
 }
 
-func parseDict(r *Reader, key []byte) (Value, error) {
+func parseDict(r *Reader, key *cryptKey) (Value, error) {
 	dict := make(Dict)
 	for {
 		skipBlank(r)
@@ -236,7 +340,7 @@ func parseNumber(r *Reader) (Value, error) {
 		)
 		rev, ok, err = parseReference(r)
 		if ok && err == nil {
-			return fmt.Sprintf("%s/%s", str, rev), nil
+			return Reference(fmt.Sprintf("%s/%s", str, rev)), nil
 		}
 	} else {
 		r.UnreadByte()
@@ -305,7 +409,7 @@ func parseIdent(r *Reader) (Value, error) {
 	return ident, nil
 }
 
-func parseHex(r *Reader, key []byte) (Value, error) {
+func parseHex(r *Reader, key *cryptKey) (Value, error) {
 	var (
 		str bytes.Buffer
 		err error
@@ -339,11 +443,10 @@ func parseHex(r *Reader, key []byte) (Value, error) {
 	if b != rangle {
 		return "", fmt.Errorf("parseHex: unterminated string")
 	}
-	s := convertString(decryptString(key, str.String()))
-	return s, nil
+	return StringValue(decryptBytes(key, str.Bytes())), nil
 }
 
-func parseString(r *Reader, key []byte) (Value, error) {
+func parseString(r *Reader, key *cryptKey) (Value, error) {
 	var (
 		parens int = 1
 		str    bytes.Buffer
@@ -380,6 +483,10 @@ func parseString(r *Reader, key []byte) (Value, error) {
 			case 'f':
 				b = formfeed
 			case lparen, rparen, backslash:
+			default:
+				if isOctal(b) {
+					b = readOctal(r, b)
+				}
 			}
 		}
 		str.WriteByte(b)
@@ -387,8 +494,23 @@ func parseString(r *Reader, key []byte) (Value, error) {
 	if b != rparen {
 		return nil, fmt.Errorf("parseString: unterminated string")
 	}
-	s := convertString(decryptString(key, str.String()))
-	return s, nil
+	return StringValue(decryptBytes(key, str.Bytes())), nil
+}
+
+func readOctal(r *Reader, b byte) byte {
+	n := b - '0'
+	for i := 0; i < 2; i++ {
+		c, err := r.ReadByte()
+		if err != nil {
+			break
+		}
+		if !isOctal(c) {
+			r.UnreadByte()
+			break
+		}
+		n = (n << 3) | (c - '0')
+	}
+	return n
 }
 
 func parseName(r *Reader) (string, error) {
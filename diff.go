@@ -0,0 +1,224 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return "modified"
+	}
+}
+
+// Change is a difference between two documents, Old or New empty if missing.
+type Change struct {
+	Kind    ChangeKind
+	Section string
+	Key     string
+	Old     string
+	New     string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s %s", c.Kind, c.Section, c.Key)
+}
+
+// Diff compares the info, fonts, objects (by number) and text of two documents.
+func Diff(a, b *Document) ([]Change, error) {
+	var list []Change
+	if n, m := a.GetCount(), b.GetCount(); n != m {
+		list = append(list, Change{
+			Kind:    ChangeModified,
+			Section: "pages",
+			Key:     "count",
+			Old:     fmt.Sprint(n),
+			New:     fmt.Sprint(m),
+		})
+	}
+	list = append(list, diffInfo(a.GetDocumentInfo(), b.GetDocumentInfo())...)
+	list = append(list, diffFonts(a.GetFonts(), b.GetFonts())...)
+
+	changes, err := diffObjects(a, b)
+	if err != nil {
+		return nil, err
+	}
+	list = append(list, changes...)
+
+	changes, err = diffText(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return append(list, changes...), nil
+}
+
+func diffInfo(a, b FileInfo) []Change {
+	date := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	fields := []struct {
+		Key string
+		Old string
+		New string
+	}{
+		{Key: "title", Old: a.Title, New: b.Title},
+		{Key: "author", Old: a.Author, New: b.Author},
+		{Key: "subject", Old: a.Subject, New: b.Subject},
+		{Key: "keywords", Old: strings.Join(a.Keywords, ", "), New: strings.Join(b.Keywords, ", ")},
+		{Key: "creator", Old: a.Creator, New: b.Creator},
+		{Key: "producer", Old: a.Producer, New: b.Producer},
+		{Key: "created", Old: date(a.Created), New: date(b.Created)},
+		{Key: "modified", Old: date(a.Modified), New: date(b.Modified)},
+	}
+	var list []Change
+	for _, f := range fields {
+		if f.Old == f.New {
+			continue
+		}
+		list = append(list, newChange("info", f.Key, f.Old, f.New))
+	}
+	return list
+}
+
+func diffFonts(a, b []Font) []Change {
+	names := func(list []Font) map[string]bool {
+		set := make(map[string]bool)
+		for _, f := range list {
+			set[f.Base] = true
+		}
+		return set
+	}
+	var (
+		old  = names(a)
+		curr = names(b)
+		list []Change
+	)
+	for _, n := range sortedKeys(old) {
+		if !curr[n] {
+			list = append(list, newChange("font", n, n, ""))
+		}
+	}
+	for _, n := range sortedKeys(curr) {
+		if !old[n] {
+			list = append(list, newChange("font", n, "", n))
+		}
+	}
+	return list
+}
+
+func diffObjects(a, b *Document) ([]Change, error) {
+	var (
+		old  = make(map[string]Pointer)
+		list []Change
+	)
+	for _, p := range a.sortedXRef() {
+		old[p.Oid] = p
+	}
+	for _, p := range b.sortedXRef() {
+		q, ok := old[p.Oid]
+		if !ok {
+			list = append(list, newChange("object", p.Oid, "", p.Oid))
+			continue
+		}
+		delete(old, p.Oid)
+		x, err := a.readRawObject(q)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", q.Oid, err)
+		}
+		y, err := b.readRawObject(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Oid, err)
+		}
+		if !bytes.Equal(normalizeValue(x.Value), normalizeValue(y.Value)) || !bytes.Equal(x.Stream, y.Stream) {
+			list = append(list, newChange("object", p.Oid, p.Oid, p.Oid))
+		}
+	}
+	for _, p := range a.sortedXRef() {
+		if _, ok := old[p.Oid]; ok {
+			list = append(list, newChange("object", p.Oid, p.Oid, ""))
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return objectNumber(list[i].Key) < objectNumber(list[j].Key)
+	})
+	return list, nil
+}
+
+func diffText(a, b *Document) ([]Change, error) {
+	var (
+		n    = int(a.GetCount())
+		m    = int(b.GetCount())
+		list []Change
+	)
+	for i := 1; i <= n || i <= m; i++ {
+		var (
+			old, curr string
+			err       error
+		)
+		if i <= n {
+			if old, err = a.GetText(i); err != nil {
+				return nil, fmt.Errorf("page %d: %w", i, err)
+			}
+		}
+		if i <= m {
+			if curr, err = b.GetText(i); err != nil {
+				return nil, fmt.Errorf("page %d: %w", i, err)
+			}
+		}
+		key := fmt.Sprintf("page %d", i)
+		switch {
+		case i > n:
+			list = append(list, Change{Kind: ChangeAdded, Section: "text", Key: key, New: curr})
+		case i > m:
+			list = append(list, Change{Kind: ChangeRemoved, Section: "text", Key: key, Old: old})
+		case old != curr:
+			list = append(list, Change{Kind: ChangeModified, Section: "text", Key: key, Old: old, New: curr})
+		}
+	}
+	return list, nil
+}
+
+func newChange(section, key, old, curr string) Change {
+	c := Change{
+		Kind:    ChangeModified,
+		Section: section,
+		Key:     key,
+		Old:     old,
+		New:     curr,
+	}
+	switch {
+	case old == "":
+		c.Kind = ChangeAdded
+	case curr == "":
+		c.Kind = ChangeRemoved
+	}
+	return c
+}
+
+func sortedKeys(set map[string]bool) []string {
+	list := make([]string, 0, len(set))
+	for k := range set {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -2,13 +2,14 @@ package pdf
 
 import (
 	"bytes"
-	"crypto/md5"
-	"crypto/rc4"
+	"context"
 	"fmt"
 	"image"
 	"io"
+	"math"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,13 +22,17 @@ type Font struct {
 	Flags    uint32
 	First    byte
 	Last     byte
-}
+	Matrix   [6]float64
+
+	ItalicAngle float64
+	Ascent      float64
+	Descent     float64
+	CapHeight   float64
+	Embedded    bool
+	Subset      bool
 
-type Signature struct {
-	Who    string
-	When   time.Time
-	Reason string
-	Pem    []byte
+	oid   string
+	codes map[uint32]rune
 }
 
 type FileInfo struct {
@@ -41,39 +46,256 @@ type FileInfo struct {
 	Modified time.Time
 	Trapped  bool
 
+	DocumentID      string
+	PDFAPart        int
+	PDFAConformance string
+
 	Fields map[string]Value
 }
 
+const (
+	OutlineItalic int64 = 1 << iota
+	OutlineBold
+)
+
 type Outline struct {
-	Title string
-	Sub   []Outline
+	Title  string
+	Page   int
+	Dest   string
+	Target Destination
+	Action string
+	URI    string
+	File   string
+	Color  [3]float64
+	Flags  int64
+	Open   bool
+	Sub    []Outline
+}
+
+func (o Outline) Italic() bool {
+	return o.Flags&OutlineItalic != 0
 }
 
+func (o Outline) Bold() bool {
+	return o.Flags&OutlineBold != 0
+}
+
+// Document can be used by several goroutines at once. Objects are read from
+// the source with ReadAt and the parts of the document loaded on demand (the
+// cross reference sections of linearized files, the page index and the named
+// destinations) are guarded.
 type Document struct {
-	inner *Reader
-	xref  []Pointer
+	src    io.ReaderAt
+	closer io.Closer
+	cache  *objectCache
+	size   int64
+	xmu    sync.RWMutex
+	xref   []Pointer
+	free   []Pointer
 
 	catalog string
 	info    string
 	encrypt string
 
-	fileid  []string
-	decrypt []byte
+	trailer   Dict
+	fileid    []string
+	decrypt   []byte
+	password  []byte
+	access    Password
+	locked    bool
+	recipient *recipientKey
+	filters   map[string]cryptFilter
+	metadata  bool
+	stmf      string
+	strf      string
+	eff       string
+
+	linear   *linearization
+	prev     int64
+	loaded   map[int64]bool
+	quirks   Quirk
+	mode     ParseMode
+	recovery bool
+	salvaged bool
+
+	cacheSize int64
+	cmapDir   string
+
+	mu       sync.Mutex
+	pages    []string
+	pageNums map[string]int
+	dests    map[string]Value
 }
 
-func Open(file string) (*Document, error) {
-	return readFile(file)
+type Option func(*Document) error
+
+func WithPassword(password string) Option {
+	return func(d *Document) error {
+		d.password = []byte(password)
+		return nil
+	}
+}
+
+// WithLocked lets encrypted documents be opened even when they can not be
+// decrypted with the given credentials. Only the unencrypted parts of the
+// file (xref, trailer, encryption dictionary) can then be used.
+func WithLocked() Option {
+	return func(d *Document) error {
+		d.locked = true
+		return nil
+	}
+}
+
+func Open(file string, opts ...Option) (*Document, error) {
+	return readFile(file, opts...)
+}
+
+func OpenReader(r io.ReaderAt, size int64, opts ...Option) (*Document, error) {
+	return readDocument(r, size, opts...)
 }
 
 func (d *Document) Close() error {
-	return d.inner.Close()
+	if d.closer != nil {
+		return d.closer.Close()
+	}
+	if c, ok := d.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
 }
 
+type WalkOrder int
+
+const (
+	WalkXRef WalkOrder = iota
+	WalkNumber
+	WalkOffset
+	WalkReachable
+)
+
 func (d *Document) Walk(fn func(Object) bool) error {
 	return d.walkObjects(false, fn)
 }
 
+func (d *Document) WalkWith(order WalkOrder, fn func(Object) bool) error {
+	switch order {
+	case WalkXRef:
+		return d.walkObjects(false, fn)
+	case WalkNumber:
+		return d.walkPointers(d.sortedXRef(), fn)
+	case WalkOffset:
+		list := d.sortedXRef()
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Offset < list[j].Offset
+		})
+		return d.walkPointers(list, fn)
+	case WalkReachable:
+		return d.walkReachable(fn)
+	default:
+		return fmt.Errorf("walk order %d: %w", order, ErrUnsupported)
+	}
+}
+
+// WalkContext is like Walk but stops as soon as ctx is done.
+func (d *Document) WalkContext(ctx context.Context, fn func(Object) bool) error {
+	return d.WalkWithContext(ctx, WalkXRef, fn)
+}
+
+func (d *Document) WalkWithContext(ctx context.Context, order WalkOrder, fn func(Object) bool) error {
+	var err error
+	e := d.WalkWith(order, func(obj Object) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		return fn(obj)
+	})
+	if e != nil {
+		return e
+	}
+	return err
+}
+
+func (d *Document) walkPointers(list []Pointer, fn func(Object) bool) error {
+	for _, x := range list {
+		if x.isEmbed() {
+			continue
+		}
+		obj := d.getObjectWithOid(x.Oid, true)
+		if obj.isZero() {
+			continue
+		}
+		if !fn(obj) {
+			break
+		}
+	}
+	return nil
+}
+
+// walkReachable visits objects depth first from the trailer, objects
+// stored in object streams included, in the order they are referenced.
+func (d *Document) walkReachable(fn func(Object) bool) error {
+	var (
+		seen = make(map[string]bool)
+		todo []string
+	)
+	for _, oid := range []string{d.encrypt, d.info, d.catalog} {
+		if oid != "" {
+			todo = append(todo, oid)
+		}
+	}
+	for len(todo) > 0 {
+		oid := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+		if seen[oid] {
+			continue
+		}
+		seen[oid] = true
+		obj := d.getObjectWithOid(oid, true)
+		if obj.isZero() {
+			continue
+		}
+		if !fn(obj) {
+			break
+		}
+		var refs []string
+		if obj.Dict != nil {
+			refs = collectRefs(obj.Dict, refs)
+		} else {
+			refs = collectRefs(obj.Data, refs)
+		}
+		for i := len(refs) - 1; i >= 0; i-- {
+			if !seen[refs[i]] {
+				todo = append(todo, refs[i])
+			}
+		}
+	}
+	return nil
+}
+
+func collectRefs(v Value, refs []string) []string {
+	switch v := v.(type) {
+	case Reference:
+		refs = append(refs, string(v))
+	case []interface{}:
+		for i := range v {
+			refs = collectRefs(v[i], refs)
+		}
+	case Dict:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			refs = collectRefs(v[k], refs)
+		}
+	}
+	return refs
+}
+
 func (d *Document) walkObjects(embbeded bool, fn func(Object) bool) error {
+	for d.loadPending() {
+	}
 	for _, x := range d.xref {
 		if !embbeded && x.isEmbed() {
 			continue
@@ -89,6 +311,37 @@ func (d *Document) walkObjects(embbeded bool, fn func(Object) bool) error {
 	return nil
 }
 
+// WalkPages visits the pages in the order of the page tree.
+func (d *Document) WalkPages(fn func(int, Object) bool) error {
+	for i, oid := range d.pageIndex() {
+		obj := d.getObjectWithOid(oid, false)
+		if obj.isZero() {
+			continue
+		}
+		if !fn(i+1, obj) {
+			break
+		}
+	}
+	return nil
+}
+
+func (d *Document) WalkImages(fn func(Object) bool) error {
+	return d.walkType(Object.IsImage, fn)
+}
+
+func (d *Document) WalkFonts(fn func(Object) bool) error {
+	return d.walkType(Object.IsFont, fn)
+}
+
+func (d *Document) walkType(is func(Object) bool, fn func(Object) bool) error {
+	return d.walkObjects(true, func(obj Object) bool {
+		if !is(obj) {
+			return true
+		}
+		return fn(obj)
+	})
+}
+
 func (d *Document) GetLang() string {
 	obj := d.getCatalog()
 	if obj.isZero() {
@@ -101,54 +354,174 @@ func (d *Document) GetFonts() []Font {
 	var list []Font
 	d.walkObjects(true, func(o Object) bool {
 		if o.IsFont() {
-			f := Font{
-				Name:     o.GetString("name"),
-				Base:     o.GetString("basefont"),
-				Sub:      o.GetString("subtype"),
-				Encoding: o.GetString("encoding"),
-				Unicode:  o.Has("tounicode"),
-				Flags:    0,
-				First:    byte(o.GetInt("firstchar")),
-				Last:     byte(o.GetInt("lastchar")),
-			}
-			if o := d.getObjectWithOid(o.GetString("fontdescriptor"), false); !o.isZero() {
-				f.Flags = uint32(o.GetInt("flags"))
-			}
-			list = append(list, f)
+			list = append(list, d.getFont(o))
 		}
 		return true
 	})
 	return list
 }
 
+// GetPageFonts returns the fonts of the page and of its forms by resource name.
+func (d *Document) GetPageFonts(page int) ([]Font, error) {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return nil, fmt.Errorf("page %d not found in document", page)
+	}
+	var (
+		list []Font
+		seen = make(map[string]bool)
+		walk func(Dict)
+	)
+	walk = func(res Dict) {
+		fonts, _ := d.resolve(res.getValue("font")).(Dict)
+		for _, name := range fonts.keys() {
+			oid, _ := toOid(fonts[name])
+			if seen[oid] {
+				continue
+			}
+			seen[oid] = true
+			f := d.getObjectWithOid(oid, false)
+			if f.isZero() {
+				continue
+			}
+			font := d.getFont(f)
+			font.Name = name
+			list = append(list, font)
+		}
+		xobjects, _ := d.resolve(res.getValue("xobject")).(Dict)
+		for _, name := range xobjects.keys() {
+			oid, _ := toOid(xobjects[name])
+			if seen[oid] {
+				continue
+			}
+			seen[oid] = true
+			x := d.getObjectWithOid(oid, false)
+			if x.Subtype() != "Form" {
+				continue
+			}
+			if res, _ := d.resolve(x.getValue("resources")).(Dict); res != nil {
+				walk(res)
+			}
+		}
+	}
+	walk(d.getPageResources(obj))
+	return list, nil
+}
+
+func (d *Document) getFont(o Object) Font {
+	f := Font{
+		Name:     o.GetString("name"),
+		Base:     o.GetString("basefont"),
+		Sub:      o.GetString("subtype"),
+		Encoding: o.GetString("encoding"),
+		Unicode:  o.Has("tounicode"),
+		First:    byte(o.GetInt("firstchar")),
+		Last:     byte(o.GetInt("lastchar")),
+		Matrix:   d.getFontMatrix(o),
+		Embedded: o.Subtype() == "Type3",
+		Subset:   isSubsetName(o.GetString("basefont")),
+		oid:      o.Oid,
+		codes:    d.getFontCodes(o),
+	}
+	desc, _ := d.resolve(o.getValue("fontdescriptor")).(Dict)
+	if desc == nil && f.Sub == "Type0" {
+		if kids, _ := d.resolve(o.getValue("descendantfonts")).([]interface{}); len(kids) > 0 {
+			cid, _ := d.resolve(kids[0]).(Dict)
+			desc, _ = d.resolve(cid.getValue("fontdescriptor")).(Dict)
+		}
+	}
+	if desc != nil {
+		f.Flags = uint32(desc.GetInt("flags"))
+		f.ItalicAngle = getNumber(d.resolve(desc.getValue("italicangle")))
+		f.Ascent = getNumber(d.resolve(desc.getValue("ascent")))
+		f.Descent = getNumber(d.resolve(desc.getValue("descent")))
+		f.CapHeight = getNumber(d.resolve(desc.getValue("capheight")))
+		if f.Sub != "Type3" {
+			f.Embedded = desc.Has("fontfile") || desc.Has("fontfile2") || desc.Has("fontfile3")
+		}
+	}
+	return f
+}
+
+// isSubsetName reports whether name has a subset tag (ABCDEF+Name).
+func isSubsetName(name string) bool {
+	if len(name) < 8 || name[6] != '+' {
+		return false
+	}
+	for i := 0; i < 6; i++ {
+		if name[i] < 'A' || name[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
 func (d *Document) GetImage(name string) image.Image {
+	if page, index, ok := parseImageName(name); ok {
+		img, _ := d.GetPageImage(page, index)
+		return img
+	}
 	obj := d.getObjectWithOid(d.getImageOid(name), true)
 	if obj.isZero() {
 		return nil
 	}
-	return obj.readImage()
+	img, _ := d.DecodeImage(obj, ImageRGB)
+	return img
 }
 
 func (d *Document) getImageOid(name string) string {
-	keep := func(o Object) bool {
-		return o.IsPage() && o.Has("resources")
-	}
-	var oid string
-	d.Walk(func(o Object) bool {
-		if keep(o) {
-			oid = o.GetResources().GetDict("xobject").GetString(name)
-		}
-		if oid == "" {
-			list := o.GetEmbeddedObjects()
-			for i := 0; oid == "" && i < len(list); i++ {
-				if keep(list[i]) {
-					oid = o.GetResources().GetDict("xobject").GetString(name)
-				}
-			}
+	for _, oid := range d.pageIndex() {
+		res := d.getPageResources(d.getObjectWithOid(oid, false))
+		xobj, _ := d.resolve(res.getValue("xobject")).(Dict)
+		if oid := xobj.GetString(name); oid != "" {
+			return oid
 		}
-		return oid == ""
-	})
-	return oid
+	}
+	return ""
+}
+
+func (d *Document) getPageResources(obj Object) Dict {
+	if res, ok := d.getInherited(obj, "resources").(Dict); ok {
+		return res
+	}
+	return make(Dict)
+}
+
+func (d *Document) getPageBox(obj Object) Rect {
+	box := Rect{0, 0, 612, 792}
+	if r := getRect(d.getInherited(obj, "mediabox")); r[0] < r[2] && r[1] < r[3] {
+		box = r
+	}
+	if v := d.getInherited(obj, "cropbox"); v != nil {
+		crop := getRect(v)
+		crop = Rect{
+			math.Max(crop[0], box[0]),
+			math.Max(crop[1], box[1]),
+			math.Min(crop[2], box[2]),
+			math.Min(crop[3], box[3]),
+		}
+		if crop[0] < crop[2] && crop[1] < crop[3] {
+			box = crop
+		}
+	}
+	return box
+}
+
+func (d *Document) getPageRotation(obj Object) int {
+	n, _ := d.getInherited(obj, "rotate").(int64)
+	return int((n%360 + 360) % 360)
+}
+
+func (d *Document) getInherited(obj Object, key string) Value {
+	seen := make(map[string]bool)
+	for !obj.isZero() && !seen[obj.Oid] {
+		seen[obj.Oid] = true
+		if v := d.resolve(obj.getValue(key)); v != nil {
+			return v
+		}
+		obj = d.getObjectWithOid(obj.GetString("parent"), false)
+	}
+	return nil
 }
 
 func (d *Document) GetDocumentMetadata() []byte {
@@ -160,28 +533,21 @@ func (d *Document) GetDocumentMetadata() []byte {
 	if obj.isZero() {
 		return nil
 	}
-	var (
-		body, _ = obj.Body()
-		key     = d.getEncryptionKeyForObject(obj)
-	)
-	return decryptBytes(key, body)
+	body, _ := obj.Body()
+	return body
 }
 
-func (d *Document) GetSignatures() []Signature {
-	var list []Signature
-	d.Walk(func(o Object) bool {
-		if o.IsSignature() {
-			key := d.getEncryptionKeyForObject(o)
-			sig := Signature{
-				Who:    decryptString(key, o.GetString("name")),
-				Reason: decryptString(key, o.GetString("reason")),
-			}
-			sig.When, _ = parseTime(decryptString(key, o.GetString("m")))
-			list = append(list, sig)
-		}
-		return true
-	})
-	return list
+// Trailer returns the trailer of the last cross reference section.
+func (d *Document) Trailer() Dict {
+	return d.trailer
+}
+
+func (d *Document) ID() [2][]byte {
+	var id [2][]byte
+	for i := 0; i < len(d.fileid) && i < len(id); i++ {
+		id[i] = []byte(d.fileid[i])
+	}
+	return id
 }
 
 func (d *Document) GetVersion() string {
@@ -189,12 +555,18 @@ func (d *Document) GetVersion() string {
 	if !obj.isZero() && obj.Has("version") {
 		return obj.GetString("version")
 	}
-	str := readVersion(d.inner)
+	str := readVersion(d)
 	str = bytes.TrimLeft(str, "%PDF-")
 	return string(str)
 }
 
 func (d *Document) GetDocumentInfo() FileInfo {
+	fi := d.getInfoDict()
+	d.mergeXMP(&fi)
+	return fi
+}
+
+func (d *Document) getInfoDict() FileInfo {
 	var (
 		fi   FileInfo
 		when string
@@ -211,13 +583,9 @@ func (d *Document) GetDocumentInfo() FileInfo {
 	fi.Producer = obj.GetString("producer")
 
 	when = obj.GetString("creationdate")
-	if strings.HasPrefix(when, "D:") {
-		fi.Created, _ = parseTime(when)
-	}
+	fi.Created, _ = d.parseDate(when)
 	when = obj.GetString("moddate")
-	if strings.HasPrefix(when, "D:") {
-		fi.Modified, _ = parseTime(when)
-	}
+	fi.Modified, _ = d.parseDate(when)
 
 	fi.Fields = make(map[string]Value)
 	for k := range obj.Dict {
@@ -231,7 +599,7 @@ func (d *Document) GetDocumentInfo() FileInfo {
 }
 
 func (d *Document) GetOutlines() []Outline {
-	list := d.getOutlines(d.getOutlinesFromCatalog())
+	list := d.getOutlines(d.getOutlinesFromCatalog(), d.getPageNumbers())
 	if len(list) <= 1 {
 		return nil
 	}
@@ -259,19 +627,19 @@ func (d *Document) GetOutlines() []Outline {
 // }
 
 func (d *Document) GetCount() int64 {
+	if d.linear != nil && d.linear.Count > 0 {
+		return d.linear.Count
+	}
 	obj := d.getPageRoot()
-	if obj.isZero() {
-		return 0
+	if n := obj.GetInt("count"); n > 0 {
+		return n
 	}
-	return obj.GetInt("count")
+	return int64(len(d.pageIndex()))
 }
 
 func (d *Document) GetPageCode(n int) ([]byte, error) {
-	obj := d.getPageRoot()
+	obj := d.getPage(n)
 	if obj.isZero() {
-		return nil, fmt.Errorf("empty document")
-	}
-	if obj = d.getPageObject(obj, n); obj.isZero() {
 		return nil, fmt.Errorf("page %d not found in document", n)
 	}
 	list := obj.GetStringArray("contents")
@@ -298,30 +666,63 @@ func (d *Document) GetPage(n int) ([]byte, error) {
 	return body, err
 }
 
-func (d *Document) getPageObject(obj Object, page int) Object {
-	if obj.IsPage() {
-		return obj
+func (d *Document) getPage(n int) Object {
+	if n == 1 && d.linear != nil && d.linear.First > 0 && !d.hasPageIndex() {
+		obj := d.getObjectWithOid(d.findOid(d.linear.First), false)
+		if obj.IsPage() {
+			return obj
+		}
 	}
-	var (
-		kids  = obj.GetStringArray("kids")
-		count = int(obj.GetInt("count"))
-	)
-	if page < 1 || page > count {
+	list := d.pageIndex()
+	if n < 1 || n > len(list) {
 		return Object{}
 	}
-	for _, k := range kids {
-		obj = d.getObjectWithOid(k, false)
-		if obj.IsPage() || obj.isZero() {
-			return obj
+	return d.getObjectWithOid(list[n-1], false)
+}
+
+func (d *Document) getPageNumbers() map[string]int {
+	d.pageIndex()
+	return d.pageNums
+}
+
+func (d *Document) hasPageIndex() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pages != nil
+}
+
+func (d *Document) pageIndex() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pages != nil {
+		return d.pages
+	}
+	var (
+		list = []string{}
+		seen = make(map[string]bool)
+		walk func(Object)
+	)
+	walk = func(obj Object) {
+		if obj.isZero() || seen[obj.Oid] {
+			return
 		}
-		if count = int(obj.GetInt("count")); page <= count {
-			kids = obj.GetStringArray("kids")
-			obj = d.getObjectWithOid(kids[page-1], false)
-			return obj
+		seen[obj.Oid] = true
+		if obj.IsPage() {
+			list = append(list, obj.Oid)
+			return
+		}
+		for _, k := range obj.GetStringArray("kids") {
+			walk(d.getObjectWithOid(k, false))
 		}
-		page -= count
 	}
-	return Object{}
+	walk(d.getPageRoot())
+
+	d.pages = list
+	d.pageNums = make(map[string]int, len(list))
+	for i, oid := range list {
+		d.pageNums[oid] = i + 1
+	}
+	return d.pages
 }
 
 func (d *Document) getOutlinesFromCatalog() Object {
@@ -332,7 +733,7 @@ func (d *Document) getOutlinesFromCatalog() Object {
 	return d.getObjectWithOid(obj.GetString("outlines"), false)
 }
 
-func (d *Document) getOutlines(obj Object) []Outline {
+func (d *Document) getOutlines(obj Object, pages map[string]int) []Outline {
 	if obj.isZero() {
 		return nil
 	}
@@ -347,15 +748,98 @@ func (d *Document) getOutlines(obj Object) []Outline {
 			return nil
 		}
 		first = obj.GetString("next")
-		line := Outline{Title: obj.GetString("title")}
+		line := Outline{Title: d.resolveString(obj.getValue("title"))}
+		d.setOutlineAction(&line, obj, pages)
+		d.setOutlineStyle(&line, obj)
 		if obj.Has("first") {
-			line.Sub = d.getOutlines(obj)
+			line.Sub = d.getOutlines(obj, pages)
 		}
 		lines = append(lines, line)
 	}
 	return lines
 }
 
+func (d *Document) setOutlineAction(line *Outline, obj Object, pages map[string]int) {
+	if dest := obj.getValue("dest"); dest != nil {
+		line.Action = "GoTo"
+		line.Target = d.getDestination(d.resolve(dest), pages)
+	} else {
+		a, _ := d.resolve(obj.getValue("a")).(Dict)
+		line.Action = a.GetString("s")
+		switch line.Action {
+		case "GoTo":
+			line.Target = d.getDestination(d.resolve(a.getValue("d")), pages)
+		case "GoToR":
+			line.File = d.getFileSpec(a.getValue("f"))
+			switch dest := d.resolve(a.getValue("d")).(type) {
+			case NameValue, StringValue:
+				line.Target.Name, _ = toString(dest)
+			case []interface{}:
+				line.Target = d.getDestination(dest, nil)
+			}
+		case "URI":
+			line.URI = strings.TrimSpace(d.resolveString(a.getValue("uri")))
+		case "Launch":
+			line.File = d.getFileSpec(a.getValue("f"))
+		}
+	}
+	if line.Action != "GoToR" {
+		line.Page = line.Target.Page
+	}
+	line.Dest = line.Target.String()
+}
+
+func (d *Document) setOutlineStyle(line *Outline, obj Object) {
+	line.Flags, _ = d.resolve(obj.getValue("f")).(int64)
+	if arr, _ := d.resolve(obj.getValue("c")).([]interface{}); len(arr) == 3 {
+		for i := range arr {
+			line.Color[i] = getNumber(d.resolve(arr[i]))
+		}
+	}
+	count, _ := d.resolve(obj.getValue("count")).(int64)
+	line.Open = count > 0
+}
+
+func (d *Document) getOutlineTarget(obj Object, pages map[string]int) (int, string) {
+	var line Outline
+	d.setOutlineAction(&line, obj, pages)
+	return line.Page, line.Dest
+}
+
+func (d *Document) getFileSpec(v Value) string {
+	switch v := d.resolve(v).(type) {
+	case StringValue:
+		return v.String()
+	case Dict:
+		if str := d.resolveString(v.getValue("uf")); str != "" {
+			return str
+		}
+		return d.resolveString(v.getValue("f"))
+	default:
+		return ""
+	}
+}
+
+func (d *Document) resolve(v Value) Value {
+	oid, ok := toOid(v)
+	if !ok {
+		return v
+	}
+	obj := d.getObjectWithOid(oid, false)
+	if obj.isZero() {
+		return v
+	}
+	if obj.Dict != nil {
+		return obj.Dict
+	}
+	return obj.Data
+}
+
+func (d *Document) resolveString(v Value) string {
+	s, _ := toString(d.resolve(v))
+	return s
+}
+
 func (d *Document) getPageRoot() Object {
 	obj := d.getCatalog()
 	if obj.isZero() {
@@ -372,84 +856,81 @@ func (d *Document) getObjectWithOid(oid string, full bool) Object {
 	if oid == "" {
 		return Object{}
 	}
-	i := sort.Search(len(d.xref), func(i int) bool {
-		return d.xref[i].Oid <= oid
-	})
-	if i >= len(d.xref) || d.xref[i].Oid != oid {
+	if obj, ok := d.getCachedObject(oid, full); ok {
+		return obj
+	}
+	p, ok := d.lookupOid(oid)
+	if !ok {
 		return Object{}
 	}
-	var (
-		obj Object
-		key []byte
-	)
-	if oid != d.encrypt {
-		key = d.decrypt
+	var obj Object
+	if !p.isEmbed() {
+		var err error
+		obj, err = d.readObjectAt(p.Offset, d.keyFor(oid), full)
+		if err == nil && obj.Oid != oid && d.lenient() && objectNumber(obj.Oid) == objectNumber(oid) {
+			obj.Oid = oid
+		}
+		if (err != nil || obj.Oid != oid) && (d.quirks.Has(QuirkXRefOffset) || d.lenient()) {
+			obj, _ = d.searchObject(oid, p.Offset)
+		}
+	} else if stm, err := d.getObjectStream(p.Owner); err == nil && stm.IsObjectStream() {
+		buf, err := stm.embeddedBytes(stm.body, p.Oid, p.Offset)
+		if err == nil {
+			obj = parseEmbeddedObject(p.Oid, buf)
+		}
 	}
-	if !d.xref[i].isEmbed() {
-		d.inner.Seek(d.xref[i].Offset, io.SeekStart)
-		obj, _ = readObject(d.inner, key, full)
-	} else {
-		obj = d.getObjectWithOid(d.xref[i].Owner, true)
-		obj = obj.getEmbeddedObject(d.xref[i].Oid, d.xref[i].Offset)
+	if obj.Oid == oid {
+		d.cacheObject(obj, full)
 	}
 	return obj
 }
 
-func (d *Document) getEncryptionKeyForObject(obj Object) []byte {
-	oid, rev := obj.ObjectId()
-	return getEncryptionKey(d.decrypt, oid, rev)
-}
-
-func (d *Document) setupKey() error {
-	if d.encrypt == "" {
-		return nil
-	}
-	var (
-		sum    = md5.New()
-		obj    = d.getObjectWithOid(d.encrypt, false)
-		user   = obj.GetBytes("u")
-		size   = obj.GetInt("length")
-		owner  = obj.GetBytes("o")
-		access = obj.GetInt("p")
-		perm   = uint32(access)
-	)
-
-	sum.Write(padding)
-	sum.Write(owner)
-	sum.Write([]byte{byte(perm), byte(perm >> 8), byte(perm >> 16), byte(perm >> 24)})
-	sum.Write([]byte(d.fileid[0]))
-
-	key := sum.Sum(nil)
-	for i := 0; i < 50; i++ {
-		sum.Reset()
-		sum.Write(key[:size/8])
-		key = sum.Sum(nil)
+func (d *Document) keyFor(oid string) cryptKeys {
+	if oid == d.encrypt || len(d.decrypt) == 0 {
+		return cryptKeys{}
 	}
-	d.decrypt = key[:size/8]
-
-	sum.Reset()
-	sum.Write(padding)
-	sum.Write([]byte(d.fileid[0]))
-	final := sum.Sum(nil)
-
-	ciph, err := rc4.NewCipher(d.decrypt)
-	if err != nil {
-		return err
+	return cryptKeys{
+		str: d.fileKey(d.strf),
+		stm: d.fileKey(d.stmf),
 	}
-	ciph.XORKeyStream(final, final)
+}
 
-	tmp := make([]byte, len(d.decrypt))
-	for i := 1; i < 20; i++ {
-		copy(tmp, d.decrypt)
-		for j := range tmp {
-			tmp[j] ^= byte(i)
+func (d *Document) lookupOid(oid string) (Pointer, bool) {
+	for {
+		d.xmu.RLock()
+		i := sort.Search(len(d.xref), func(i int) bool {
+			return d.xref[i].Oid <= oid
+		})
+		var (
+			ok = i < len(d.xref) && d.xref[i].Oid == oid
+			p  Pointer
+		)
+		if ok {
+			p = d.xref[i]
+		}
+		d.xmu.RUnlock()
+		if ok {
+			return p, true
+		}
+		if !d.loadPending() {
+			return p, false
 		}
-		c, _ := rc4.NewCipher(tmp)
-		c.XORKeyStream(final, final)
 	}
+}
 
-	if !bytes.HasPrefix(user, final) {
-		return fmt.Errorf("invalid password")
+func (d *Document) findOid(num int64) string {
+	prefix := fmt.Sprintf("%d/", num)
+	for {
+		d.xmu.RLock()
+		for _, x := range d.xref {
+			if strings.HasPrefix(x.Oid, prefix) {
+				d.xmu.RUnlock()
+				return x.Oid
+			}
+		}
+		d.xmu.RUnlock()
+		if !d.loadPending() {
+			return ""
+		}
 	}
-	return nil
 }
@@ -0,0 +1,268 @@
+package pdf
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var standardEncoding = map[byte]string{
+	0x27: "quoteright", 0x60: "quoteleft", 0xa1: "exclamdown", 0xa2: "cent", 0xa3: "sterling",
+	0xa4: "fraction", 0xa5: "yen", 0xa6: "florin", 0xa7: "section", 0xa8: "currency",
+	0xa9: "quotesingle", 0xaa: "quotedblleft", 0xab: "guillemotleft", 0xac: "guilsinglleft",
+	0xad: "guilsinglright", 0xae: "fi", 0xaf: "fl", 0xb1: "endash", 0xb2: "dagger",
+	0xb3: "daggerdbl", 0xb4: "periodcentered", 0xb6: "paragraph", 0xb7: "bullet",
+	0xb8: "quotesinglbase", 0xb9: "quotedblbase", 0xba: "quotedblright", 0xbb: "guillemotright",
+	0xbc: "ellipsis", 0xbd: "perthousand", 0xbf: "questiondown", 0xc1: "grave", 0xc2: "acute",
+	0xc3: "circumflex", 0xc4: "tilde", 0xc5: "macron", 0xc6: "breve", 0xc7: "dotaccent",
+	0xc8: "dieresis", 0xca: "ring", 0xcb: "cedilla", 0xcd: "hungarumlaut", 0xce: "ogonek",
+	0xcf: "caron", 0xd0: "emdash", 0xe1: "AE", 0xe3: "ordfeminine", 0xe8: "Lslash",
+	0xe9: "Oslash", 0xea: "OE", 0xeb: "ordmasculine", 0xf1: "ae", 0xf5: "dotlessi",
+	0xf8: "lslash", 0xf9: "oslash", 0xfa: "oe", 0xfb: "germandbls",
+}
+
+var symbolEncoding = map[byte]rune{
+	0x20: 0x0020, 0x21: 0x0021, 0x22: 0x2200, 0x23: 0x0023, 0x24: 0x2203, 0x25: 0x0025,
+	0x26: 0x0026, 0x27: 0x220b, 0x28: 0x0028, 0x29: 0x0029, 0x2a: 0x2217, 0x2b: 0x002b,
+	0x2c: 0x002c, 0x2d: 0x2212, 0x2e: 0x002e, 0x2f: 0x002f, 0x30: 0x0030, 0x31: 0x0031,
+	0x32: 0x0032, 0x33: 0x0033, 0x34: 0x0034, 0x35: 0x0035, 0x36: 0x0036, 0x37: 0x0037,
+	0x38: 0x0038, 0x39: 0x0039, 0x3a: 0x003a, 0x3b: 0x003b, 0x3c: 0x003c, 0x3d: 0x003d,
+	0x3e: 0x003e, 0x3f: 0x003f, 0x40: 0x2245, 0x41: 0x0391, 0x42: 0x0392, 0x43: 0x03a7,
+	0x44: 0x0394, 0x45: 0x0395, 0x46: 0x03a6, 0x47: 0x0393, 0x48: 0x0397, 0x49: 0x0399,
+	0x4a: 0x03d1, 0x4b: 0x039a, 0x4c: 0x039b, 0x4d: 0x039c, 0x4e: 0x039d, 0x4f: 0x039f,
+	0x50: 0x03a0, 0x51: 0x0398, 0x52: 0x03a1, 0x53: 0x03a3, 0x54: 0x03a4, 0x55: 0x03a5,
+	0x56: 0x03c2, 0x57: 0x03a9, 0x58: 0x039e, 0x59: 0x03a8, 0x5a: 0x0396, 0x5b: 0x005b,
+	0x5c: 0x2234, 0x5d: 0x005d, 0x5e: 0x22a5, 0x5f: 0x005f, 0x61: 0x03b1, 0x62: 0x03b2,
+	0x63: 0x03c7, 0x64: 0x03b4, 0x65: 0x03b5, 0x66: 0x03c6, 0x67: 0x03b3, 0x68: 0x03b7,
+	0x69: 0x03b9, 0x6a: 0x03d5, 0x6b: 0x03ba, 0x6c: 0x03bb, 0x6d: 0x03bc, 0x6e: 0x03bd,
+	0x6f: 0x03bf, 0x70: 0x03c0, 0x71: 0x03b8, 0x72: 0x03c1, 0x73: 0x03c3, 0x74: 0x03c4,
+	0x75: 0x03c5, 0x76: 0x03d6, 0x77: 0x03c9, 0x78: 0x03be, 0x79: 0x03c8, 0x7a: 0x03b6,
+	0x7b: 0x007b, 0x7c: 0x007c, 0x7d: 0x007d, 0x7e: 0x223c, 0xa0: 0x20ac, 0xa1: 0x03d2,
+	0xa2: 0x2032, 0xa3: 0x2264, 0xa4: 0x2044, 0xa5: 0x221e, 0xa6: 0x0192, 0xa7: 0x2663,
+	0xa8: 0x2666, 0xa9: 0x2665, 0xaa: 0x2660, 0xab: 0x2194, 0xac: 0x2190, 0xad: 0x2191,
+	0xae: 0x2192, 0xaf: 0x2193, 0xb0: 0x00b0, 0xb1: 0x00b1, 0xb2: 0x2033, 0xb3: 0x2265,
+	0xb4: 0x00d7, 0xb5: 0x221d, 0xb6: 0x2202, 0xb7: 0x2022, 0xb8: 0x00f7, 0xb9: 0x2260,
+	0xba: 0x2261, 0xbb: 0x2248, 0xbc: 0x2026, 0xbd: 0x23d0, 0xbe: 0x23af, 0xbf: 0x21b5,
+	0xc0: 0x2135, 0xc1: 0x2111, 0xc2: 0x211c, 0xc3: 0x2118, 0xc4: 0x2297, 0xc5: 0x2295,
+	0xc6: 0x2205, 0xc7: 0x2229, 0xc8: 0x222a, 0xc9: 0x2283, 0xca: 0x2287, 0xcb: 0x2284,
+	0xcc: 0x2282, 0xcd: 0x2286, 0xce: 0x2208, 0xcf: 0x2209, 0xd0: 0x2220, 0xd1: 0x2207,
+	0xd2: 0x00ae, 0xd3: 0x00a9, 0xd4: 0x2122, 0xd5: 0x220f, 0xd6: 0x221a, 0xd7: 0x22c5,
+	0xd8: 0x00ac, 0xd9: 0x2227, 0xda: 0x2228, 0xdb: 0x21d4, 0xdc: 0x21d0, 0xdd: 0x21d1,
+	0xde: 0x21d2, 0xdf: 0x21d3, 0xe0: 0x25ca, 0xe1: 0x2329, 0xe2: 0x00ae, 0xe3: 0x00a9,
+	0xe4: 0x2122, 0xe5: 0x2211, 0xe6: 0x239b, 0xe7: 0x239c, 0xe8: 0x239d, 0xe9: 0x23a1,
+	0xea: 0x23a2, 0xeb: 0x23a3, 0xec: 0x23a7, 0xed: 0x23a8, 0xee: 0x23a9, 0xef: 0x23aa,
+	0xf1: 0x232a, 0xf2: 0x222b, 0xf3: 0x2320, 0xf4: 0x23ae, 0xf5: 0x2321, 0xf6: 0x239e,
+	0xf7: 0x239f, 0xf8: 0x23a0, 0xf9: 0x23a4, 0xfa: 0x23a5, 0xfb: 0x23a6, 0xfc: 0x23ab,
+	0xfd: 0x23ac, 0xfe: 0x23ad,
+}
+
+var dingbatsEncoding = map[byte]rune{
+	0x20: 0x0020, 0x25: 0x260e, 0x2a: 0x261b, 0x2b: 0x261e, 0x48: 0x2605, 0x6c: 0x25cf,
+	0x6e: 0x25a0, 0x73: 0x25b2, 0x74: 0x25bc, 0x75: 0x25c6, 0x77: 0x25d7, 0xa8: 0x2663,
+	0xa9: 0x2666, 0xaa: 0x2665, 0xab: 0x2660, 0xd5: 0x2192, 0xd6: 0x2194, 0xd7: 0x2195,
+}
+
+var glyphNames = map[string]rune{
+	"space": 0x0020, "exclam": 0x0021, "quotedbl": 0x0022, "numbersign": 0x0023,
+	"dollar": 0x0024, "percent": 0x0025, "ampersand": 0x0026, "quotesingle": 0x0027,
+	"parenleft": 0x0028, "parenright": 0x0029, "asterisk": 0x002a, "plus": 0x002b,
+	"comma": 0x002c, "hyphen": 0x002d, "hyphenminus": 0x002d, "period": 0x002e,
+	"slash": 0x002f, "zero": 0x0030, "one": 0x0031, "two": 0x0032, "three": 0x0033,
+	"four": 0x0034, "five": 0x0035, "six": 0x0036, "seven": 0x0037, "eight": 0x0038,
+	"nine": 0x0039, "colon": 0x003a, "semicolon": 0x003b, "less": 0x003c, "equal": 0x003d,
+	"greater": 0x003e, "question": 0x003f, "at": 0x0040, "A": 0x0041, "B": 0x0042,
+	"C": 0x0043, "D": 0x0044, "E": 0x0045, "F": 0x0046, "G": 0x0047, "H": 0x0048,
+	"I": 0x0049, "J": 0x004a, "K": 0x004b, "L": 0x004c, "M": 0x004d, "N": 0x004e,
+	"O": 0x004f, "P": 0x0050, "Q": 0x0051, "R": 0x0052, "S": 0x0053, "T": 0x0054,
+	"U": 0x0055, "V": 0x0056, "W": 0x0057, "X": 0x0058, "Y": 0x0059, "Z": 0x005a,
+	"bracketleft": 0x005b, "backslash": 0x005c, "bracketright": 0x005d,
+	"asciicircum": 0x005e, "underscore": 0x005f, "grave": 0x0060, "a": 0x0061, "b": 0x0062,
+	"c": 0x0063, "d": 0x0064, "e": 0x0065, "f": 0x0066, "g": 0x0067, "h": 0x0068,
+	"i": 0x0069, "j": 0x006a, "k": 0x006b, "l": 0x006c, "m": 0x006d, "n": 0x006e,
+	"o": 0x006f, "p": 0x0070, "q": 0x0071, "r": 0x0072, "s": 0x0073, "t": 0x0074,
+	"u": 0x0075, "v": 0x0076, "w": 0x0077, "x": 0x0078, "y": 0x0079, "z": 0x007a,
+	"braceleft": 0x007b, "bar": 0x007c, "braceright": 0x007d, "asciitilde": 0x007e,
+	"nbspace": 0x00a0, "nonbreakingspace": 0x00a0, "exclamdown": 0x00a1, "cent": 0x00a2,
+	"sterling": 0x00a3, "currency": 0x00a4, "yen": 0x00a5, "brokenbar": 0x00a6,
+	"section": 0x00a7, "dieresis": 0x00a8, "copyright": 0x00a9, "ordfeminine": 0x00aa,
+	"guillemotleft": 0x00ab, "logicalnot": 0x00ac, "sfthyphen": 0x00ad,
+	"registered": 0x00ae, "macron": 0x00af, "degree": 0x00b0, "plusminus": 0x00b1,
+	"twosuperior": 0x00b2, "threesuperior": 0x00b3, "acute": 0x00b4, "mu": 0x00b5,
+	"paragraph": 0x00b6, "middot": 0x00b7, "periodcentered": 0x00b7, "cedilla": 0x00b8,
+	"onesuperior": 0x00b9, "ordmasculine": 0x00ba, "guillemotright": 0x00bb,
+	"onequarter": 0x00bc, "onehalf": 0x00bd, "threequarters": 0x00be,
+	"questiondown": 0x00bf, "Agrave": 0x00c0, "Aacute": 0x00c1, "Acircumflex": 0x00c2,
+	"Atilde": 0x00c3, "Adieresis": 0x00c4, "Aring": 0x00c5, "AE": 0x00c6,
+	"Ccedilla": 0x00c7, "Egrave": 0x00c8, "Eacute": 0x00c9, "Ecircumflex": 0x00ca,
+	"Edieresis": 0x00cb, "Igrave": 0x00cc, "Iacute": 0x00cd, "Icircumflex": 0x00ce,
+	"Idieresis": 0x00cf, "Eth": 0x00d0, "Ntilde": 0x00d1, "Ograve": 0x00d2,
+	"Oacute": 0x00d3, "Ocircumflex": 0x00d4, "Otilde": 0x00d5, "Odieresis": 0x00d6,
+	"multiply": 0x00d7, "Oslash": 0x00d8, "Ugrave": 0x00d9, "Uacute": 0x00da,
+	"Ucircumflex": 0x00db, "Udieresis": 0x00dc, "Yacute": 0x00dd, "Thorn": 0x00de,
+	"germandbls": 0x00df, "agrave": 0x00e0, "aacute": 0x00e1, "acircumflex": 0x00e2,
+	"atilde": 0x00e3, "adieresis": 0x00e4, "aring": 0x00e5, "ae": 0x00e6,
+	"ccedilla": 0x00e7, "egrave": 0x00e8, "eacute": 0x00e9, "ecircumflex": 0x00ea,
+	"edieresis": 0x00eb, "igrave": 0x00ec, "iacute": 0x00ed, "icircumflex": 0x00ee,
+	"idieresis": 0x00ef, "eth": 0x00f0, "ntilde": 0x00f1, "ograve": 0x00f2,
+	"oacute": 0x00f3, "ocircumflex": 0x00f4, "otilde": 0x00f5, "odieresis": 0x00f6,
+	"divide": 0x00f7, "oslash": 0x00f8, "ugrave": 0x00f9, "uacute": 0x00fa,
+	"ucircumflex": 0x00fb, "udieresis": 0x00fc, "yacute": 0x00fd, "thorn": 0x00fe,
+	"ydieresis": 0x00ff, "dotlessi": 0x0131, "Lslash": 0x0141, "lslash": 0x0142,
+	"OE": 0x0152, "oe": 0x0153, "Scaron": 0x0160, "scaron": 0x0161, "Ydieresis": 0x0178,
+	"Zcaron": 0x017d, "zcaron": 0x017e, "florin": 0x0192, "dotlessj": 0x0237,
+	"circumflex": 0x02c6, "caron": 0x02c7, "breve": 0x02d8, "dotaccent": 0x02d9,
+	"ring": 0x02da, "ogonek": 0x02db, "tilde": 0x02dc, "hungarumlaut": 0x02dd,
+	"Omega": 0x03a9, "pi": 0x03c0, "endash": 0x2013, "emdash": 0x2014, "quoteleft": 0x2018,
+	"quoteright": 0x2019, "quotesinglbase": 0x201a, "quotedblleft": 0x201c,
+	"quotedblright": 0x201d, "quotedblbase": 0x201e, "dagger": 0x2020, "daggerdbl": 0x2021,
+	"bullet": 0x2022, "ellipsis": 0x2026, "perthousand": 0x2030, "guilsinglleft": 0x2039,
+	"guilsinglright": 0x203a, "fraction": 0x2044, "Euro": 0x20ac, "trademark": 0x2122,
+	"arrowleft": 0x2190, "arrowup": 0x2191, "arrowright": 0x2192, "arrowdown": 0x2193,
+	"partialdiff": 0x2202, "Delta": 0x2206, "product": 0x220f, "summation": 0x2211,
+	"minus": 0x2212, "radical": 0x221a, "infinity": 0x221e, "integral": 0x222b,
+	"approxequal": 0x2248, "notequal": 0x2260, "lessequal": 0x2264, "greaterequal": 0x2265,
+	"lozenge": 0x25ca, "checkmark": 0x2713, "apple": 0xf8ff, "ff": 0xfb00, "fi": 0xfb01,
+	"fl": 0xfb02, "ffi": 0xfb03, "ffl": 0xfb04,
+}
+
+func getBaseEncoding(name string) map[uint32]rune {
+	codes := make(map[uint32]rune)
+	switch name {
+	case "WinAnsiEncoding":
+		for i := 0x20; i < 0x100; i++ {
+			if r := charmap.Windows1252.DecodeByte(byte(i)); r != 0xfffd {
+				codes[uint32(i)] = r
+			}
+		}
+	case "MacRomanEncoding":
+		for i := 0x20; i < 0x100; i++ {
+			if r := charmap.Macintosh.DecodeByte(byte(i)); r != 0xfffd {
+				codes[uint32(i)] = r
+			}
+		}
+	case "Symbol":
+		for c, r := range symbolEncoding {
+			codes[uint32(c)] = r
+		}
+	case "ZapfDingbats":
+		for i := 0x21; i < 0x100; i++ {
+			switch {
+			case i < 0x7f:
+				codes[uint32(i)] = 0x2700 + rune(i-0x20)
+			case i >= 0x80 && i <= 0x8d:
+				codes[uint32(i)] = 0x2768 + rune(i-0x80)
+			case i >= 0xa1 && i <= 0xa7:
+				codes[uint32(i)] = 0x2761 + rune(i-0xa1)
+			case i >= 0xac && i <= 0xb5:
+				codes[uint32(i)] = 0x2460 + rune(i-0xac)
+			case i >= 0xb6 && i <= 0xd4:
+				codes[uint32(i)] = 0x2776 + rune(i-0xb6)
+			case i >= 0xd8 && i <= 0xfe && i != 0xf0:
+				codes[uint32(i)] = 0x2798 + rune(i-0xd8)
+			}
+		}
+		for c, r := range dingbatsEncoding {
+			codes[uint32(c)] = r
+		}
+	default:
+		for i := 0x20; i < 0x7f; i++ {
+			codes[uint32(i)] = rune(i)
+		}
+		for c, n := range standardEncoding {
+			if r, ok := glyphRune(n); ok {
+				codes[uint32(c)] = r
+			}
+		}
+	}
+	return codes
+}
+
+func parseType1Encoding(buf []byte) map[uint32]rune {
+	names := parseType1Names(buf)
+	if len(names) == 0 {
+		return nil
+	}
+	codes := make(map[uint32]rune)
+	for code, name := range names {
+		if r, ok := glyphRune(name); ok {
+			codes[code] = r
+		}
+	}
+	return codes
+}
+
+func parseType1Names(buf []byte) map[uint32]string {
+	x := bytes.Index(buf, []byte("/Encoding"))
+	if x < 0 {
+		return nil
+	}
+	toks := tokenizeCMap(buf[x+len("/Encoding"):])
+	if len(toks) > 0 && toks[0] == "StandardEncoding" {
+		return nil
+	}
+	names := make(map[uint32]string)
+	for i := 0; i+3 < len(toks) && toks[i] != "def"; i++ {
+		if toks[i] != "dup" || toks[i+3] != "put" {
+			continue
+		}
+		code, err := strconv.ParseUint(toks[i+1], 10, 8)
+		if err != nil {
+			continue
+		}
+		names[uint32(code)] = strings.TrimPrefix(toks[i+2], "/")
+		i += 3
+	}
+	return names
+}
+
+func standardName(code byte) string {
+	if name, ok := standardEncoding[code]; ok {
+		return name
+	}
+	if code < 0x20 || code > 0x7e {
+		return ""
+	}
+	if list := glyphAliases[rune(code)]; len(list) > 0 {
+		return list[0]
+	}
+	return ""
+}
+
+var glyphAliases = makeGlyphAliases()
+
+func makeGlyphAliases() map[rune][]string {
+	aliases := make(map[rune][]string)
+	for name, r := range glyphNames {
+		aliases[r] = append(aliases[r], name)
+	}
+	for _, list := range aliases {
+		sort.Strings(list)
+	}
+	return aliases
+}
+
+func glyphRune(name string) (rune, bool) {
+	if x := strings.IndexByte(name, '.'); x > 0 {
+		name = name[:x]
+	}
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	var hex string
+	switch {
+	case strings.HasPrefix(name, "uni") && len(name) >= 7:
+		hex = name[3:7]
+	case strings.HasPrefix(name, "u") && len(name) >= 5 && len(name) <= 7:
+		hex = name[1:]
+	default:
+		return 0, false
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return rune(n), true
+}
@@ -0,0 +1,196 @@
+package pdf
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+type TextFormat int
+
+const (
+	FormatHTML TextFormat = iota
+	FormatJSON
+)
+
+func (d *Document) ExportText(w io.Writer, format TextFormat, pages ...int) error {
+	if len(pages) == 0 {
+		for i := 1; i <= int(d.GetCount()); i++ {
+			pages = append(pages, i)
+		}
+	}
+	switch format {
+	case FormatHTML:
+		return d.exportHTML(w, pages)
+	case FormatJSON:
+		return d.exportJSON(w, pages)
+	default:
+		return fmt.Errorf("text format %d %w", format, ErrUnsupported)
+	}
+}
+
+func (d *Document) exportJSON(w io.Writer, pages []int) error {
+	type run struct {
+		Page int        `json:"page"`
+		Box  [4]float64 `json:"bbox"`
+		Font string     `json:"font"`
+		Size float64    `json:"size"`
+		Text string     `json:"text"`
+	}
+	var (
+		ws = bufio.NewWriter(w)
+		e  = json.NewEncoder(ws)
+	)
+	for _, p := range pages {
+		runs, err := d.GetTextRuns(p)
+		if err != nil {
+			return err
+		}
+		for _, r := range runs {
+			x := run{
+				Page: r.Page,
+				Box:  r.Rect,
+				Font: r.Font,
+				Size: r.Size,
+				Text: r.Text,
+			}
+			if err := e.Encode(x); err != nil {
+				return err
+			}
+		}
+	}
+	return ws.Flush()
+}
+
+func (d *Document) exportHTML(w io.Writer, pages []int) error {
+	ws := bufio.NewWriter(w)
+	ws.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	if title := d.GetDocumentInfo().Title; title != "" {
+		fmt.Fprintf(ws, "<title>%s</title>\n", html.EscapeString(title))
+	}
+	ws.WriteString("</head>\n<body>\n")
+	for _, p := range pages {
+		runs, err := d.GetTextRuns(p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(ws, "<div class=\"page\" id=\"page-%d\">\n", p)
+		writeHTMLBlocks(ws, groupLines(runs), d.getPageLinks(p))
+		ws.WriteString("</div>\n")
+	}
+	ws.WriteString("</body>\n</html>\n")
+	return ws.Flush()
+}
+
+type htmlBlock struct {
+	tag   string
+	lines []string
+}
+
+func writeHTMLBlocks(w io.Writer, lines [][]TextRun, links []Link) {
+	var (
+		body = bodySize(lines)
+		curr htmlBlock
+		prev []TextRun
+	)
+	flush := func() {
+		if len(curr.lines) > 0 {
+			fmt.Fprintf(w, "<%s>%s</%s>\n", curr.tag, strings.Join(curr.lines, " "), curr.tag)
+		}
+		curr.lines = curr.lines[:0]
+	}
+	for _, line := range lines {
+		tag := headingTag(lineSize(line), body)
+		if tag != curr.tag || len(prev) == 0 || lineGap(prev, line) > 1.5*lineSize(prev) {
+			flush()
+			curr.tag = tag
+		}
+		curr.lines = append(curr.lines, lineHTML(line, links))
+		prev = line
+	}
+	flush()
+}
+
+func lineHTML(line []TextRun, links []Link) string {
+	var buf strings.Builder
+	for i, r := range line {
+		if i > 0 && needSpace(line[i-1], r) {
+			buf.WriteByte(space)
+		}
+		text := html.EscapeString(r.Text)
+		if link, ok := findLink(links, r.Rect); ok {
+			if href, ok := link.href(); ok {
+				text = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(href), text)
+			}
+		}
+		buf.WriteString(text)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func findLink(links []Link, rect Rect) (Link, bool) {
+	var (
+		x = (rect[0] + rect[2]) / 2
+		y = (rect[1] + rect[3]) / 2
+	)
+	for _, k := range links {
+		if k.Rect.Contains(x, y) {
+			return k, true
+		}
+	}
+	return Link{}, false
+}
+
+func headingTag(size, body float64) string {
+	switch {
+	case body == 0 || size < body*1.15:
+		return "p"
+	case size >= body*1.8:
+		return "h1"
+	case size >= body*1.4:
+		return "h2"
+	default:
+		return "h3"
+	}
+}
+
+func lineSize(line []TextRun) float64 {
+	var size float64
+	for _, r := range line {
+		size = math.Max(size, r.Size)
+	}
+	return size
+}
+
+func lineGap(prev, next []TextRun) float64 {
+	return prev[0].Rect[1] - next[0].Rect[1]
+}
+
+func bodySize(lines [][]TextRun) float64 {
+	var (
+		counts = make(map[float64]int)
+		sizes  []float64
+	)
+	for _, line := range lines {
+		for _, r := range line {
+			size := math.Round(r.Size*10) / 10
+			if _, ok := counts[size]; !ok {
+				sizes = append(sizes, size)
+			}
+			counts[size] += len(r.Text)
+		}
+	}
+	sort.Float64s(sizes)
+	var body float64
+	for _, s := range sizes {
+		if counts[s] > counts[body] {
+			body = s
+		}
+	}
+	return body
+}
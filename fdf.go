@@ -0,0 +1,241 @@
+package pdf
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+type FormFormat int
+
+const (
+	FormFDF FormFormat = iota
+	FormXFDF
+	FormJSON
+)
+
+func (f Field) Values() []string {
+	switch v := f.Value.(type) {
+	case NameValue, StringValue:
+		str, _ := toString(v)
+		return []string{str}
+	case []interface{}:
+		var list []string
+		for i := range v {
+			if str, ok := toString(v[i]); ok {
+				list = append(list, str)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+func (d *Document) ExportForm(w io.Writer, format FormFormat) error {
+	var fields []Field
+	for _, f := range d.GetFields() {
+		if f.Name != "" {
+			fields = append(fields, f)
+		}
+	}
+	switch format {
+	case FormFDF:
+		return exportFDF(w, fields)
+	case FormXFDF:
+		return exportXFDF(w, fields)
+	case FormJSON:
+		return exportFormJSON(w, fields)
+	default:
+		return fmt.Errorf("form format %d %w", format, ErrUnsupported)
+	}
+}
+
+type formNode struct {
+	name  string
+	field *Field
+	kids  []*formNode
+}
+
+func (n *formNode) child(name string) *formNode {
+	for _, k := range n.kids {
+		if k.name == name {
+			return k
+		}
+	}
+	k := &formNode{name: name}
+	n.kids = append(n.kids, k)
+	return k
+}
+
+func makeFormTree(fields []Field) *formNode {
+	var root formNode
+	for i := range fields {
+		node := &root
+		for _, part := range strings.Split(fields[i].Name, ".") {
+			node = node.child(part)
+		}
+		node.field = &fields[i]
+	}
+	return &root
+}
+
+func exportFDF(w io.Writer, fields []Field) error {
+	ws := bufio.NewWriter(w)
+	ws.WriteString("%FDF-1.2\n%\xe2\xe3\xcf\xd3\n1 0 obj\n<< /FDF << /Fields [")
+	for _, k := range makeFormTree(fields).kids {
+		writeFDFNode(ws, k)
+	}
+	ws.WriteString("] >> >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return ws.Flush()
+}
+
+func writeFDFNode(ws *bufio.Writer, n *formNode) {
+	ws.WriteString("\n<< /T ")
+	ws.WriteString(fdfString(n.name))
+	if n.field != nil {
+		if values := n.field.Values(); len(values) > 0 {
+			ws.WriteString(" /V ")
+			encode := fdfString
+			if n.field.Type == "Btn" {
+				encode = fdfName
+			}
+			if len(values) == 1 {
+				ws.WriteString(encode(values[0]))
+			} else {
+				ws.WriteString("[")
+				for i, v := range values {
+					if i > 0 {
+						ws.WriteString(" ")
+					}
+					ws.WriteString(encode(v))
+				}
+				ws.WriteString("]")
+			}
+		}
+	}
+	if len(n.kids) > 0 {
+		ws.WriteString(" /Kids [")
+		for _, k := range n.kids {
+			writeFDFNode(ws, k)
+		}
+		ws.WriteString("]")
+	}
+	ws.WriteString(" >>")
+}
+
+func fdfString(str string) string {
+	var buf strings.Builder
+	for _, r := range str {
+		if r >= 0x80 {
+			buf.Reset()
+			buf.WriteString("<FEFF")
+			for _, c := range utf16.Encode([]rune(str)) {
+				fmt.Fprintf(&buf, "%04X", c)
+			}
+			buf.WriteString(">")
+			return buf.String()
+		}
+	}
+	buf.WriteString("(")
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; c {
+		case '(', ')', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\r':
+			buf.WriteString("\\r")
+		case '\n':
+			buf.WriteString("\\n")
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func fdfName(str string) string {
+	var buf strings.Builder
+	buf.WriteByte('/')
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c < 0x21 || c > 0x7e || strings.IndexByte("()<>[]{}/%#", c) >= 0 {
+			fmt.Fprintf(&buf, "#%02X", c)
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
+
+func exportXFDF(w io.Writer, fields []Field) error {
+	type field struct {
+		Name   string   `xml:"name,attr"`
+		Values []string `xml:"value"`
+		Kids   []field  `xml:"field"`
+	}
+	type xfdf struct {
+		XMLName xml.Name `xml:"http://ns.adobe.com/xfdf/ xfdf"`
+		Space   string   `xml:"http://www.w3.org/XML/1998/namespace space,attr"`
+		Fields  []field  `xml:"fields>field"`
+	}
+	var convert func(n *formNode) field
+	convert = func(n *formNode) field {
+		f := field{Name: n.name}
+		if n.field != nil {
+			f.Values = n.field.Values()
+		}
+		for _, k := range n.kids {
+			f.Kids = append(f.Kids, convert(k))
+		}
+		return f
+	}
+	doc := xfdf{Space: "preserve"}
+	for _, k := range makeFormTree(fields).kids {
+		doc.Fields = append(doc.Fields, convert(k))
+	}
+	ws := bufio.NewWriter(w)
+	ws.WriteString(xml.Header)
+	e := xml.NewEncoder(ws)
+	e.Indent("", "  ")
+	if err := e.Encode(doc); err != nil {
+		return err
+	}
+	ws.WriteString("\n")
+	return ws.Flush()
+}
+
+func exportFormJSON(w io.Writer, fields []Field) error {
+	type field struct {
+		Name  string      `json:"name"`
+		Type  string      `json:"type"`
+		Page  int         `json:"page"`
+		Value interface{} `json:"value"`
+	}
+	var (
+		ws = bufio.NewWriter(w)
+		e  = json.NewEncoder(ws)
+	)
+	for _, f := range fields {
+		x := field{
+			Name: f.Name,
+			Type: f.Type,
+			Page: f.Page,
+		}
+		switch values := f.Values(); {
+		case len(values) == 1:
+			x.Value = values[0]
+		case len(values) > 1:
+			x.Value = values
+		}
+		if err := e.Encode(x); err != nil {
+			return err
+		}
+	}
+	return ws.Flush()
+}
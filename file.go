@@ -0,0 +1,135 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	blockSize  = 32 << 10
+	blockCount = 64
+)
+
+type block struct {
+	buf  []byte
+	used int64
+}
+
+// BlockReader reads an io.ReaderAt on demand by blocks of blockSize bytes.
+// The last blocks read are kept in memory so that the many small reads done
+// when parsing objects do not each cost a system call or a network request.
+type BlockReader struct {
+	mu     sync.Mutex
+	src    io.ReaderAt
+	size   int64
+	clock  int64
+	blocks map[int64]*block
+}
+
+func NewBlockReader(r io.ReaderAt, size int64) *BlockReader {
+	return &BlockReader{
+		src:    r,
+		size:   size,
+		blocks: make(map[int64]*block),
+	}
+}
+
+func openFile(file string) (*BlockReader, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	s, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return NewBlockReader(f, s.Size()), nil
+}
+
+func (r *BlockReader) Size() int64 {
+	return r.size
+}
+
+func (r *BlockReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blocks = nil
+	if c, ok := r.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (r *BlockReader) ReadAt(b []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("readat: negative offset")
+	}
+	if offset >= r.size {
+		return 0, io.EOF
+	}
+	if len(b) >= blockSize*blockCount/2 {
+		return r.src.ReadAt(b, offset)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int
+	for n < len(b) && offset < r.size {
+		buf, err := r.readBlock(offset / blockSize)
+		if err != nil {
+			return n, err
+		}
+		at := int(offset % blockSize)
+		if at >= len(buf) {
+			break
+		}
+		c := copy(b[n:], buf[at:])
+		n += c
+		offset += int64(c)
+	}
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *BlockReader) readBlock(index int64) ([]byte, error) {
+	if r.blocks == nil {
+		return nil, fmt.Errorf("read block: reader closed")
+	}
+	r.clock++
+	if b, ok := r.blocks[index]; ok {
+		b.used = r.clock
+		return b.buf, nil
+	}
+	if len(r.blocks) >= blockCount {
+		r.evictBlock()
+	}
+	buf := make([]byte, blockSize)
+	n, err := r.src.ReadAt(buf, index*blockSize)
+	if err != nil && !(errors.Is(err, io.EOF) && n > 0) {
+		return nil, err
+	}
+	r.blocks[index] = &block{
+		buf:  buf[:n],
+		used: r.clock,
+	}
+	return buf[:n], nil
+}
+
+func (r *BlockReader) evictBlock() {
+	var (
+		index int64 = -1
+		used  int64
+	)
+	for i, b := range r.blocks {
+		if index < 0 || b.used < used {
+			index, used = i, b.used
+		}
+	}
+	delete(r.blocks, index)
+}
@@ -0,0 +1,141 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/ascii85"
+	"fmt"
+	"io"
+)
+
+const (
+	filterASCIIHex = "ASCIIHexDecode"
+	filterASCII85  = "ASCII85Decode"
+	filterFlate    = "FlateDecode"
+)
+
+func (d Dict) getFilters() []string {
+	if str := d.GetString("filter"); str != "" {
+		return []string{str}
+	}
+	return d.GetStringArray("filter")
+}
+
+func (d Dict) getDecodeParms() []Dict {
+	if parms, ok := d.getValue("decodeparms").(Dict); ok {
+		return []Dict{parms}
+	}
+	var list []Dict
+	for _, v := range d.GetArray("decodeparms") {
+		parms, _ := v.(Dict)
+		list = append(list, parms)
+	}
+	return list
+}
+
+func isTextFilter(name string) bool {
+	switch name {
+	case filterASCIIHex, "AHx", filterASCII85, "A85":
+		return true
+	default:
+		return false
+	}
+}
+
+func textDecoder(name string, r io.Reader) io.Reader {
+	switch name {
+	case filterASCIIHex, "AHx":
+		return &hexReader{inner: bufio.NewReader(r)}
+	case filterASCII85, "A85":
+		return newA85Reader(r)
+	default:
+		return r
+	}
+}
+
+// hexReader decodes an ASCIIHexDecode stream as it is read.
+type hexReader struct {
+	inner *bufio.Reader
+	curr  byte
+	half  bool
+	err   error
+}
+
+func (h *hexReader) Read(b []byte) (int, error) {
+	var n int
+	for n < len(b) && h.err == nil {
+		c, err := h.inner.ReadByte()
+		if err == nil && c == rangle {
+			err = io.EOF
+		}
+		if err != nil {
+			if h.half {
+				b[n] = h.curr
+				n++
+				h.half = false
+			}
+			h.err = err
+			break
+		}
+		if isBlank(c) || c == formfeed || c == 0 {
+			continue
+		}
+		v, ok := fromHexChar(c)
+		if !ok {
+			h.err = fmt.Errorf("%s: invalid character %q", filterASCIIHex, c)
+			break
+		}
+		if h.half {
+			b[n] = h.curr | v
+			n++
+		} else {
+			h.curr = v << 4
+		}
+		h.half = !h.half
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, h.err
+}
+
+// newA85Reader skips the optional <~ prefix and stops at the ~> end marker.
+func newA85Reader(r io.Reader) io.Reader {
+	rs := bufio.NewReader(r)
+	for {
+		c, err := rs.ReadByte()
+		if err != nil {
+			break
+		}
+		if !isBlank(c) && c != formfeed {
+			rs.UnreadByte()
+			break
+		}
+	}
+	if prefix, _ := rs.Peek(2); bytes.Equal(prefix, []byte("<~")) {
+		rs.Discard(len(prefix))
+	}
+	return ascii85.NewDecoder(&delimReader{
+		inner: rs,
+		delim: '~',
+	})
+}
+
+// delimReader reads its inner reader until the delimiter is found.
+type delimReader struct {
+	inner io.Reader
+	delim byte
+	done  bool
+}
+
+func (r *delimReader) Read(b []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	n, err := r.inner.Read(b)
+	if x := bytes.IndexByte(b[:n], r.delim); x >= 0 {
+		n, err = x, nil
+		r.done = true
+	}
+	return n, err
+}
@@ -0,0 +1,69 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+)
+
+type Match struct {
+	Page  int
+	Start int
+	End   int
+	Text  string
+	Rect  Rect
+}
+
+func (d *Document) Find(pattern string) []Match {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = regexp.MustCompile(regexp.QuoteMeta(pattern))
+	}
+	var list []Match
+	for i := 1; i <= int(d.GetCount()); i++ {
+		ms, err := d.findInPage(i, re)
+		if err != nil {
+			continue
+		}
+		list = append(list, ms...)
+	}
+	return list
+}
+
+func (d *Document) findInPage(page int, re *regexp.Regexp) ([]Match, error) {
+	var (
+		w bytes.Buffer
+		t = newTextState(nil)
+	)
+	t.glyphs = []textGlyph{}
+	if err := d.runText(page, t, &w); err != nil {
+		return nil, err
+	}
+	var (
+		text = w.String()
+		list []Match
+	)
+	for _, ix := range re.FindAllStringIndex(text, -1) {
+		if ix[0] == ix[1] {
+			continue
+		}
+		m := Match{
+			Page:  page,
+			Start: ix[0],
+			End:   ix[1],
+			Text:  text[ix[0]:ix[1]],
+		}
+		first := true
+		for _, g := range t.glyphs {
+			if g.offset+g.size <= ix[0] || g.offset >= ix[1] {
+				continue
+			}
+			if first {
+				m.Rect, first = g.box, false
+			} else {
+				m.Rect = m.Rect.union(g.box)
+			}
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
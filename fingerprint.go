@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+type Fingerprint struct {
+	ID        [2][]byte
+	Content   []byte
+	Revisions [][]byte
+}
+
+func (d *Document) Fingerprint() (Fingerprint, error) {
+	var (
+		fp  Fingerprint
+		err error
+	)
+	fp.ID = d.ID()
+	if fp.Content, err = d.contentHash(); err != nil {
+		return fp, err
+	}
+	fp.Revisions, err = d.revisionHashes()
+	return fp, err
+}
+
+func (d *Document) contentHash() ([]byte, error) {
+	var (
+		sum  = sha256.New()
+		seen = make(map[string]bool)
+	)
+	for _, x := range d.sortedXRef() {
+		if seen[x.Oid] || d.isStructural(x.Oid) {
+			continue
+		}
+		seen[x.Oid] = true
+		raw, err := d.readRawObject(x)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", x.Oid, err)
+		}
+		if raw.Skip {
+			continue
+		}
+		fmt.Fprintf(sum, "%s\n", x.Oid)
+		sum.Write(normalizeValue(raw.Value))
+		sum.Write([]byte{nl})
+		sum.Write(raw.Stream)
+	}
+	return sum.Sum(nil), nil
+}
+
+func (d *Document) isStructural(oid string) bool {
+	if d.linear == nil {
+		return false
+	}
+	if oid == d.linear.Oid {
+		return true
+	}
+	if len(d.linear.Hint) == 0 {
+		return false
+	}
+	p, ok := d.lookupOid(oid)
+	return ok && !p.isEmbed() && p.Offset == d.linear.Hint[0]
+}
+
+func (d *Document) revisionHashes() ([][]byte, error) {
+	var (
+		sum  = sha256.New()
+		list [][]byte
+		ends = d.revisionOffsets()
+		prev int64
+	)
+	for _, end := range ends {
+		if _, err := io.Copy(sum, io.NewSectionReader(d.src, prev, end-prev)); err != nil {
+			return nil, err
+		}
+		list = append(list, sum.Sum(nil))
+		prev = end
+	}
+	return list, nil
+}
+
+func (d *Document) revisionOffsets() []int64 {
+	var (
+		list   []int64
+		offset int64
+		chunk  = 64 << 10
+		skip   = d.linear != nil
+	)
+	for offset < d.size {
+		r, err := d.section(offset, int64(chunk+len(eof)+2))
+		if err != nil {
+			break
+		}
+		buf := r.Bytes()
+		for i := 0; i < len(buf) && i < chunk; {
+			x := bytes.Index(buf[i:], eof)
+			if x < 0 || i+x >= chunk {
+				break
+			}
+			i += x + len(eof)
+			end := i
+			if end < len(buf) && buf[end] == cr {
+				end++
+			}
+			if end < len(buf) && buf[end] == nl {
+				end++
+			}
+			if skip {
+				skip = false
+				continue
+			}
+			list = append(list, offset+int64(end))
+		}
+		offset += int64(chunk)
+	}
+	return list
+}
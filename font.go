@@ -0,0 +1,189 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+func (f Font) ToUnicodeMap() map[uint32]rune {
+	codes := make(map[uint32]rune, len(f.codes))
+	for c, r := range f.codes {
+		codes[c] = r
+	}
+	return codes
+}
+
+func (d *Document) getToUnicode(obj Object) *cmap {
+	str := obj.GetString("tounicode")
+	if !isOid(str) {
+		return nil
+	}
+	tu := d.getObjectWithOid(str, true)
+	body, err := tu.Body()
+	if err != nil {
+		return nil
+	}
+	if cm := parseCMap(body); len(cm.codes) > 0 {
+		return cm
+	}
+	return nil
+}
+
+func (d *Document) getFontCodes(obj Object) map[uint32]rune {
+	if str := obj.GetString("tounicode"); str != "" {
+		tu := d.getObjectWithOid(str, true)
+		if body, err := tu.Body(); err == nil {
+			if codes := parseToUnicode(body); len(codes) > 0 {
+				return codes
+			}
+		}
+	}
+	if obj.Subtype() == "Type0" {
+		return d.getCIDFontCodes(obj)
+	}
+	return d.getEncodingCodes(obj)
+}
+
+func (d *Document) getEncodingCodes(obj Object) map[uint32]rune {
+	var (
+		base  string
+		diffs []interface{}
+	)
+	switch enc := d.resolve(obj.getValue("encoding")).(type) {
+	case NameValue:
+		base = string(enc)
+	case Dict:
+		base = enc.GetString("baseencoding")
+		diffs, _ = d.resolve(enc.getValue("differences")).([]interface{})
+	}
+	codes := make(map[uint32]rune)
+	switch {
+	case base != "":
+		codes = getBaseEncoding(base)
+	case obj.Subtype() == "TrueType":
+		codes = getBaseEncoding("WinAnsiEncoding")
+	case obj.Subtype() != "Type3":
+		codes = d.getBuiltinEncoding(obj)
+	}
+	var code uint32
+	for _, v := range diffs {
+		switch v := v.(type) {
+		case int64:
+			code = uint32(v)
+		case NameValue:
+			if r, ok := glyphRune(string(v)); ok {
+				codes[code] = r
+			}
+			code++
+		}
+	}
+	return codes
+}
+
+func (d *Document) getEncodingCMap(obj Object) *cmap {
+	switch enc := obj.GetString("encoding"); {
+	case enc == "Identity-H" || enc == "Identity-V":
+		return identityCMap()
+	case isOid(enc):
+		stream := d.getObjectWithOid(enc, true)
+		body, err := stream.Body()
+		if err != nil {
+			return nil
+		}
+		cm := parseCMap(body)
+		if name := stream.GetString("usecmap"); name != "" && !isOid(name) {
+			cm.usecmap = name
+		}
+		if cm.usecmap != "" {
+			cm.inherit(d.getPredefinedCMap(cm.usecmap))
+		}
+		if len(cm.ranges) > 0 {
+			return cm
+		}
+	case enc != "":
+		return d.getPredefinedCMap(enc)
+	}
+	return nil
+}
+
+func (d *Document) getBuiltinEncoding(obj Object) map[uint32]rune {
+	name := obj.GetString("basefont")
+	if x := strings.IndexByte(name, '+'); x >= 0 {
+		name = name[x+1:]
+	}
+	if x := strings.IndexByte(name, ','); x >= 0 {
+		name = name[:x]
+	}
+	switch name {
+	case "Symbol", "ZapfDingbats":
+		return getBaseEncoding(name)
+	}
+	desc := d.getObjectWithOid(obj.GetString("fontdescriptor"), false)
+	file := d.getObjectWithOid(desc.GetString("fontfile"), true)
+	if file.isZero() {
+		return getBaseEncoding("")
+	}
+	body, err := file.Body()
+	if err != nil {
+		return getBaseEncoding("")
+	}
+	if n := file.GetInt("length1"); n > 0 && int(n) < len(body) {
+		body = body[:n]
+	}
+	if codes := parseType1Encoding(body); len(codes) > 0 {
+		return codes
+	}
+	return getBaseEncoding("")
+}
+
+func (d *Document) getCIDFontCodes(obj Object) map[uint32]rune {
+	enc := d.getEncodingCMap(obj)
+	if enc == nil || (enc.cids == nil && enc.text != nil) {
+		return nil
+	}
+	kids, _ := d.resolve(obj.getValue("descendantfonts")).([]interface{})
+	if len(kids) == 0 {
+		return nil
+	}
+	cid, _ := d.resolve(kids[0]).(Dict)
+	desc, _ := d.resolve(cid.getValue("fontdescriptor")).(Dict)
+	if desc == nil {
+		return nil
+	}
+	file := d.getObjectWithOid(desc.GetString("fontfile2"), true)
+	body, err := file.Body()
+	if err != nil {
+		return nil
+	}
+	glyphs := parseTrueTypeCmap(body)
+	if len(glyphs) == 0 {
+		return nil
+	}
+	var cidgid []byte
+	if str := cid.GetString("cidtogidmap"); isOid(str) {
+		m := d.getObjectWithOid(str, true)
+		cidgid, _ = m.Body()
+	}
+	cids := make(map[uint32]rune)
+	if cidgid == nil {
+		for gid, r := range glyphs {
+			cids[uint32(gid)] = r
+		}
+	}
+	for i := 0; i+1 < len(cidgid); i += 2 {
+		gid := binary.BigEndian.Uint16(cidgid[i:])
+		if r, ok := glyphs[gid]; ok {
+			cids[uint32(i/2)] = r
+		}
+	}
+	if enc.cids == nil {
+		return cids
+	}
+	codes := make(map[uint32]rune)
+	for code, cid := range enc.cids {
+		if r, ok := cids[cid]; ok {
+			codes[code] = r
+		}
+	}
+	return codes
+}
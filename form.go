@@ -0,0 +1,265 @@
+package pdf
+
+import "sort"
+
+const (
+	TabRow       = "R"
+	TabColumn    = "C"
+	TabStructure = "S"
+)
+
+type Field struct {
+	Oid   string
+	Name  string
+	Type  string
+	Flags int64
+	Value Value
+	Page  int
+	Rect  [4]float64
+
+	widgets []string
+}
+
+func (d *Document) GetFields() []Field {
+	form := d.getAcroForm()
+	if form == nil {
+		return nil
+	}
+	var (
+		list  []Field
+		pages = d.getPageNumbers()
+		seen  = make(map[string]bool)
+	)
+	for _, oid := range form.GetStringArray("fields") {
+		list = d.appendFields(list, oid, Field{}, pages, seen)
+	}
+	return list
+}
+
+func (d *Document) GetPageTabs(page int) string {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return ""
+	}
+	return d.resolveString(obj.getValue("tabs"))
+}
+
+func (d *Document) GetTabOrder(page int) []Field {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return nil
+	}
+	var (
+		fields  = d.GetFields()
+		widgets = make(map[string]Field)
+		list    []Field
+	)
+	for _, f := range fields {
+		for _, w := range f.widgets {
+			widgets[w] = f
+		}
+	}
+	annots, _ := d.resolve(obj.getValue("annots")).([]interface{})
+	for _, a := range annots {
+		oid, _ := toOid(a)
+		f, ok := widgets[oid]
+		if !ok {
+			continue
+		}
+		w := d.getObjectWithOid(oid, false)
+		f.Rect = getRect(d.resolve(w.getValue("rect")))
+		list = append(list, f)
+	}
+	switch d.GetPageTabs(page) {
+	case TabRow:
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Rect[3] == list[j].Rect[3] {
+				return list[i].Rect[0] < list[j].Rect[0]
+			}
+			return list[i].Rect[3] > list[j].Rect[3]
+		})
+	case TabColumn:
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Rect[0] == list[j].Rect[0] {
+				return list[i].Rect[3] > list[j].Rect[3]
+			}
+			return list[i].Rect[0] < list[j].Rect[0]
+		})
+	case TabStructure:
+		order := d.getStructOrder(obj.Oid)
+		sort.SliceStable(list, func(i, j int) bool {
+			return structIndex(order, list[i]) < structIndex(order, list[j])
+		})
+	}
+	return list
+}
+
+func (d *Document) GetCalculationOrder() []Field {
+	form := d.getAcroForm()
+	if form == nil {
+		return nil
+	}
+	var (
+		fields = make(map[string]Field)
+		list   []Field
+	)
+	for _, f := range d.GetFields() {
+		fields[f.Oid] = f
+		for _, w := range f.widgets {
+			fields[w] = f
+		}
+	}
+	co, _ := d.resolve(form.getValue("co")).([]interface{})
+	for _, v := range co {
+		oid, _ := toOid(v)
+		if f, ok := fields[oid]; ok {
+			list = append(list, f)
+		}
+	}
+	return list
+}
+
+func (d *Document) getAcroForm() Dict {
+	root := d.getCatalog()
+	if root.isZero() {
+		return nil
+	}
+	form, _ := d.resolve(root.getValue("acroform")).(Dict)
+	return form
+}
+
+func (d *Document) appendFields(list []Field, oid string, parent Field, pages map[string]int, seen map[string]bool) []Field {
+	if seen[oid] {
+		return list
+	}
+	seen[oid] = true
+	obj := d.getObjectWithOid(oid, false)
+	if obj.isZero() {
+		return list
+	}
+	field := parent
+	field.Oid = oid
+	field.widgets = nil
+	if name := d.resolveString(obj.getValue("t")); name != "" {
+		if field.Name != "" {
+			field.Name += "."
+		}
+		field.Name += name
+	}
+	if ft := d.resolveString(obj.getValue("ft")); ft != "" {
+		field.Type = ft
+	}
+	if obj.Has("ff") {
+		field.Flags, _ = d.resolve(obj.getValue("ff")).(int64)
+	}
+	if obj.Has("v") {
+		field.Value = d.resolve(obj.getValue("v"))
+	}
+	var kids []string
+	for _, k := range obj.GetStringArray("kids") {
+		kid := d.getObjectWithOid(k, false)
+		if kid.isZero() {
+			continue
+		}
+		if !kid.Has("t") && kid.Subtype() == "Widget" {
+			field.widgets = append(field.widgets, k)
+			continue
+		}
+		kids = append(kids, k)
+	}
+	if obj.Subtype() == "Widget" {
+		field.widgets = append(field.widgets, oid)
+	}
+	if len(field.widgets) > 0 {
+		w := d.getObjectWithOid(field.widgets[0], false)
+		field.Page = pages[w.GetString("p")]
+		field.Rect = getRect(d.resolve(w.getValue("rect")))
+		list = append(list, field)
+	}
+	for _, k := range kids {
+		list = d.appendFields(list, k, field, pages, seen)
+	}
+	return list
+}
+
+func (d *Document) getStructOrder(page string) map[string]int {
+	var (
+		order = make(map[string]int)
+		root  = d.getCatalog()
+	)
+	if root.isZero() {
+		return order
+	}
+	tree, _ := d.resolve(root.getValue("structtreeroot")).(Dict)
+	if tree == nil {
+		return order
+	}
+	var (
+		seen = make(map[string]bool)
+		walk func(Value)
+	)
+	walk = func(v Value) {
+		if oid, ok := toOid(v); ok {
+			if seen[oid] {
+				return
+			}
+			seen[oid] = true
+		}
+		switch v := d.resolve(v).(type) {
+		case []interface{}:
+			for _, k := range v {
+				walk(k)
+			}
+		case Dict:
+			if v.GetString("type") == "OBJR" {
+				if pg := v.GetString("pg"); pg == "" || pg == page {
+					if _, ok := order[v.GetString("obj")]; !ok {
+						order[v.GetString("obj")] = len(order)
+					}
+				}
+				return
+			}
+			walk(v.getValue("k"))
+		}
+	}
+	walk(tree.getValue("k"))
+	return order
+}
+
+func structIndex(order map[string]int, f Field) int {
+	for _, w := range f.widgets {
+		if i, ok := order[w]; ok {
+			return i
+		}
+	}
+	return len(order)
+}
+
+func getRect(v Value) [4]float64 {
+	var rect [4]float64
+	arr, _ := v.([]interface{})
+	if len(arr) != 4 {
+		return rect
+	}
+	for i := range arr {
+		rect[i] = getNumber(arr[i])
+	}
+	if rect[0] > rect[2] {
+		rect[0], rect[2] = rect[2], rect[0]
+	}
+	if rect[1] > rect[3] {
+		rect[1], rect[3] = rect[3], rect[1]
+	}
+	return rect
+}
+
+func getNumber(v Value) float64 {
+	switch v := v.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
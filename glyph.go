@@ -0,0 +1,200 @@
+package pdf
+
+import (
+	"encoding/binary"
+)
+
+type glyphSet struct {
+	matrix  [6]float64
+	outline func(code uint32) []PathOp
+	cache   map[uint32][]PathOp
+}
+
+func (g *glyphSet) get(code uint32) []PathOp {
+	if list, ok := g.cache[code]; ok {
+		return list
+	}
+	list := g.outline(code)
+	g.cache[code] = list
+	return list
+}
+
+func (d *Document) getGlyphSet(f *textFont) *glyphSet {
+	obj := d.getObjectWithOid(f.oid, false)
+	if obj.isZero() {
+		return nil
+	}
+	var g *glyphSet
+	switch obj.Subtype() {
+	case "Type3":
+		g = d.getType3Glyphs(obj)
+	case "Type0":
+		g = d.getCIDGlyphs(obj, f)
+	case "TrueType":
+		g = d.getTrueTypeGlyphs(obj, f)
+	default:
+		g = d.getType1Glyphs(obj, f)
+	}
+	if g != nil {
+		g.cache = make(map[uint32][]PathOp)
+	}
+	return g
+}
+
+func (d *Document) getFontFile(desc Dict, key string) []byte {
+	file := d.getObjectWithOid(desc.GetString(key), true)
+	if file.isZero() {
+		return nil
+	}
+	body, err := file.Body()
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+func (d *Document) getType3Glyphs(obj Object) *glyphSet {
+	var (
+		procs, _ = d.resolve(obj.getValue("charprocs")).(Dict)
+		names    = d.getDifferences(obj)
+	)
+	outline := func(code uint32) []PathOp {
+		proc := d.getObjectWithOid(procs.GetString(names[code]), true)
+		if proc.isZero() {
+			return nil
+		}
+		body, err := proc.Body()
+		if err != nil {
+			return nil
+		}
+		return parsePath(body)
+	}
+	return &glyphSet{
+		matrix:  d.getFontMatrix(obj),
+		outline: outline,
+	}
+}
+
+func (d *Document) getType1Glyphs(obj Object, f *textFont) *glyphSet {
+	var (
+		desc, _ = d.resolve(obj.getValue("fontdescriptor")).(Dict)
+		body    = d.getFontFile(desc, "fontfile")
+		font    = parseType1(body)
+	)
+	if font == nil {
+		return nil
+	}
+	var (
+		diffs   = d.getDifferences(obj)
+		builtin map[uint32]string
+	)
+	switch enc := d.resolve(obj.getValue("encoding")).(type) {
+	case NameValue:
+	case Dict:
+		if enc.GetString("baseencoding") == "" {
+			builtin = parseType1Names(body)
+		}
+	default:
+		builtin = parseType1Names(body)
+	}
+	outline := func(code uint32) []PathOp {
+		if name, ok := diffs[code]; ok {
+			return font.outline(name)
+		}
+		if name, ok := builtin[code]; ok {
+			return font.outline(name)
+		}
+		if r, ok := f.codes[code]; ok {
+			for _, name := range glyphAliases[r] {
+				if _, ok := font.glyphs[name]; ok {
+					return font.outline(name)
+				}
+			}
+		}
+		if code < 0x100 {
+			return font.outline(standardName(byte(code)))
+		}
+		return nil
+	}
+	return &glyphSet{
+		matrix:  font.matrix,
+		outline: outline,
+	}
+}
+
+func (d *Document) getTrueTypeGlyphs(obj Object, f *textFont) *glyphSet {
+	var (
+		desc, _ = d.resolve(obj.getValue("fontdescriptor")).(Dict)
+		font    = parseTrueType(d.getFontFile(desc, "fontfile2"))
+	)
+	if font == nil {
+		return nil
+	}
+	outline := func(code uint32) []PathOp {
+		if r, ok := f.codes[code]; ok {
+			if gid, ok := font.lookup(3, 1, uint32(r)); ok {
+				return font.outline(gid)
+			}
+			if gid, ok := font.lookup(0, 3, uint32(r)); ok {
+				return font.outline(gid)
+			}
+		}
+		for _, c := range []uint32{code, 0xf000 | code, 0xf100 | code, 0xf200 | code} {
+			if gid, ok := font.lookup(3, 0, c); ok {
+				return font.outline(gid)
+			}
+		}
+		if gid, ok := font.lookup(1, 0, code); ok {
+			return font.outline(gid)
+		}
+		if sfntTable(font.buf, "cmap") == nil && code <= 0xffff {
+			return font.outline(uint16(code))
+		}
+		return nil
+	}
+	return &glyphSet{
+		matrix:  font.matrix(),
+		outline: outline,
+	}
+}
+
+func (d *Document) getCIDGlyphs(obj Object, f *textFont) *glyphSet {
+	kids, _ := d.resolve(obj.getValue("descendantfonts")).([]interface{})
+	if len(kids) == 0 {
+		return nil
+	}
+	var (
+		cid, _  = d.resolve(kids[0]).(Dict)
+		desc, _ = d.resolve(cid.getValue("fontdescriptor")).(Dict)
+		font    = parseTrueType(d.getFontFile(desc, "fontfile2"))
+		cidgid  []byte
+	)
+	if font == nil {
+		return nil
+	}
+	if str := cid.GetString("cidtogidmap"); isOid(str) {
+		cidgid, _ = d.getObjectWithOid(str, true).Body()
+	}
+	outline := func(code uint32) []PathOp {
+		gid := code
+		if f.enc != nil {
+			if c, ok := f.enc.cid(code); ok {
+				gid = c
+			}
+		}
+		if cidgid != nil {
+			if int(2*gid+1) >= len(cidgid) {
+				return nil
+			}
+			gid = uint32(binary.BigEndian.Uint16(cidgid[2*gid:]))
+		}
+		if gid > 0xffff {
+			return nil
+		}
+		return font.outline(uint16(gid))
+	}
+	return &glyphSet{
+		matrix:  font.matrix(),
+		outline: outline,
+	}
+}
@@ -0,0 +1,134 @@
+package pdf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Graph is the graph of the references between the objects of a document.
+type Graph struct {
+	// Roots are the objects referenced by the trailer.
+	Roots []string
+	// Nodes are ordered by number, xref and object streams left out.
+	Nodes []string
+	Types map[string]string
+	Edges map[string][]string
+	// Orphans are the nodes that can not be reached from the roots.
+	Orphans []string
+	// Dangling are references to missing objects, From is empty for the trailer.
+	Dangling []Edge
+}
+
+type Edge struct {
+	From string
+	To   string
+}
+
+func (d *Document) Graph() Graph {
+	g := Graph{
+		Types: make(map[string]string),
+		Edges: make(map[string][]string),
+	}
+	for _, oid := range []string{d.catalog, d.info, d.encrypt} {
+		if oid != "" {
+			g.Roots = append(g.Roots, oid)
+		}
+	}
+	for _, p := range d.sortedXRef() {
+		obj := d.getObjectWithOid(p.Oid, false)
+		if obj.isZero() || obj.isType("XRef") || obj.isType("ObjStm") || d.isStructural(p.Oid) {
+			continue
+		}
+		var refs []string
+		if obj.Dict != nil {
+			refs = collectRefs(obj.Dict, refs)
+		} else {
+			refs = collectRefs(obj.Data, refs)
+		}
+		g.Nodes = append(g.Nodes, p.Oid)
+		g.Types[p.Oid] = obj.GetString("type")
+		g.Edges[p.Oid] = uniqueRefs(refs)
+	}
+	seen := make(map[string]bool)
+	for _, oid := range g.Roots {
+		if _, ok := d.lookupOid(oid); !ok {
+			g.Dangling = append(g.Dangling, Edge{To: oid})
+		}
+	}
+	for todo := g.Roots; len(todo) > 0; {
+		oid := todo[0]
+		todo = todo[1:]
+		if seen[oid] {
+			continue
+		}
+		seen[oid] = true
+		todo = append(todo, g.Edges[oid]...)
+	}
+	for _, oid := range g.Nodes {
+		if !seen[oid] {
+			g.Orphans = append(g.Orphans, oid)
+		}
+		for _, ref := range g.Edges[oid] {
+			if _, ok := d.lookupOid(ref); !ok {
+				g.Dangling = append(g.Dangling, Edge{From: oid, To: ref})
+			}
+		}
+	}
+	return g
+}
+
+// WriteDOT writes the graph in the dot language of Graphviz.
+func (g Graph) WriteDOT(w io.Writer) error {
+	var (
+		ws      = bufio.NewWriter(w)
+		orphans = make(map[string]bool)
+		label   = func(oid string) string {
+			return strings.Replace(oid, "/", " ", 1)
+		}
+	)
+	for _, oid := range g.Orphans {
+		orphans[oid] = true
+	}
+	ws.WriteString("digraph pdf {\n")
+	for _, oid := range g.Nodes {
+		attrs := fmt.Sprintf("label=%q", label(oid))
+		if typ := g.Types[oid]; typ != "" {
+			attrs = fmt.Sprintf("label=%q", label(oid)+"\n"+typ)
+		}
+		if orphans[oid] {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(ws, "\t%q [%s];\n", label(oid), attrs)
+	}
+	missing := make(map[string]bool)
+	for _, e := range g.Dangling {
+		if missing[e.To] {
+			continue
+		}
+		missing[e.To] = true
+		fmt.Fprintf(ws, "\t%q [color=red];\n", label(e.To))
+	}
+	for _, oid := range g.Nodes {
+		for _, ref := range g.Edges[oid] {
+			fmt.Fprintf(ws, "\t%q -> %q;\n", label(oid), label(ref))
+		}
+	}
+	ws.WriteString("}\n")
+	return ws.Flush()
+}
+
+func uniqueRefs(refs []string) []string {
+	var (
+		list []string
+		seen = make(map[string]bool)
+	)
+	for _, oid := range refs {
+		if !seen[oid] {
+			seen[oid] = true
+			list = append(list, oid)
+		}
+	}
+	return list
+}
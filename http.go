@@ -0,0 +1,88 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrRange = errors.New("range requests not supported")
+
+type HTTPReader struct {
+	client *http.Client
+	url    string
+	size   int64
+}
+
+func OpenURL(url string, opts ...Option) (*Document, error) {
+	r, err := NewHTTPReader(url)
+	if err != nil {
+		return nil, err
+	}
+	return OpenReader(NewBlockReader(r, r.Size()), r.Size(), opts...)
+}
+
+func NewHTTPReader(url string) (*HTTPReader, error) {
+	return NewHTTPReaderWithClient(http.DefaultClient, url)
+}
+
+func NewHTTPReaderWithClient(client *http.Client, url string) (*HTTPReader, error) {
+	res, err := client.Head(url)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status %s", url, res.Status)
+	}
+	if !strings.Contains(res.Header.Get("Accept-Ranges"), "bytes") {
+		return nil, fmt.Errorf("%s: %w", url, ErrRange)
+	}
+	if res.ContentLength <= 0 {
+		return nil, fmt.Errorf("%s: unknown content length", url)
+	}
+	r := HTTPReader{
+		client: client,
+		url:    url,
+		size:   res.ContentLength,
+	}
+	return &r, nil
+}
+
+func (r *HTTPReader) Size() int64 {
+	return r.size
+}
+
+func (r *HTTPReader) ReadAt(b []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("readat: negative offset")
+	}
+	if offset >= r.size {
+		return 0, io.EOF
+	}
+	last := offset + int64(len(b)) - 1
+	if last >= r.size {
+		last = r.size - 1
+	}
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(last, 10))
+	res, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("%s: unexpected status %s: %w", r.url, res.Status, ErrRange)
+	}
+	n, err := io.ReadFull(res.Body, b[:last-offset+1])
+	if err == nil && n < len(b) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,395 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"sort"
+	"strings"
+)
+
+type ImageMode int
+
+const (
+	ImageRGB ImageMode = iota
+	ImageRaw
+)
+
+type PageImage struct {
+	Name             string
+	Page             int
+	Index            int
+	Resource         string
+	Oid              string
+	Width            int
+	Height           int
+	BitsPerComponent int
+	ColorSpace       string
+	Filter           string
+
+	inline *Object
+}
+
+func (d *Document) GetImages() []PageImage {
+	var list []PageImage
+	for p := 1; p <= len(d.pageIndex()); p++ {
+		list = append(list, d.GetPageImages(p)...)
+	}
+	return list
+}
+
+func (d *Document) ReadImage(pi PageImage) (image.Image, error) {
+	if pi.inline != nil {
+		return d.DecodeImage(*pi.inline, ImageRGB)
+	}
+	obj := d.getObjectWithOid(pi.Oid, true)
+	if obj.isZero() {
+		return nil, fmt.Errorf("%s %w", pi.Oid, ErrMissing)
+	}
+	return d.DecodeImage(obj, ImageRGB)
+}
+
+func (d *Document) GetPageImages(page int) []PageImage {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return nil
+	}
+	var (
+		res     = d.getPageResources(obj)
+		xobj, _ = d.resolve(res.getValue("xobject")).(Dict)
+		names   []string
+		objects []Object
+		inlines []Object
+		seen    = make(map[string]bool)
+	)
+	add := func(name string) {
+		oid := xobj.GetString(name)
+		if seen[strings.ToLower(name)] || oid == "" {
+			return
+		}
+		seen[strings.ToLower(name)] = true
+		if o := d.getObjectWithOid(oid, false); o.IsImage() {
+			names = append(names, name)
+			objects = append(objects, o)
+		}
+	}
+	if body, err := d.GetPageCode(page); err == nil {
+		toks := tokenizeCMap(body)
+		for i := 1; i < len(toks); i++ {
+			if toks[i] == "Do" && len(toks[i-1]) > 1 && toks[i-1][0] == slash {
+				add(toks[i-1][1:])
+			}
+		}
+		p := NewContentParser()
+		p.Register("BI", func(_ io.Writer, op Operator) error {
+			inlines = append(inlines, d.getInlineObject(res, *op.Inline))
+			return nil
+		})
+		p.Parse(body, io.Discard)
+	}
+	rest := make([]string, 0, len(xobj))
+	for name := range xobj {
+		rest = append(rest, name)
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		add(name)
+	}
+	list := make([]PageImage, 0, len(objects)+len(inlines))
+	for i, obj := range append(objects, inlines...) {
+		pi := PageImage{
+			Name:             fmt.Sprintf("p%d-img%d", page, i+1),
+			Page:             page,
+			Index:            i + 1,
+			Oid:              obj.Oid,
+			Width:            int(obj.GetInt("width")),
+			Height:           int(obj.GetInt("height")),
+			BitsPerComponent: int(obj.GetInt("bitspercomponent")),
+			ColorSpace:       d.getColorSpace(obj),
+		}
+		if i < len(names) {
+			pi.Resource = names[i]
+		} else {
+			pi.inline = &inlines[i-len(names)]
+		}
+		if filters := obj.getFilters(); len(filters) > 0 {
+			pi.Filter = filters[len(filters)-1]
+		}
+		if obj.GetBool("imagemask") {
+			pi.BitsPerComponent = 1
+		}
+		list = append(list, pi)
+	}
+	return list
+}
+
+func (d *Document) getInlineObject(res Dict, img InlineImage) Object {
+	obj := img.Object()
+	if name, ok := obj.getValue("colorspace").(NameValue); ok {
+		spaces, _ := d.resolve(res.getValue("colorspace")).(Dict)
+		if cs := spaces.getValue(string(name)); cs != nil {
+			obj.Dict["colorspace"] = cs
+		}
+	}
+	return obj
+}
+
+func (d *Document) GetPageImage(page, index int) (image.Image, error) {
+	obj, err := d.GetPageImageObject(page, index)
+	if err != nil {
+		return nil, err
+	}
+	return d.DecodeImage(obj, ImageRGB)
+}
+
+func (d *Document) GetPageThumbnail(page int) (image.Image, error) {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return nil, fmt.Errorf("page %d not found in document", page)
+	}
+	thumb := d.getObjectWithOid(obj.GetString("thumb"), true)
+	if thumb.isZero() {
+		return nil, fmt.Errorf("thumbnail of page %d %w", page, ErrMissing)
+	}
+	if !thumb.Has("subtype") {
+		dict := make(Dict)
+		for k, v := range thumb.Dict {
+			dict[k] = v
+		}
+		dict["subtype"] = "Image"
+		thumb.Dict = dict
+	}
+	return d.DecodeImage(thumb, ImageRGB)
+}
+
+func (d *Document) GetPageImageObject(page, index int) (Object, error) {
+	list := d.GetPageImages(page)
+	if index < 1 || index > len(list) {
+		return Object{}, fmt.Errorf("image %d on page %d %w", index, page, ErrMissing)
+	}
+	if pi := list[index-1]; pi.inline != nil {
+		return *pi.inline, nil
+	}
+	obj := d.getObjectWithOid(list[index-1].Oid, true)
+	if obj.isZero() {
+		return obj, fmt.Errorf("%s %w", list[index-1].Oid, ErrMissing)
+	}
+	return obj, nil
+}
+
+func parseImageName(name string) (int, int, bool) {
+	var page, index int
+	if n, _ := fmt.Sscanf(name, "p%d-img%d", &page, &index); n != 2 {
+		return 0, 0, false
+	}
+	return page, index, fmt.Sprintf("p%d-img%d", page, index) == name
+}
+
+func (d *Document) DecodeImage(obj Object, mode ImageMode) (image.Image, error) {
+	img, err := d.decodeImage(obj, mode)
+	if err != nil || mode == ImageRaw {
+		return img, err
+	}
+	return d.applyMask(img, obj), nil
+}
+
+func (d *Document) applyMask(img image.Image, obj Object) image.Image {
+	var (
+		oid     = obj.GetString("smask")
+		stencil bool
+	)
+	if !isOid(oid) {
+		oid, stencil = obj.GetString("mask"), true
+	}
+	if !isOid(oid) {
+		return img
+	}
+	mask, err := d.decodeImage(d.getObjectWithOid(oid, true), ImageRaw)
+	if err != nil {
+		return img
+	}
+	var (
+		rect = img.Bounds()
+		area = mask.Bounds()
+		out  = image.NewNRGBA(rect)
+	)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		my := area.Min.Y + (y-rect.Min.Y)*area.Dy()/rect.Dy()
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			var (
+				mx = area.Min.X + (x-rect.Min.X)*area.Dx()/rect.Dx()
+				c  = color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				a  = color.GrayModel.Convert(mask.At(mx, my)).(color.Gray)
+			)
+			c.A = a.Y
+			if stencil {
+				c.A = 0xff - a.Y
+			}
+			out.SetNRGBA(x, y, c)
+		}
+	}
+	return out
+}
+
+func (d *Document) decodeImage(obj Object, mode ImageMode) (image.Image, error) {
+	if !obj.IsImage() {
+		return nil, fmt.Errorf("%s: not an image", obj.Oid)
+	}
+	for _, f := range obj.getFilters() {
+		if f == filterJPX {
+			return nil, fmt.Errorf("%s: %s %w", obj.Oid, f, ErrUnsupported)
+		}
+	}
+	filters := obj.getFilters()
+	if len(filters) == 0 || filters[len(filters)-1] != filterDCT {
+		img, err := d.decodeSamples(obj)
+		if err != nil || mode == ImageRaw {
+			return img, err
+		}
+		return convertRGB(img, d.getColorSpace(obj)), nil
+	}
+	rs, err := obj.Encoded()
+	if err != nil {
+		return nil, err
+	}
+	img, err := jpeg.Decode(bytes.NewReader(rs))
+	if err != nil || mode == ImageRaw {
+		return img, err
+	}
+	img = applyDecode(img, d.getDecode(obj))
+	return convertRGB(img, d.getColorSpace(obj)), nil
+}
+
+func (d *Document) getDecode(obj Object) []float64 {
+	arr, _ := d.resolve(obj.getValue("decode")).([]interface{})
+	list := make([]float64, len(arr))
+	for i := range arr {
+		list[i] = getNumber(d.resolve(arr[i]))
+	}
+	return list
+}
+
+// applyDecode maps the components of the decoded JPEG through the Decode
+// array of the image. image/jpeg undoes the inversion of Adobe CMYK and YCCK
+// data on its own while PDF expects the Decode array to do it, so CMYK samples
+// are inverted back first.
+func applyDecode(img image.Image, decode []float64) image.Image {
+	switch img := img.(type) {
+	case *image.CMYK:
+		if len(decode) < 8 {
+			decode = []float64{0, 1, 0, 1, 0, 1, 0, 1}
+		}
+		out := image.NewCMYK(img.Rect)
+		for i, v := range img.Pix {
+			c := (i % 4) * 2
+			out.Pix[i] = decodeSample(0xff-v, decode[c], decode[c+1])
+		}
+		return out
+	case *image.Gray:
+		if len(decode) < 2 || isDefaultDecode(decode) {
+			return img
+		}
+		out := image.NewGray(img.Rect)
+		for i, v := range img.Pix {
+			out.Pix[i] = decodeSample(v, decode[0], decode[1])
+		}
+		return out
+	default:
+		if len(decode) < 6 || isDefaultDecode(decode) {
+			return img
+		}
+		var (
+			rect = img.Bounds()
+			out  = image.NewRGBA(rect)
+		)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				c.R = decodeSample(c.R, decode[0], decode[1])
+				c.G = decodeSample(c.G, decode[2], decode[3])
+				c.B = decodeSample(c.B, decode[4], decode[5])
+				out.SetRGBA(x, y, c)
+			}
+		}
+		return out
+	}
+}
+
+func isDefaultDecode(decode []float64) bool {
+	for i := 0; i+1 < len(decode); i += 2 {
+		if decode[i] != 0 || decode[i+1] != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeSample(v byte, lo, hi float64) byte {
+	return unitByte(lo + float64(v)*(hi-lo)/0xff)
+}
+
+func unitByte(f float64) byte {
+	f *= 0xff
+	switch {
+	case f <= 0:
+		return 0
+	case f >= 0xff:
+		return 0xff
+	default:
+		return byte(f + 0.5)
+	}
+}
+
+func (d *Document) getColorSpace(obj Object) string {
+	switch cs := d.resolve(obj.getValue("colorspace")).(type) {
+	case NameValue:
+		return string(cs)
+	case []interface{}:
+		if len(cs) > 0 {
+			str, _ := cs[0].(NameValue)
+			return string(str)
+		}
+	}
+	return ""
+}
+
+func convertRGB(img image.Image, space string) image.Image {
+	switch img := img.(type) {
+	case *image.CMYK:
+		return cmykToRGB(img)
+	case *image.Gray:
+		if space == "Separation" {
+			return invertGray(img)
+		}
+	}
+	return img
+}
+
+func cmykToRGB(img *image.CMYK) image.Image {
+	var (
+		rect = img.Bounds()
+		out  = image.NewRGBA(rect)
+	)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			c := img.CMYKAt(x, y)
+			r, g, b := color.CMYKToRGB(c.C, c.M, c.Y, c.K)
+			out.SetRGBA(x, y, color.RGBA{R: r, G: g, B: b, A: 0xff})
+		}
+	}
+	return out
+}
+
+func invertGray(img *image.Gray) image.Image {
+	var (
+		rect = img.Bounds()
+		out  = image.NewGray(rect)
+	)
+	for i := range img.Pix {
+		out.Pix[i] = 0xff - img.Pix[i]
+	}
+	return out
+}
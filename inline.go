@@ -0,0 +1,182 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+var (
+	begInline = []byte("ID")
+	endInline = []byte("EI")
+)
+
+var inlineKeys = map[string]string{
+	"bpc": "bitspercomponent",
+	"cs":  "colorspace",
+	"d":   "decode",
+	"dp":  "decodeparms",
+	"f":   "filter",
+	"h":   "height",
+	"i":   "interpolate",
+	"im":  "imagemask",
+	"l":   "length",
+	"w":   "width",
+}
+
+var inlineNames = map[string]string{
+	"AHx":  filterASCIIHex,
+	"A85":  filterASCII85,
+	"LZW":  "LZWDecode",
+	"Fl":   filterFlate,
+	"RL":   "RunLengthDecode",
+	"CCF":  "CCITTFaxDecode",
+	"DCT":  filterDCT,
+	"G":    "DeviceGray",
+	"RGB":  "DeviceRGB",
+	"CMYK": "DeviceCMYK",
+	"I":    "Indexed",
+}
+
+type InlineImage struct {
+	Dict Dict
+	Data []byte
+}
+
+func (i InlineImage) Object() Object {
+	dict := make(Dict)
+	for k, v := range i.Dict {
+		if long, ok := inlineKeys[k]; ok {
+			k = long
+		}
+		switch k {
+		case "filter", "colorspace":
+			v = expandInlineName(v)
+		}
+		dict[k] = v
+	}
+	dict["type"] = "XObject"
+	dict["subtype"] = "Image"
+	return Object{
+		Dict:    dict,
+		Content: i.Data,
+	}
+}
+
+func expandInlineName(v Value) Value {
+	switch v := v.(type) {
+	case NameValue:
+		if long, ok := inlineNames[string(v)]; ok {
+			return NameValue(long)
+		}
+		return v
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i := range v {
+			arr[i] = expandInlineName(v[i])
+		}
+		return arr
+	default:
+		return v
+	}
+}
+
+func (i InlineImage) size() int {
+	if len(i.Dict.getFilters()) > 0 || i.Dict.Has("f") {
+		return 0
+	}
+	var (
+		width  = i.Dict.GetInt("w")
+		height = i.Dict.GetInt("h")
+		bits   = i.Dict.GetInt("bpc")
+		comps  int64
+	)
+	if i.Dict.GetBool("im") {
+		bits, comps = 1, 1
+	}
+	switch cs := expandInlineName(i.Dict.getValue("cs")).(type) {
+	case NameValue:
+		comps = int64(colorComponents(string(cs)))
+	case []interface{}:
+		if len(cs) > 0 && cs[0] == NameValue("Indexed") {
+			comps = 1
+		}
+	}
+	if width <= 0 || height <= 0 || bits <= 0 || comps <= 0 {
+		return 0
+	}
+	return int(height * ((width*comps*bits + 7) / 8))
+}
+
+func indexInlineEnd(buf []byte) int {
+	for i := 0; i < len(buf); {
+		x := bytes.Index(buf[i:], endInline)
+		if x < 0 {
+			break
+		}
+		x += i
+		var (
+			next   = x + len(endInline)
+			before = x == 0 || isBlank(buf[x-1])
+			after  = next == len(buf) || isBlank(buf[next]) || isDelimiter(buf[next])
+		)
+		if before && after {
+			return x
+		}
+		i = next
+	}
+	return -1
+}
+
+func readInlineImage(r *Reader) (InlineImage, error) {
+	img := InlineImage{
+		Dict: make(Dict),
+	}
+	for {
+		skipBlank(r)
+		if r.Len() == 0 {
+			return img, fmt.Errorf("inline image: %s %w", begInline, ErrMissing)
+		}
+		if r.StartsWith(begInline) {
+			r.Discard(len(begInline) + 1)
+			break
+		}
+		name, err := parseName(r)
+		if err != nil {
+			return img, fmt.Errorf("inline image: %s", err)
+		}
+		value, err := parseValue(r, nil)
+		if err != nil {
+			return img, fmt.Errorf("inline image %s: invalid value %w", name, err)
+		}
+		img.Dict[strings.ToLower(name)] = value
+	}
+	var (
+		buf = r.Bytes()
+		end = -1
+	)
+	if n := img.size(); n > 0 && n < len(buf) {
+		if x := indexInlineEnd(buf[n:]); x >= 0 && isBlank(buf[n]) && len(bytes.TrimSpace(buf[n:n+x])) == 0 {
+			end = n + x
+		}
+	}
+	if end < 0 {
+		end = indexInlineEnd(buf)
+	}
+	if end < 0 {
+		return img, fmt.Errorf("inline image: %s %w", endInline, ErrMissing)
+	}
+	data := buf[:end]
+	if n := len(data); n > 0 && isBlank(data[n-1]) {
+		data = data[:n-1]
+		if n > 1 && data[n-2] == cr && buf[end-1] == nl {
+			data = data[:n-2]
+		}
+	}
+	img.Data = data
+	if n := int(img.Dict.GetInt("l")); n > 0 && n <= end {
+		img.Data = buf[:n]
+	}
+	r.Discard(end + len(endInline))
+	return img, nil
+}
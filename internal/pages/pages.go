@@ -0,0 +1,207 @@
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var ErrInvalid = errors.New("invalid page number")
+
+type Ranger interface {
+	Pages(int64) []int
+}
+
+func makeInterval(from, to string) (Ranger, error) {
+	var i Interval
+	fst, err := strconv.Atoi(from)
+	if err != nil && from != "" {
+		i.from = from
+	}
+	lst, err := strconv.Atoi(to)
+	if err != nil && to != "" {
+		i.to = to
+	}
+	if fst > 0 && lst > 0 && fst >= lst {
+		return nil, fmt.Errorf("invalid interval (%d - %d)", fst, lst)
+	}
+	i.first, i.last = fst, lst
+	return i, nil
+}
+
+func (i Interval) Pages(n int64) []int {
+	if i.from != "" || i.to != "" {
+		return nil
+	}
+	if i.first == 0 {
+		i.first = 1
+	}
+	if i.last == 0 {
+		i.last = int(n)
+	}
+	var ps []int
+	for j := i.first; j <= i.last; j++ {
+		ps = append(ps, j)
+	}
+	return ps
+}
+
+// a range is defined with
+// : = all pages
+// x: = from page X to end of document
+// :x = from begin of a document to page X
+// x:y = from page x to page y (offset can be negative)
+// x,y,z = list of page
+// possible to mix range and individual page
+// pages can also be given by their label (iv, A-3) once resolved
+type Range struct {
+	pages []Ranger
+}
+
+func (r *Range) Set(str string) (err error) {
+	if str == "" {
+		return nil
+	}
+	if str == ":" {
+		r.pages = append(r.pages, all())
+		return nil
+	}
+	r.pages, err = parseRange(str)
+	return err
+}
+
+func (r *Range) String() string {
+	if len(r.pages) == 0 {
+		return "outline"
+	}
+	return "page"
+}
+
+func (r *Range) Pages(n int64) []int {
+	var ps []int
+	for _, p := range r.pages {
+		ps = append(ps, p.Pages(n)...)
+	}
+	return ps
+}
+
+// Resolve replaces the page labels used in the range by the page numbers
+// returned by fn.
+func (r *Range) Resolve(fn func(string) (int, error)) error {
+	for j, p := range r.pages {
+		switch p := p.(type) {
+		case Single:
+			if p.label == "" {
+				continue
+			}
+			n, err := fn(p.label)
+			if err != nil {
+				return err
+			}
+			r.pages[j] = Single{page: n}
+		case Interval:
+			var err error
+			if p.from != "" {
+				if p.first, err = fn(p.from); err != nil {
+					return err
+				}
+			}
+			if p.to != "" {
+				if p.last, err = fn(p.to); err != nil {
+					return err
+				}
+			}
+			if p.first > 0 && p.last > 0 && p.first >= p.last {
+				return fmt.Errorf("invalid interval (%d - %d)", p.first, p.last)
+			}
+			p.from, p.to = "", ""
+			r.pages[j] = p
+		}
+	}
+	return nil
+}
+
+func (r *Range) IsEmpty() bool {
+	return len(r.pages) == 0
+}
+
+const (
+	colon = ':'
+	comma = ','
+)
+
+func parseRange(str string) ([]Ranger, error) {
+	var (
+		pages []Ranger
+		i     int
+	)
+	for j := 0; j < len(str); j++ {
+		switch b := str[j]; b {
+		case comma:
+			g, err := makeSingle(str[i:j])
+			if err != nil {
+				return nil, err
+			}
+			pages, i = append(pages, g), j+1
+		case colon:
+			k := j + 1
+			for ; k < len(str); k++ {
+				if str[k] == comma {
+					break
+				}
+				if str[k] == colon {
+					return nil, fmt.Errorf("syntax error: unexpected colon")
+				}
+			}
+			g, err := makeInterval(str[i:j], str[j+1:k])
+			if err != nil {
+				return nil, err
+			}
+			j = k + 1
+			pages, i = append(pages, g), j
+		default:
+		}
+	}
+	if i < len(str) {
+		g, err := makeSingle(str[i:])
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, g)
+	}
+	return pages, nil
+}
+
+type Single struct {
+	page  int
+	label string
+}
+
+func makeSingle(str string) (Ranger, error) {
+	if str == "" {
+		return nil, fmt.Errorf("%s: %w", str, ErrInvalid)
+	}
+	n, err := strconv.Atoi(str)
+	if err != nil {
+		return Single{label: str}, nil
+	}
+	return Single{page: n}, nil
+}
+
+func (s Single) Pages(_ int64) []int {
+	if s.label != "" {
+		return nil
+	}
+	return []int{s.page}
+}
+
+type Interval struct {
+	first int
+	last  int
+	from  string
+	to    string
+}
+
+func all() Ranger {
+	return Interval{}
+}
@@ -0,0 +1,133 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	filterDCT = "DCTDecode"
+	filterJPX = "JPXDecode"
+)
+
+var (
+	jp2Signature = []byte{0x00, 0x00, 0x00, 0x0c, 'j', 'P', ' ', ' ', 0x0d, 0x0a, 0x87, 0x0a}
+	j2kSignature = []byte{0xff, 0x4f, 0xff, 0x51}
+)
+
+type RawImage struct {
+	Oid              string
+	Filter           string
+	Width            int
+	Height           int
+	Components       int
+	BitsPerComponent int
+	ColorSpace       string
+	Data             []byte
+}
+
+func (r RawImage) Ext() string {
+	switch r.Filter {
+	case filterDCT:
+		return ".jpg"
+	case filterJPX:
+		if bytes.HasPrefix(r.Data, jp2Signature) {
+			return ".jp2"
+		}
+		return ".j2k"
+	default:
+		return ".bin"
+	}
+}
+
+func (d *Document) GetRawImage(name string) (RawImage, error) {
+	var obj Object
+	if page, index, ok := parseImageName(name); ok {
+		o, err := d.GetPageImageObject(page, index)
+		if err != nil {
+			return RawImage{}, err
+		}
+		obj = o
+	} else {
+		obj = d.getObjectWithOid(d.getImageOid(name), true)
+	}
+	if obj.isZero() {
+		return RawImage{}, fmt.Errorf("image %s %w", name, ErrMissing)
+	}
+	return d.ReadRawImage(obj)
+}
+
+func (d *Document) ReadRawImage(obj Object) (RawImage, error) {
+	if !obj.IsImage() {
+		return RawImage{}, fmt.Errorf("%s: not an image", obj.Oid)
+	}
+	raw := RawImage{
+		Oid:              obj.Oid,
+		Width:            int(obj.GetInt("width")),
+		Height:           int(obj.GetInt("height")),
+		BitsPerComponent: int(obj.GetInt("bitspercomponent")),
+		ColorSpace:       d.getColorSpace(obj),
+	}
+	if filters := obj.getFilters(); len(filters) > 0 {
+		raw.Filter = filters[len(filters)-1]
+	}
+	var err error
+	if raw.Filter == filterDCT || raw.Filter == filterJPX {
+		raw.Data, err = obj.Encoded()
+	} else {
+		raw.Data, err = obj.Body()
+	}
+	if err != nil {
+		return raw, err
+	}
+	if raw.Filter == filterJPX {
+		if w, h, nc, bpc, ok := jpxInfo(raw.Data); ok {
+			if raw.Width == 0 {
+				raw.Width = w
+			}
+			if raw.Height == 0 {
+				raw.Height = h
+			}
+			if raw.BitsPerComponent == 0 {
+				raw.BitsPerComponent = bpc
+			}
+			raw.Components = nc
+		}
+	}
+	if raw.Components == 0 {
+		raw.Components = colorComponents(raw.ColorSpace)
+	}
+	return raw, nil
+}
+
+func jpxInfo(buf []byte) (int, int, int, int, bool) {
+	x := bytes.Index(buf, j2kSignature)
+	if x < 0 {
+		return 0, 0, 0, 0, false
+	}
+	siz := buf[x+len(j2kSignature):]
+	if len(siz) < 41 {
+		return 0, 0, 0, 0, false
+	}
+	var (
+		width  = binary.BigEndian.Uint32(siz[4:]) - binary.BigEndian.Uint32(siz[12:])
+		height = binary.BigEndian.Uint32(siz[8:]) - binary.BigEndian.Uint32(siz[16:])
+		comps  = binary.BigEndian.Uint16(siz[36:])
+		bits   = int(siz[38]&0x7f) + 1
+	)
+	return int(width), int(height), int(comps), bits, true
+}
+
+func colorComponents(space string) int {
+	switch space {
+	case "DeviceGray", "CalGray", "Indexed", "Separation":
+		return 1
+	case "DeviceRGB", "CalRGB", "Lab":
+		return 3
+	case "DeviceCMYK":
+		return 4
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,96 @@
+package pdf
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MarshalJSON writes a name with its leading slash.
+func (n NameValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal("/" + string(n))
+}
+
+// MarshalJSON writes a reference as {"ref": "number generation"}.
+func (r Reference) MarshalJSON() ([]byte, error) {
+	ref := struct {
+		Ref string `json:"ref"`
+	}{
+		Ref: strings.Replace(string(r), "/", " ", 1),
+	}
+	return json.Marshal(ref)
+}
+
+// MarshalJSON writes binary strings as {"base64": ...}.
+func (s StringValue) MarshalJSON() ([]byte, error) {
+	if str := s.String(); isText(str) {
+		return json.Marshal(str)
+	}
+	bin := struct {
+		Data string `json:"base64"`
+	}{
+		Data: base64.StdEncoding.EncodeToString(s),
+	}
+	return json.Marshal(bin)
+}
+
+func (d Dict) MarshalJSON() ([]byte, error) {
+	values := make(map[string]interface{}, len(d))
+	for k, v := range d {
+		values[k] = jsonValue(v)
+	}
+	return json.Marshal(values)
+}
+
+// MarshalJSON writes the length of the stream, not its body.
+func (o Object) MarshalJSON() ([]byte, error) {
+	obj := struct {
+		Oid    string      `json:"oid"`
+		Value  interface{} `json:"value"`
+		Stream *int64      `json:"stream,omitempty"`
+	}{
+		Oid: strings.Replace(o.Oid, "/", " ", 1),
+	}
+	if o.Dict != nil {
+		obj.Value = o.Dict
+	} else {
+		obj.Value = jsonValue(o.Data)
+	}
+	if o.HasStream() {
+		size := o.streamLength()
+		obj.Stream = &size
+	}
+	return json.Marshal(obj)
+}
+
+func jsonValue(v Value) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i := range v {
+			arr[i] = jsonValue(v[i])
+		}
+		return arr
+	case string:
+		if v == "null" {
+			return nil
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isText(str string) bool {
+	if !utf8.ValidString(str) {
+		return false
+	}
+	for _, r := range str {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	LabelDecimal    = "D"
+	LabelUpperRoman = "R"
+	LabelLowerRoman = "r"
+	LabelUpperAlpha = "A"
+	LabelLowerAlpha = "a"
+)
+
+type PageLabel struct {
+	Page   int
+	Style  string
+	Prefix string
+	First  int
+}
+
+func (p PageLabel) format(page int) string {
+	n := p.First + page - p.Page
+	switch p.Style {
+	case LabelDecimal:
+		return p.Prefix + strconv.Itoa(n)
+	case LabelUpperRoman:
+		return p.Prefix + formatRoman(n)
+	case LabelLowerRoman:
+		return p.Prefix + strings.ToLower(formatRoman(n))
+	case LabelUpperAlpha:
+		return p.Prefix + formatAlpha(n)
+	case LabelLowerAlpha:
+		return p.Prefix + strings.ToLower(formatAlpha(n))
+	default:
+		return p.Prefix
+	}
+}
+
+func (d *Document) GetPageLabels() []PageLabel {
+	root := d.getCatalog()
+	if root.isZero() || !root.Has("pagelabels") {
+		return nil
+	}
+	var list []PageLabel
+	d.walkNumberTree(root.getValue("pagelabels"), func(n int64, v Value) {
+		dict, _ := d.resolve(v).(Dict)
+		pl := PageLabel{
+			Page:   int(n) + 1,
+			Style:  dict.GetString("s"),
+			Prefix: d.resolveString(dict.getValue("p")),
+			First:  1,
+		}
+		if first, ok := d.resolve(dict.getValue("st")).(int64); ok && first > 0 {
+			pl.First = int(first)
+		}
+		list = append(list, pl)
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Page < list[j].Page
+	})
+	return list
+}
+
+func (d *Document) GetPageLabel(page int) string {
+	if page < 1 || page > int(d.GetCount()) {
+		return ""
+	}
+	return pageLabel(d.GetPageLabels(), page)
+}
+
+func (d *Document) LabelToPage(label string) (int, error) {
+	var (
+		labels = d.GetPageLabels()
+		count  = int(d.GetCount())
+	)
+	for p := 1; p <= count; p++ {
+		if pageLabel(labels, p) == label {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("page label %s %w", label, ErrMissing)
+}
+
+func pageLabel(labels []PageLabel, page int) string {
+	i := sort.Search(len(labels), func(i int) bool {
+		return labels[i].Page > page
+	})
+	if i == 0 {
+		return strconv.Itoa(page)
+	}
+	return labels[i-1].format(page)
+}
+
+func formatRoman(n int) string {
+	if n <= 0 {
+		return strconv.Itoa(n)
+	}
+	var (
+		values  = []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+		symbols = []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+		str     strings.Builder
+	)
+	for i := range values {
+		for n >= values[i] {
+			str.WriteString(symbols[i])
+			n -= values[i]
+		}
+	}
+	return str.String()
+}
+
+func formatAlpha(n int) string {
+	if n <= 0 {
+		return strconv.Itoa(n)
+	}
+	var (
+		letter = byte('A' + (n-1)%26)
+		count  = (n-1)/26 + 1
+	)
+	return strings.Repeat(string(letter), count)
+}
+
+func (d *Document) walkNumberTree(v Value, fn func(int64, Value)) {
+	var (
+		seen = make(map[string]bool)
+		walk func(Value)
+	)
+	walk = func(v Value) {
+		if oid, ok := toOid(v); ok {
+			if seen[oid] {
+				return
+			}
+			seen[oid] = true
+		}
+		node, _ := d.resolve(v).(Dict)
+		if node == nil {
+			return
+		}
+		nums, _ := d.resolve(node.getValue("nums")).([]interface{})
+		for i := 0; i+1 < len(nums); i += 2 {
+			key, ok := d.resolve(nums[i]).(int64)
+			if !ok {
+				continue
+			}
+			fn(key, nums[i+1])
+		}
+		kids, _ := d.resolve(node.getValue("kids")).([]interface{})
+		for _, k := range kids {
+			walk(k)
+		}
+	}
+	walk(v)
+}
@@ -0,0 +1,156 @@
+package pdf
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+var scriptLangs = []struct {
+	Table *unicode.RangeTable
+	Lang  string
+}{
+	{Table: unicode.Hiragana, Lang: "ja"},
+	{Table: unicode.Katakana, Lang: "ja"},
+	{Table: unicode.Hangul, Lang: "ko"},
+	{Table: unicode.Han, Lang: "zh"},
+	{Table: unicode.Cyrillic, Lang: "ru"},
+	{Table: unicode.Greek, Lang: "el"},
+	{Table: unicode.Arabic, Lang: "ar"},
+	{Table: unicode.Hebrew, Lang: "he"},
+	{Table: unicode.Thai, Lang: "th"},
+	{Table: unicode.Devanagari, Lang: "hi"},
+	{Table: unicode.Georgian, Lang: "ka"},
+	{Table: unicode.Armenian, Lang: "hy"},
+}
+
+func (d *Document) GetPageLang(page int, guess bool) []string {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return nil
+	}
+	var (
+		seen = make(map[string]int)
+		add  = func(lang string, n int) {
+			if lang != "" {
+				seen[lang] += n
+			}
+		}
+	)
+	for _, lang := range d.getStructLangs(obj.Oid) {
+		add(lang, 1)
+	}
+	if body, err := d.GetPageCode(page); err == nil {
+		for _, lang := range getContentLangs(body) {
+			add(lang, 1)
+		}
+	}
+	if len(seen) == 0 {
+		add(d.GetLang(), 1)
+	}
+	if len(seen) == 0 && guess {
+		if body, err := d.GetPage(page); err == nil {
+			for lang, n := range guessLangs(body) {
+				add(lang, n)
+			}
+		}
+	}
+	list := make([]string, 0, len(seen))
+	for lang := range seen {
+		list = append(list, lang)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if seen[list[i]] == seen[list[j]] {
+			return list[i] < list[j]
+		}
+		return seen[list[i]] > seen[list[j]]
+	})
+	return list
+}
+
+func (d *Document) getStructLangs(page string) []string {
+	root := d.getCatalog()
+	if root.isZero() {
+		return nil
+	}
+	tree, _ := d.resolve(root.getValue("structtreeroot")).(Dict)
+	if tree == nil {
+		return nil
+	}
+	var (
+		list []string
+		seen = make(map[string]bool)
+		walk func(Value, string, string)
+	)
+	walk = func(v Value, lang, pg string) {
+		if oid, ok := toOid(v); ok {
+			if seen[oid] {
+				return
+			}
+			seen[oid] = true
+		}
+		switch v := d.resolve(v).(type) {
+		case Dict:
+			if str := d.resolveString(v.getValue("lang")); str != "" {
+				lang = str
+			}
+			if str := v.GetString("pg"); str != "" {
+				pg = str
+			}
+			if v.Type() == "MCR" || v.Type() == "OBJR" || !v.Has("k") {
+				if pg == page && lang != "" {
+					list = append(list, lang)
+				}
+				return
+			}
+			walk(v.getValue("k"), lang, pg)
+		case []interface{}:
+			for i := range v {
+				walk(v[i], lang, pg)
+			}
+		case int64:
+			if pg == page && lang != "" {
+				list = append(list, lang)
+			}
+		}
+	}
+	walk(tree.getValue("k"), d.GetLang(), "")
+	return list
+}
+
+func getContentLangs(body []byte) []string {
+	var (
+		r    = NewReader(body)
+		list []string
+		prev Token
+	)
+	for r.Len() > 0 {
+		tok := readToken(r)
+		if tok.Type == EOF {
+			break
+		}
+		if tok.Type == String && prev.Type == Name && prev.Literal == "Lang" {
+			list = append(list, convertString(tok.Literal))
+		}
+		prev = tok
+	}
+	return list
+}
+
+func guessLangs(body []byte) map[string]int {
+	set := make(map[string]int)
+	for len(body) > 0 {
+		r, z := utf8.DecodeRune(body)
+		body = body[z:]
+		if r == utf8.RuneError || !unicode.IsLetter(r) {
+			continue
+		}
+		for _, s := range scriptLangs {
+			if unicode.Is(s.Table, r) {
+				set[s.Lang]++
+				break
+			}
+		}
+	}
+	return set
+}
@@ -0,0 +1,106 @@
+package pdf
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+type TextMode int
+
+const (
+	TextRaw TextMode = iota
+	TextReading
+	TextLayout
+	TextStructure
+)
+
+func (d *Document) GetTextWith(page int, mode TextMode) (string, error) {
+	switch mode {
+	case TextRaw:
+		return d.GetText(page)
+	case TextStructure:
+		return d.getStructuredText(page)
+	}
+	runs, err := d.GetTextRuns(page)
+	if err != nil {
+		return "", err
+	}
+	lines := groupLines(runs)
+	if mode == TextLayout {
+		return layoutLines(lines), nil
+	}
+	var buf strings.Builder
+	for _, line := range lines {
+		for i, r := range line {
+			if i > 0 && needSpace(line[i-1], r) {
+				buf.WriteByte(space)
+			}
+			buf.WriteString(r.Text)
+		}
+		buf.WriteByte(nl)
+	}
+	return buf.String(), nil
+}
+
+func needSpace(prev, next TextRun) bool {
+	if strings.HasSuffix(prev.Text, " ") || strings.HasPrefix(next.Text, " ") {
+		return false
+	}
+	return next.Rect[0]-prev.Rect[2] > next.Size*0.1
+}
+
+func layoutLines(lines [][]TextRun) string {
+	var (
+		left  = math.Inf(1)
+		width float64
+		count int
+	)
+	for _, line := range lines {
+		for _, r := range line {
+			left = math.Min(left, r.Rect[0])
+			width += r.Rect[2] - r.Rect[0]
+			count += utf8.RuneCountInString(r.Text)
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+	var (
+		char = width / float64(count)
+		buf  strings.Builder
+		prev TextRun
+	)
+	if char <= 0 {
+		char = 1
+	}
+	for i, line := range lines {
+		if i > 0 {
+			var (
+				height = prev.Size * 1.2
+				skip   = int((prev.Rect[1]-line[0].Rect[1])/height+0.5) - 1
+			)
+			for j := 0; j < skip && j < 3; j++ {
+				buf.WriteByte(nl)
+			}
+		}
+		col := 0
+		for j, r := range line {
+			pos := int((r.Rect[0]-left)/char + 0.5)
+			if j > 0 && r.Rect[0]-line[j-1].Rect[2] < r.Size {
+				pos = col
+				if needSpace(line[j-1], r) {
+					pos++
+				}
+			}
+			for ; col < pos; col++ {
+				buf.WriteByte(space)
+			}
+			buf.WriteString(r.Text)
+			col += utf8.RuneCountInString(r.Text)
+		}
+		buf.WriteByte(nl)
+		prev = line[0]
+	}
+	return buf.String()
+}
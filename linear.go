@@ -0,0 +1,283 @@
+package pdf
+
+import (
+	"fmt"
+)
+
+type linearization struct {
+	Oid    string
+	Length int64
+	Hint   []int64
+	First  int64
+	End    int64
+	Count  int64
+	XRef   int64
+}
+
+func readLinearization(obj Object) *linearization {
+	return &linearization{
+		Oid:    obj.Oid,
+		Length: obj.GetInt("l"),
+		Hint:   obj.GetIntArray("h"),
+		First:  obj.GetInt("o"),
+		End:    obj.GetInt("e"),
+		Count:  obj.GetInt("n"),
+		XRef:   obj.GetInt("t"),
+	}
+}
+
+type PageHint struct {
+	Page    int
+	Objects int64
+	Offset  int64
+	Length  int64
+	Shared  []int64
+	Content int64
+	Size    int64
+}
+
+type SharedHint struct {
+	Group   int
+	Object  int64
+	Offset  int64
+	Length  int64
+	Objects int64
+}
+
+type Linearization struct {
+	Length   int64
+	First    int64
+	End      int64
+	Count    int64
+	MainXRef int64
+	Hint     []int64
+
+	Pages    []PageHint
+	Shared   []SharedHint
+	Problems []string
+}
+
+func (d *Document) IsLinearized() (Linearization, bool) {
+	var lz Linearization
+	if d.linear == nil {
+		return lz, false
+	}
+	lz.Length = d.linear.Length
+	lz.First = d.linear.First
+	lz.End = d.linear.End
+	lz.Count = d.linear.Count
+	lz.MainXRef = d.linear.XRef
+	lz.Hint = d.linear.Hint
+
+	report := func(msg string, args ...interface{}) {
+		lz.Problems = append(lz.Problems, fmt.Sprintf(msg, args...))
+	}
+	if lz.Length != d.size {
+		report("file length mismatch: /L %d, actual %d", lz.Length, d.size)
+	}
+	if lz.End <= 0 || lz.End > d.size {
+		report("end of first page %d outside of file", lz.End)
+	}
+	if lz.MainXRef <= 0 || lz.MainXRef > d.size {
+		report("main xref offset %d outside of file", lz.MainXRef)
+	}
+	pages := d.pageIndex()
+	if int64(len(pages)) != lz.Count {
+		report("page count mismatch: /N %d, actual %d", lz.Count, len(pages))
+	}
+	if len(pages) > 0 {
+		if num := objectNumber(pages[0]); int64(num) != lz.First {
+			report("first page mismatch: /O %d, actual %d", lz.First, num)
+		}
+	}
+	if len(lz.Hint) < 2 {
+		report("hint stream location missing")
+		return lz, len(lz.Problems) == 0
+	}
+	if err := d.readHints(&lz, int64(len(pages))); err != nil {
+		report("hint stream: %s", err)
+		return lz, false
+	}
+	for i, p := range lz.Pages {
+		if i >= len(pages) {
+			break
+		}
+		x, ok := d.lookupOid(pages[i])
+		if !ok || x.isEmbed() {
+			continue
+		}
+		if off := x.Offset; off != p.Offset {
+			report("page %d: hint offset %d, actual %d", p.Page, p.Offset, off)
+		}
+	}
+	return lz, len(lz.Problems) == 0
+}
+
+func (d *Document) readHints(lz *Linearization, pages int64) error {
+	if lz.Count < 0 || lz.Count > pages {
+		return fmt.Errorf("invalid page count %d", lz.Count)
+	}
+	obj, err := d.readObjectAt(lz.Hint[0], cryptKeys{}, true)
+	if err != nil {
+		return err
+	}
+	body, err := obj.Body()
+	if err != nil {
+		return err
+	}
+	adjust := func(offset int64) int64 {
+		if offset >= lz.Hint[0] {
+			offset += lz.Hint[1]
+		}
+		return offset
+	}
+	if lz.Pages, err = readPageHints(body, lz.Count, adjust); err != nil {
+		return err
+	}
+	if offset := obj.GetInt("s"); offset > 0 && offset < int64(len(body)) {
+		lz.Shared, err = readSharedHints(body[offset:], adjust)
+	}
+	return err
+}
+
+func readPageHints(body []byte, count int64, adjust func(int64) int64) ([]PageHint, error) {
+	var (
+		br    = bitReader{buf: body}
+		least = br.Read(32)
+		first = br.Read(32)
+		bobjs = br.Read(16)
+		llen  = br.Read(32)
+		blen  = br.Read(16)
+		loff  = br.Read(32)
+		boff  = br.Read(16)
+		lsize = br.Read(32)
+		bsize = br.Read(16)
+		bnum  = br.Read(16)
+		bids  = br.Read(16)
+		bfrac = br.Read(16)
+		_     = br.Read(16)
+		pages = make([]PageHint, count)
+	)
+	if br.err != nil {
+		return nil, fmt.Errorf("page offset header: %w", br.err)
+	}
+	for i := range pages {
+		pages[i].Page = i + 1
+		pages[i].Objects = least + br.Read(int(bobjs))
+	}
+	br.Align()
+	for i := range pages {
+		pages[i].Length = llen + br.Read(int(blen))
+	}
+	br.Align()
+	for i := range pages {
+		pages[i].Shared = make([]int64, br.Read(int(bnum)))
+	}
+	br.Align()
+	for i := range pages {
+		for j := range pages[i].Shared {
+			pages[i].Shared[j] = br.Read(int(bids))
+		}
+	}
+	br.Align()
+	for i := range pages {
+		for range pages[i].Shared {
+			br.Read(int(bfrac))
+		}
+	}
+	br.Align()
+	for i := range pages {
+		pages[i].Content = loff + br.Read(int(boff))
+	}
+	br.Align()
+	for i := range pages {
+		pages[i].Size = lsize + br.Read(int(bsize))
+	}
+	if br.err != nil {
+		return nil, fmt.Errorf("page offset entries: %w", br.err)
+	}
+	offset := first
+	for i := range pages {
+		pages[i].Offset = adjust(offset)
+		offset += pages[i].Length
+	}
+	return pages, nil
+}
+
+func readSharedHints(body []byte, adjust func(int64) int64) ([]SharedHint, error) {
+	var (
+		br     = bitReader{buf: body}
+		object = br.Read(32)
+		offset = br.Read(32)
+		nfirst = br.Read(32)
+		count  = br.Read(32)
+		bobjs  = br.Read(16)
+		least  = br.Read(32)
+		blen   = br.Read(16)
+	)
+	if br.err != nil {
+		return nil, fmt.Errorf("shared objects header: %w", br.err)
+	}
+	if count < nfirst || count > int64(len(br.buf)*8-br.pos) {
+		return nil, fmt.Errorf("shared objects: invalid group count")
+	}
+	groups := make([]SharedHint, count)
+	for i := range groups {
+		groups[i].Group = i
+		groups[i].Length = least + br.Read(int(blen))
+	}
+	br.Align()
+	signed := make([]bool, count)
+	for i := range groups {
+		signed[i] = br.Read(1) == 1
+	}
+	br.Align()
+	for i := range groups {
+		if signed[i] {
+			br.Skip(128)
+		}
+	}
+	for i := range groups {
+		groups[i].Objects = 1 + br.Read(int(bobjs))
+	}
+	if br.err != nil {
+		return nil, fmt.Errorf("shared objects entries: %w", br.err)
+	}
+	for i := nfirst; i < count; i++ {
+		groups[i].Object = object
+		groups[i].Offset = adjust(offset)
+		object += groups[i].Objects
+		offset += groups[i].Length
+	}
+	return groups, nil
+}
+
+type bitReader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (b *bitReader) Read(n int) int64 {
+	var v int64
+	for i := 0; i < n; i++ {
+		if b.pos >= len(b.buf)*8 {
+			b.err = ErrMissing
+			return v
+		}
+		bit := (b.buf[b.pos/8] >> (7 - uint(b.pos%8))) & 1
+		v = (v << 1) | int64(bit)
+		b.pos++
+	}
+	return v
+}
+
+func (b *bitReader) Skip(n int) {
+	b.pos += n
+}
+
+func (b *bitReader) Align() {
+	if r := b.pos % 8; r != 0 {
+		b.pos += 8 - r
+	}
+}
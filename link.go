@@ -0,0 +1,259 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Link struct {
+	Rect Rect
+	URI  string
+	Page int
+	View string
+}
+
+// href keeps only http, https and mailto URIs.
+func (k Link) href() (string, bool) {
+	if k.URI == "" {
+		return fmt.Sprintf("#page-%d", k.Page), k.Page > 0
+	}
+	u, err := url.Parse(k.URI)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return k.URI, true
+	default:
+		return "", false
+	}
+}
+
+func (d *Document) GetLinks(page int) ([]Link, error) {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return nil, fmt.Errorf("page %d not found in document", page)
+	}
+	var (
+		pages = d.getPageNumbers()
+		list  []Link
+	)
+	for _, annot := range d.getPageAnnots(obj) {
+		if annot.Subtype() != "Link" {
+			continue
+		}
+		link := Link{
+			Rect: Rect(getRect(d.resolve(annot.getValue("rect")))),
+		}
+		if act, _ := d.resolve(annot.getValue("a")).(Dict); act.GetString("s") == "URI" {
+			link.URI = strings.TrimSpace(d.resolveString(act.getValue("uri")))
+		} else {
+			link.Page, link.View = d.getOutlineTarget(annot, pages)
+		}
+		if link.URI == "" && link.Page == 0 {
+			continue
+		}
+		list = append(list, link)
+	}
+	return list, nil
+}
+
+func (d *Document) getPageLinks(page int) []Link {
+	list, _ := d.GetLinks(page)
+	return list
+}
+
+// Destination is the target of a link or of an outline entry. Params holds
+// the numeric parameters of the view (left, top, zoom for XYZ...) and NaN
+// for the ones left unspecified (null) in the document.
+type Destination struct {
+	Name   string
+	Page   int
+	View   string
+	Params []float64
+}
+
+func (d Destination) String() string {
+	if d.View == "" {
+		return d.Name
+	}
+	parts := []string{d.View}
+	for _, f := range d.Params {
+		if math.IsNaN(f) {
+			parts = append(parts, "null")
+			continue
+		}
+		parts = append(parts, strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (d *Document) ResolveDestination(name string) (Destination, error) {
+	v := d.lookupDest(name)
+	if v == nil {
+		return Destination{}, fmt.Errorf("destination %s %w", name, ErrMissing)
+	}
+	dest := d.getDestination(v, d.getPageNumbers())
+	dest.Name = name
+	if dest.Page == 0 {
+		return dest, fmt.Errorf("destination %s: page %w", name, ErrMissing)
+	}
+	return dest, nil
+}
+
+func (d *Document) GetDestinations() []Destination {
+	var (
+		dests = d.getDests()
+		pages = d.getPageNumbers()
+		list  = make([]Destination, 0, len(dests))
+	)
+	for name, v := range dests {
+		dest := d.getDestination(d.resolveDest(v), pages)
+		dest.Name = name
+		list = append(list, dest)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+func (d *Document) getDestination(v Value, pages map[string]int) Destination {
+	var dest Destination
+	switch name := v.(type) {
+	case NameValue, StringValue:
+		dest.Name, _ = toString(name)
+		if v = d.lookupDest(dest.Name); v == nil {
+			return dest
+		}
+	}
+	arr, _ := d.resolve(v).([]interface{})
+	if len(arr) == 0 {
+		return dest
+	}
+	switch page := arr[0].(type) {
+	case Reference:
+		dest.Page = pages[string(page)]
+	case int64:
+		dest.Page = int(page) + 1
+	}
+	if len(arr) > 1 {
+		dest.View = d.resolveString(arr[1])
+	}
+	for i := 2; i < len(arr); i++ {
+		switch v := d.resolve(arr[i]).(type) {
+		case int64, float64:
+			dest.Params = append(dest.Params, getNumber(v))
+		default:
+			dest.Params = append(dest.Params, math.NaN())
+		}
+	}
+	return dest
+}
+
+func (d *Document) getDests() map[string]Value {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dests == nil {
+		d.dests = make(map[string]Value)
+		root := d.getCatalog()
+		dests := d.getRawDests()
+		if dests == nil {
+			dests, _ = d.resolve(root.getValue("dests")).(Dict)
+		}
+		for k, v := range dests {
+			d.dests[k] = v
+		}
+		names, _ := d.resolve(root.getValue("names")).(Dict)
+		d.walkNameTree(names.getValue("dests"), func(name string, v Value) {
+			d.dests[name] = v
+		})
+	}
+	return d.dests
+}
+
+func (d *Document) lookupDest(name string) Value {
+	return d.resolveDest(d.getDests()[name])
+}
+
+// getRawDests reads the /Dests dictionary of the catalog again from the file
+// to keep its keys as written since the parser lowercases them.
+func (d *Document) getRawDests() map[string]Value {
+	p, ok := d.lookupOid(d.catalog)
+	if !ok {
+		return nil
+	}
+	raw, err := d.readRaw(p, false)
+	if err != nil {
+		return nil
+	}
+	var buf []byte
+	for k, v := range rawDictValues(raw.Value) {
+		if strings.EqualFold(k, "dests") {
+			buf = v
+		}
+	}
+	val, _ := parseValue(NewReader(buf), nil)
+	if oid, ok := toOid(val); ok {
+		if p, ok = d.lookupOid(oid); !ok {
+			return nil
+		}
+		if raw, err = d.readRaw(p, false); err != nil {
+			return nil
+		}
+		buf = raw.Value
+	}
+	var key *cryptKey
+	if !p.isEmbed() {
+		key = d.keyFor(p.Oid).derive(parseOid(p.Oid)).str
+	}
+	list := make(map[string]Value)
+	for k, v := range rawDictValues(buf) {
+		if val, err := parseValue(NewReader(v), key); err == nil {
+			list[k] = val
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// rawDictValues returns the bytes of the values of a dictionary by their keys
+// without changing their case.
+func rawDictValues(buf []byte) map[string][]byte {
+	r := NewReader(buf)
+	skipBlank(r)
+	if b, err := r.Peek(2); err != nil || string(b) != "<<" {
+		return nil
+	}
+	r.Discard(2)
+	list := make(map[string][]byte)
+	for {
+		skipBlank(r)
+		if b, err := r.Peek(1); err != nil || b[0] != slash {
+			break
+		}
+		name, err := parseName(r)
+		if err != nil {
+			break
+		}
+		start := r.Tell()
+		if _, err := parseValue(r, nil); err != nil {
+			break
+		}
+		list[name] = buf[start:r.Tell()]
+	}
+	return list
+}
+
+func (d *Document) resolveDest(v Value) Value {
+	if dict, ok := d.resolve(v).(Dict); ok {
+		return d.resolve(dict.getValue("d"))
+	}
+	return d.resolve(v)
+}
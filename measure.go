@@ -0,0 +1,123 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// FontWidths are keyed by code for simple fonts and by CID for composite fonts.
+type FontWidths struct {
+	Codes   map[uint32]float64
+	Default float64
+}
+
+func (d *Document) GetFontWidths(f Font) (FontWidths, error) {
+	tf, err := d.loadTextFont(f)
+	if err != nil {
+		return FontWidths{}, err
+	}
+	fw := FontWidths{
+		Codes:   make(map[uint32]float64, len(tf.widths)),
+		Default: tf.missing,
+	}
+	for c, w := range tf.widths {
+		fw.Codes[c] = w
+	}
+	return fw, nil
+}
+
+// MeasureString ignores character and word spacing and horizontal scaling.
+func (d *Document) MeasureString(f Font, size float64, text string) (float64, error) {
+	tf, err := d.loadTextFont(f)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		codes, longest = tf.reverseCodes()
+		width          float64
+	)
+	for len(text) > 0 {
+		var (
+			n    int
+			code uint32
+			ok   bool
+		)
+		for i := longest; i > 0 && !ok; i-- {
+			n = prefixLen(text, i)
+			code, ok = codes[text[:n]]
+		}
+		if ok {
+			width += tf.width(code)
+		} else {
+			_, n = utf8.DecodeRuneInString(text)
+			width += tf.missing
+		}
+		text = text[n:]
+	}
+	return width * size / 1000, nil
+}
+
+func (d *Document) loadTextFont(f Font) (*textFont, error) {
+	obj := d.getObjectWithOid(f.oid, false)
+	if obj.isZero() || !obj.IsFont() {
+		return nil, fmt.Errorf("font %s %w", f.Base, ErrMissing)
+	}
+	return d.getTextFont(obj), nil
+}
+
+// reverseCodes maps the text of the codes back to the codes.
+func (f *textFont) reverseCodes() (map[string]uint32, int) {
+	var (
+		codes   = make(map[string]uint32)
+		longest = 1
+		add     = func(str string, code uint32) {
+			if _, ok := codes[str]; ok || str == "" {
+				return
+			}
+			codes[str] = code
+			if n := utf8.RuneCountInString(str); n > longest {
+				longest = n
+			}
+		}
+	)
+	if f.cmap != nil {
+		var list []uint32
+		for c := range f.cmap.codes {
+			list = append(list, c)
+		}
+		for _, c := range sortCodes(list) {
+			add(f.cmap.codes[c], c)
+		}
+	}
+	var list []uint32
+	for c := range f.codes {
+		list = append(list, c)
+	}
+	for _, c := range sortCodes(list) {
+		add(string(f.codes[c]), c)
+	}
+	if f.size == 1 {
+		for c := uint32(0); c < 256; c++ {
+			add(string(rune(c)), c)
+		}
+	}
+	return codes, longest
+}
+
+func sortCodes(list []uint32) []uint32 {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i] < list[j]
+	})
+	return list
+}
+
+// prefixLen returns the length in bytes of the first n runes of str.
+func prefixLen(str string, n int) int {
+	var size int
+	for i := 0; i < n && size < len(str); i++ {
+		_, z := utf8.DecodeRuneInString(str[size:])
+		size += z
+	}
+	return size
+}
@@ -2,10 +2,7 @@ package pdf
 
 import (
 	"bytes"
-	"compress/zlib"
 	"fmt"
-	"image"
-	"image/jpeg"
 	"io"
 	"strconv"
 	"strings"
@@ -15,6 +12,8 @@ type Pointer struct {
 	Oid    string
 	Owner  string
 	Offset int64
+
+	free bool
 }
 
 func (p Pointer) isEmbed() bool {
@@ -24,8 +23,11 @@ func (p Pointer) isEmbed() bool {
 type Object struct {
 	Oid string
 	Dict
-	Data    Value
-	Content []byte
+	Data     Value
+	Content  []byte
+	Revision int
+
+	stream *stream
 }
 
 func (o Object) ObjectId() (int, int) {
@@ -46,7 +48,7 @@ func (o Object) IsFont() bool {
 }
 
 func (o Object) IsSignature() bool {
-	return o.isType("Sig")
+	return o.isType("Sig") || o.isType("DocTimeStamp")
 }
 
 func (o Object) IsPage() bool {
@@ -54,7 +56,10 @@ func (o Object) IsPage() bool {
 }
 
 func (o Object) IsImage() bool {
-	return o.isType("xobject") && o.GetString("subtype") == "Image"
+	if o.Has("type") && !o.isType("XObject") {
+		return false
+	}
+	return o.GetString("subtype") == "Image"
 }
 
 func (o Object) IsMeta() bool {
@@ -77,44 +82,17 @@ func (o Object) GetResources() Dict {
 }
 
 func (o Object) Body() ([]byte, error) {
-	var rs io.Reader
-	rs = bytes.NewReader(o.Content)
-	if o.IsFlate() {
-		z, err := zlib.NewReader(rs)
-		if err != nil {
-			return nil, err
-		}
-		defer z.Close()
-		rs = z
-	} else if o.IsLZW() {
-		// z := lzw.NewReader(rs)
-		// defer z.Close()
-		// rs = z
-	}
-	buf, err := io.ReadAll(rs)
+	rc, err := o.Reader()
 	if err != nil {
 		return nil, err
 	}
-	if !o.Has("decodeparms") {
-		return buf, err
-	}
-	var (
-		dict      = o.GetDict("decodeparms")
-		predictor = int(dict.GetInt("predictor"))
-		columns   = int(dict.GetInt("columns"))
-		filtered  []byte
-		row       = make([]byte, columns)
-	)
-	if predictor <= 1 {
-		return buf, nil
-	}
-	for i := 0; i < len(buf); i += columns + 1 {
-		for j := 0; j < columns; j++ {
-			row[j] = row[j] + buf[i+j+1]
-		}
-		filtered = append(filtered, row...)
-	}
-	return filtered, nil
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// BodyReader decodes the stream as it is read. The reader must be closed.
+func (o Object) BodyReader() (io.ReadCloser, error) {
+	return o.Reader()
 }
 
 func (o Object) isType(str string) bool {
@@ -139,7 +117,7 @@ func (o Object) GetEmbeddedObjects() []Object {
 	var (
 		first = o.GetInt("first")
 		count = int(o.GetInt("n"))
-		pairs = bytes.Split(bytes.TrimSpace(body[:first]), []byte{space})
+		pairs = bytes.Fields(body[:first])
 		list  = make([]Object, 0, count)
 		rs    = NewReader(body[first:])
 	)
@@ -161,42 +139,66 @@ func (o Object) GetEmbeddedObjects() []Object {
 }
 
 func (o Object) getEmbeddedObject(oid string, offset int64) Object {
+	buf, err := o.getEmbeddedBytes(oid, offset)
+	if err != nil {
+		return Object{}
+	}
+	return parseEmbeddedObject(oid, buf)
+}
+
+func parseEmbeddedObject(oid string, buf []byte) Object {
 	var obj Object
-	if !o.IsObjectStream() {
+	value, err := parseValue(NewReader(buf), nil)
+	if err != nil {
 		return obj
 	}
+	obj.Oid = oid
+	obj.Data = value
+	if dict, ok := value.(Dict); ok {
+		obj.Dict = dict
+	}
+	return obj
+}
+
+func (o Object) getEmbeddedBytes(oid string, offset int64) ([]byte, error) {
+	if !o.IsObjectStream() {
+		return nil, fmt.Errorf("%s: not an object stream", o.Oid)
+	}
 	body, err := o.Body()
 	if err != nil {
-		return obj
+		return nil, err
 	}
+	return o.embeddedBytes(body, oid, offset)
+}
+
+func (o Object) embeddedBytes(body []byte, oid string, offset int64) ([]byte, error) {
 	var (
 		first = o.GetInt("first")
 		count = o.GetInt("n")
-		pairs = bytes.Split(bytes.TrimSpace(body[:first]), []byte{space})
 	)
-	if offset < 0 || offset >= count {
-		return obj
+	if first > int64(len(body)) {
+		return nil, fmt.Errorf("%s: invalid offset of first object", o.Oid)
 	}
-	offset *= 2
-	if offset < 0 || offset >= int64(len(pairs)) {
-		return obj
+	pairs := bytes.Fields(body[:first])
+	if offset < 0 || offset >= count || 2*offset+1 >= int64(len(pairs)) {
+		return nil, fmt.Errorf("%s: object %w", oid, ErrMissing)
 	}
-	if oid != fmt.Sprintf("%s/0", pairs[offset]) {
-		return obj
+	if oid != fmt.Sprintf("%s/0", pairs[2*offset]) {
+		return nil, fmt.Errorf("%s: object %w", oid, ErrMissing)
 	}
-	offset, _ = strconv.ParseInt(string(pairs[offset+1]), 10, 64)
-	r := NewReader(body[first+offset:])
-
-	value, err := parseValue(r, nil)
-	if err != nil {
-		return obj
+	var (
+		beg, _ = strconv.ParseInt(string(pairs[2*offset+1]), 10, 64)
+		end    = int64(len(body))
+	)
+	if ix := 2*offset + 3; ix < int64(len(pairs)) {
+		end, _ = strconv.ParseInt(string(pairs[ix]), 10, 64)
+		end += first
 	}
-	obj.Oid = oid
-	obj.Data = value
-	if dict, ok := value.(Dict); ok {
-		obj.Dict = dict
+	beg += first
+	if beg > end || end > int64(len(body)) {
+		return nil, fmt.Errorf("%s: invalid object offset", oid)
 	}
-	return obj
+	return bytes.TrimSpace(body[beg:end]), nil
 }
 
 func (o Object) readXRef() ([]Pointer, error) {
@@ -214,29 +216,35 @@ func (o Object) readXRef() ([]Pointer, error) {
 	if len(ix) == 0 {
 		ix = append(ix, 0, o.GetInt("size"))
 	}
-	for j := 0; j < int(ix[1]) && !r.AtEOF(); j++ {
-		oid := ix[0] + int64(j)
-		for i := 0; i < len(ws); i++ {
-			xs[i] = r.ReadInt(ws[i])
-		}
-		var p Pointer
-		switch xs[0] {
-		case 1:
-			p.Oid = fmt.Sprintf("%d/%d", oid, xs[2])
-			p.Offset = xs[1]
-		case 2:
-			p.Oid = fmt.Sprintf("%d/0", oid)
-			p.Owner = fmt.Sprintf("%d/0", xs[1])
-			p.Offset = xs[2]
-		default:
-			continue
+	if len(ws) < 3 {
+		return nil, fmt.Errorf("xref stream: invalid field widths")
+	}
+	for k := 0; k+1 < len(ix); k += 2 {
+		for j := 0; j < int(ix[k+1]) && !r.AtEOF(); j++ {
+			oid := ix[k] + int64(j)
+			for i := 0; i < len(ws); i++ {
+				xs[i] = r.ReadInt(ws[i])
+			}
+			if ws[0] == 0 {
+				xs[0] = 1
+			}
+			var p Pointer
+			switch xs[0] {
+			case 0:
+				p.Oid = fmt.Sprintf("%d/%d", oid, xs[2])
+				p.free = true
+			case 1:
+				p.Oid = fmt.Sprintf("%d/%d", oid, xs[2])
+				p.Offset = xs[1]
+			case 2:
+				p.Oid = fmt.Sprintf("%d/0", oid)
+				p.Owner = fmt.Sprintf("%d/0", xs[1])
+				p.Offset = xs[2]
+			default:
+				continue
+			}
+			ps = append(ps, p)
 		}
-		ps = append(ps, p)
 	}
 	return ps, nil
 }
-
-func (o Object) readImage() image.Image {
-	img, _ := jpeg.Decode(bytes.NewReader(o.Content))
-	return img
-}
@@ -0,0 +1,280 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+)
+
+const pubsecFilter = "Adobe.PubSec"
+
+var ErrRecipientKey = errors.New("no matching recipient")
+
+var (
+	oidEnvelopedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 3}
+	oidDESEDE3CBC    = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128CBC     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+type recipientKey struct {
+	key  crypto.Decrypter
+	cert *x509.Certificate
+}
+
+func WithRecipientKey(key crypto.Decrypter, cert *x509.Certificate) Option {
+	return func(d *Document) error {
+		if key == nil {
+			return fmt.Errorf("recipient key %w", ErrMissing)
+		}
+		d.recipient = &recipientKey{
+			key:  key,
+			cert: cert,
+		}
+		return nil
+	}
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type keyTransRecipient struct {
+	Version      int
+	Recipient    asn1.RawValue
+	Algorithm    pkix.AlgorithmIdentifier
+	EncryptedKey []byte
+}
+
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type encryptedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Algorithm   pkix.AlgorithmIdentifier
+	Content     asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+func (d *Document) setupPubSec(obj Object) error {
+	var (
+		list     = obj.getRawStrings("recipients")
+		metadata = true
+		method   string
+		length   = obj.GetInt("length")
+	)
+	if obj.Has("encryptmetadata") {
+		metadata = obj.GetBool("encryptmetadata")
+	}
+	if obj.GetInt("v") >= 4 {
+		cf := obj.GetDict("cf").GetDict(strings.ToLower(d.stmf))
+		list = cf.getRawStrings("recipients")
+		if len(list) == 0 {
+			if str := string(cf.GetBytes("recipients")); str != "" {
+				list = append(list, str)
+			}
+		}
+		if cf.Has("encryptmetadata") {
+			metadata = cf.GetBool("encryptmetadata")
+		}
+		method = cf.GetString("cfm")
+		if n := cf.GetInt("length"); n > 0 && n <= 32 {
+			length = n * 8
+		}
+	}
+	d.metadata = metadata
+	if len(list) == 0 {
+		return fmt.Errorf("recipients %w", ErrMissing)
+	}
+	if d.recipient == nil {
+		return ErrRecipientKey
+	}
+	var seed []byte
+	for _, r := range list {
+		if s, err := d.recipient.open([]byte(r)); err == nil {
+			seed = s
+			break
+		}
+	}
+	if len(seed) < 20 {
+		return ErrRecipientKey
+	}
+	var sum []byte
+	if method == "AESV3" {
+		sum = pubsecDigest(sha256.New(), seed[:20], list, metadata)
+		length = 256
+	} else {
+		sum = pubsecDigest(sha1.New(), seed[:20], list, metadata)
+		if length == 0 {
+			length = 40
+			if obj.GetInt("v") >= 4 {
+				length = 128
+			}
+		}
+	}
+	if n := int(length / 8); n > 0 && n < len(sum) {
+		sum = sum[:n]
+	}
+	d.decrypt = sum
+	return nil
+}
+
+func pubsecDigest(h hash.Hash, seed []byte, list []string, metadata bool) []byte {
+	h.Write(seed)
+	for _, r := range list {
+		h.Write([]byte(r))
+	}
+	if !metadata {
+		h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+	return h.Sum(nil)
+}
+
+func (r *recipientKey) open(data []byte) ([]byte, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(data, &ci); err != nil {
+		return nil, err
+	}
+	if !ci.ContentType.Equal(oidEnvelopedData) {
+		return nil, fmt.Errorf("content type %s: %w", ci.ContentType, ErrUnsupported)
+	}
+	elems, err := asn1Elements(ci.Content.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) > 0 && elems[0].Tag == asn1.TagInteger {
+		elems = elems[1:]
+	}
+	if len(elems) > 0 && elems[0].Class == asn1.ClassContextSpecific {
+		elems = elems[1:]
+	}
+	if len(elems) < 2 {
+		return nil, fmt.Errorf("enveloped data %w", ErrMissing)
+	}
+	var eci encryptedContentInfo
+	if _, err := asn1.Unmarshal(elems[1].FullBytes, &eci); err != nil {
+		return nil, err
+	}
+	infos, err := asn1Elements(elems[0].FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	for _, ri := range infos {
+		var ktr keyTransRecipient
+		if _, err := asn1.Unmarshal(ri.FullBytes, &ktr); err != nil || !r.match(ktr.Recipient) {
+			continue
+		}
+		key, err := r.key.Decrypt(rand.Reader, ktr.EncryptedKey, nil)
+		if err != nil {
+			continue
+		}
+		return decryptContent(eci, key)
+	}
+	return nil, ErrRecipientKey
+}
+
+func (r *recipientKey) match(rid asn1.RawValue) bool {
+	if r.cert == nil {
+		return true
+	}
+	if rid.Class == asn1.ClassContextSpecific {
+		return bytes.Equal(rid.Bytes, r.cert.SubjectKeyId)
+	}
+	var is issuerAndSerial
+	if _, err := asn1.Unmarshal(rid.FullBytes, &is); err != nil {
+		return false
+	}
+	return bytes.Equal(is.Issuer.FullBytes, r.cert.RawIssuer) && is.Serial.Cmp(r.cert.SerialNumber) == 0
+}
+
+func decryptContent(eci encryptedContentInfo, key []byte) ([]byte, error) {
+	var (
+		block cipher.Block
+		err   error
+	)
+	switch alg := eci.Algorithm.Algorithm; {
+	case alg.Equal(oidDESEDE3CBC):
+		block, err = des.NewTripleDESCipher(key)
+	case alg.Equal(oidAES128CBC), alg.Equal(oidAES192CBC), alg.Equal(oidAES256CBC):
+		block, err = aes.NewCipher(key)
+	default:
+		return nil, fmt.Errorf("content algorithm %s: %w", alg, ErrUnsupported)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(eci.Algorithm.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	}
+	buf := eci.Content.Bytes
+	if eci.Content.IsCompound {
+		buf, err = joinOctets(buf)
+		if err != nil {
+			return nil, err
+		}
+	}
+	size := block.BlockSize()
+	if len(iv) != size || len(buf) == 0 || len(buf)%size != 0 {
+		return nil, fmt.Errorf("encrypted content: invalid size")
+	}
+	out := make([]byte, len(buf))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, buf)
+	if pad := int(out[len(out)-1]); pad > 0 && pad <= size {
+		out = out[:len(out)-pad]
+	}
+	return out, nil
+}
+
+func joinOctets(buf []byte) ([]byte, error) {
+	var out []byte
+	for len(buf) > 0 {
+		var (
+			str []byte
+			err error
+		)
+		if buf, err = asn1.Unmarshal(buf, &str); err != nil {
+			return nil, err
+		}
+		out = append(out, str...)
+	}
+	return out, nil
+}
+
+func asn1Elements(buf []byte) ([]asn1.RawValue, error) {
+	var seq asn1.RawValue
+	if _, err := asn1.Unmarshal(buf, &seq); err != nil {
+		return nil, err
+	}
+	var (
+		rest = seq.Bytes
+		list []asn1.RawValue
+	)
+	for len(rest) > 0 {
+		var (
+			v   asn1.RawValue
+			err error
+		)
+		if rest, err = asn1.Unmarshal(rest, &v); err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
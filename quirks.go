@@ -0,0 +1,148 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Quirk uint32
+
+const (
+	QuirkXRefOffset Quirk = 1 << iota
+	QuirkStreamLength
+	QuirkDateFormat
+)
+
+func (q Quirk) Has(other Quirk) bool {
+	return q&other == other
+}
+
+type quirkRule struct {
+	Match string
+	Quirk Quirk
+}
+
+var (
+	quirkMu    sync.RWMutex
+	quirkRules = []quirkRule{
+		{Match: "libreoffice", Quirk: QuirkXRefOffset},
+		{Match: "openoffice", Quirk: QuirkXRefOffset},
+		{Match: "pdfsharp", Quirk: QuirkStreamLength},
+		{Match: "quartz pdfcontext", Quirk: QuirkDateFormat},
+		{Match: "microsoft: print to pdf", Quirk: QuirkDateFormat},
+	}
+)
+
+func RegisterQuirk(match string, q Quirk) {
+	quirkMu.Lock()
+	defer quirkMu.Unlock()
+	quirkRules = append(quirkRules, quirkRule{
+		Match: strings.ToLower(match),
+		Quirk: q,
+	})
+}
+
+func lookupQuirks(producer, creator string) Quirk {
+	quirkMu.RLock()
+	defer quirkMu.RUnlock()
+
+	var (
+		q   Quirk
+		all = []string{strings.ToLower(producer), strings.ToLower(creator)}
+	)
+	for _, r := range quirkRules {
+		for _, str := range all {
+			if str != "" && strings.Contains(str, r.Match) {
+				q |= r.Quirk
+			}
+		}
+	}
+	return q
+}
+
+func (d *Document) Quirks() Quirk {
+	return d.quirks
+}
+
+func (d *Document) detectQuirks() {
+	obj := d.getObjectWithOid(d.info, false)
+	if obj.isZero() {
+		return
+	}
+	d.quirks = lookupQuirks(obj.GetString("producer"), obj.GetString("creator"))
+}
+
+func (d *Document) searchObject(oid string, offset int64) (Object, error) {
+	const window = MinRead
+	id, rev := parseOid(oid)
+	start := offset - window
+	if start < 0 {
+		start = 0
+	}
+	r, err := d.section(start, 2*window)
+	if err != nil {
+		return Object{}, err
+	}
+	var (
+		buf  = r.Bytes()
+		head = []byte(fmt.Sprintf("%d %d obj", id, rev))
+		best = int64(-1)
+	)
+	for i := 0; i < len(buf); {
+		x := bytes.Index(buf[i:], head)
+		if x < 0 {
+			break
+		}
+		i += x
+		if i == 0 || !isDigit(buf[i-1]) {
+			pos := start + int64(i)
+			if best < 0 || abs(pos-offset) < abs(best-offset) {
+				best = pos
+			}
+		}
+		i += len(head)
+	}
+	if best < 0 {
+		return Object{}, fmt.Errorf("%s: object %w near offset %d", oid, ErrMissing, offset)
+	}
+	return d.readObjectAt(best, d.keyFor(oid), true)
+}
+
+var quirkTimePatterns = []string{
+	"D:20060102150405Z0700",
+	"D:200601021504Z0700",
+	"D:200601021504",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"Mon Jan _2 15:04:05 2006",
+	"1/2/2006 15:04:05",
+}
+
+func (d *Document) parseDate(str string) (time.Time, error) {
+	if strings.HasPrefix(str, "D:") {
+		when, err := parseTime(str)
+		if err == nil || !d.quirks.Has(QuirkDateFormat) {
+			return when, err
+		}
+	}
+	if !d.quirks.Has(QuirkDateFormat) {
+		return time.Time{}, fmt.Errorf("%s: invalid date", str)
+	}
+	str = strings.ReplaceAll(strings.TrimSpace(str), "'", "")
+	for _, pat := range quirkTimePatterns {
+		if when, err := time.Parse(pat, str); err == nil {
+			return when, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s: invalid date", str)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,209 @@
+package pdf
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+const subScanlines = 4
+
+type subpath struct {
+	points []Point
+	closed bool
+}
+
+type rasterEdge struct {
+	x0, y0 float64
+	x1, y1 float64
+	dir    int
+}
+
+type rasterCrossing struct {
+	x   float64
+	dir int
+}
+
+func fillPath(paths []subpath, evenOdd bool, clip image.Rectangle, fn func(x, y int, a float64)) {
+	var (
+		edges []rasterEdge
+		area  = image.Rectangle{
+			Min: image.Pt(math.MaxInt32, math.MaxInt32),
+			Max: image.Pt(math.MinInt32, math.MinInt32),
+		}
+	)
+	for _, p := range paths {
+		for i := range p.points {
+			var (
+				a = p.points[i]
+				b = p.points[(i+1)%len(p.points)]
+			)
+			area.Min.X = minInt(area.Min.X, int(math.Floor(a.X)))
+			area.Min.Y = minInt(area.Min.Y, int(math.Floor(a.Y)))
+			area.Max.X = maxInt(area.Max.X, int(math.Ceil(a.X))+1)
+			area.Max.Y = maxInt(area.Max.Y, int(math.Ceil(a.Y))+1)
+			switch {
+			case a.Y < b.Y:
+				edges = append(edges, rasterEdge{x0: a.X, y0: a.Y, x1: b.X, y1: b.Y, dir: 1})
+			case a.Y > b.Y:
+				edges = append(edges, rasterEdge{x0: b.X, y0: b.Y, x1: a.X, y1: a.Y, dir: -1})
+			}
+		}
+	}
+	area = area.Intersect(clip)
+	if len(edges) == 0 || area.Empty() {
+		return
+	}
+	var (
+		cover  = make([]float64, area.Dx()+1)
+		cross  []rasterCrossing
+		weight = 1.0 / subScanlines
+	)
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for i := range cover {
+			cover[i] = 0
+		}
+		for s := 0; s < subScanlines; s++ {
+			sy := float64(y) + (float64(s)+0.5)/subScanlines
+			cross = cross[:0]
+			for _, e := range edges {
+				if sy < e.y0 || sy >= e.y1 {
+					continue
+				}
+				x := e.x0 + (sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+				cross = append(cross, rasterCrossing{x: x, dir: e.dir})
+			}
+			sort.Slice(cross, func(i, j int) bool { return cross[i].x < cross[j].x })
+			var wind int
+			for i := 0; i+1 < len(cross); i++ {
+				wind += cross[i].dir
+				inside := wind != 0
+				if evenOdd {
+					inside = wind%2 != 0
+				}
+				if inside {
+					addSpan(cover, cross[i].x-float64(area.Min.X), cross[i+1].x-float64(area.Min.X), weight)
+				}
+			}
+		}
+		for i, a := range cover[:area.Dx()] {
+			if a > 0 {
+				fn(area.Min.X+i, y, math.Min(a, 1))
+			}
+		}
+	}
+}
+
+func addSpan(cover []float64, x0, x1, w float64) {
+	x0 = math.Max(x0, 0)
+	x1 = math.Min(x1, float64(len(cover)-1))
+	if x1 <= x0 {
+		return
+	}
+	i0, i1 := int(x0), int(x1)
+	if i0 == i1 {
+		cover[i0] += (x1 - x0) * w
+		return
+	}
+	cover[i0] += (float64(i0+1) - x0) * w
+	for i := i0 + 1; i < i1; i++ {
+		cover[i] += w
+	}
+	cover[i1] += (x1 - float64(i1)) * w
+}
+
+func strokePath(paths []subpath, width float64) []subpath {
+	var (
+		list []subpath
+		half = math.Max(width, 1) / 2
+	)
+	for _, p := range paths {
+		n := len(p.points)
+		if n == 0 {
+			continue
+		}
+		segs := n - 1
+		if p.closed {
+			segs = n
+		}
+		for i := 0; i < segs; i++ {
+			var (
+				a  = p.points[i]
+				b  = p.points[(i+1)%n]
+				dx = b.X - a.X
+				dy = b.Y - a.Y
+				d  = math.Hypot(dx, dy)
+			)
+			if d == 0 {
+				continue
+			}
+			nx, ny := -dy/d*half, dx/d*half
+			list = append(list, subpath{points: []Point{
+				{X: a.X + nx, Y: a.Y + ny},
+				{X: b.X + nx, Y: b.Y + ny},
+				{X: b.X - nx, Y: b.Y - ny},
+				{X: a.X - nx, Y: a.Y - ny},
+			}})
+		}
+		if half <= 1 {
+			continue
+		}
+		for i, pt := range p.points {
+			if !p.closed && (i == 0 || i == n-1) {
+				continue
+			}
+			list = append(list, joinPolygon(pt, half))
+		}
+	}
+	return list
+}
+
+func joinPolygon(pt Point, radius float64) subpath {
+	const sides = 8
+	points := make([]Point, sides)
+	for i := range points {
+		angle := -2 * math.Pi * float64(i) / sides
+		points[i] = Point{X: pt.X + radius*math.Cos(angle), Y: pt.Y + radius*math.Sin(angle)}
+	}
+	return subpath{points: points, closed: true}
+}
+
+func flattenCurve(p0, p1, p2, p3 Point) []Point {
+	var (
+		size = math.Hypot(p1.X-p0.X, p1.Y-p0.Y) + math.Hypot(p2.X-p1.X, p2.Y-p1.Y) + math.Hypot(p3.X-p2.X, p3.Y-p2.Y)
+		n    = int(size/2) + 1
+	)
+	if n > 100 {
+		n = 100
+	}
+	list := make([]Point, 0, n)
+	for i := 1; i <= n; i++ {
+		var (
+			t  = float64(i) / float64(n)
+			u  = 1 - t
+			a  = u * u * u
+			b  = 3 * u * u * t
+			c  = 3 * u * t * t
+			dt = t * t * t
+		)
+		list = append(list, Point{
+			X: a*p0.X + b*p1.X + c*p2.X + dt*p3.X,
+			Y: a*p0.Y + b*p1.Y + c*p2.Y + dt*p3.Y,
+		})
+	}
+	return list
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
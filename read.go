@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"sort"
 	"strconv"
 )
@@ -29,108 +28,413 @@ var (
 
 const MinRead = 1024
 
-func readFile(file string) (*Document, error) {
-	buf, err := os.ReadFile(file)
+func readFile(file string, opts ...Option) (*Document, error) {
+	r, err := openFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("read file", err)
+		return nil, fmt.Errorf("read file: %w", err)
 	}
-	var (
-		doc Document
-		rs  = NewReader(buf)
-	)
+	doc, err := readDocument(r, r.Size(), opts...)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	doc.closer = r
+	return doc, nil
+}
 
-	linearized, err := readPreamble(rs.Section(0, MinRead))
+func readDocument(src io.ReaderAt, size int64, opts ...Option) (*Document, error) {
+	doc := Document{
+		src:       src,
+		size:      size,
+		cacheSize: DefaultCacheSize,
+	}
+	for _, o := range opts {
+		if err := o(&doc); err != nil {
+			return nil, err
+		}
+	}
+	if doc.lenient() {
+		doc.skipJunk()
+	}
+	rs, err := doc.section(0, MinRead)
 	if err != nil {
+		return nil, err
+	}
+	lin, offset, err := readPreamble(rs)
+	if err != nil {
+		if doc.strict() {
+			return nil, &ParseError{Reason: err.Error()}
+		}
 		return nil, fmt.Errorf("read preamble: %s", err)
 	}
-	if linearized == 0 {
-		err = readClassic(rs, &doc)
-	} else {
-		rs.Seek(linearized, io.SeekStart)
-		err = readLinearized(rs, &doc)
+	switch {
+	case doc.recovery:
+		err = readSalvage(&doc)
+	case lin == nil:
+		err = readClassic(&doc)
+	default:
+		doc.linear = lin
+		err = readLinearized(&doc, offset)
+	}
+	if err != nil && !doc.recovery && !doc.strict() {
+		if e := readSalvage(&doc); e == nil {
+			err = nil
+		}
 	}
 	if err != nil {
+		if doc.strict() && !errors.Is(err, ErrMalformed) {
+			return nil, &ParseError{Offset: doc.size, Reason: err.Error()}
+		}
 		return nil, err
 	}
+	if doc.strict() {
+		for doc.loadPending() {
+		}
+		if err := doc.validate(); err != nil {
+			return nil, err
+		}
+	}
 	sort.Slice(doc.xref, func(i, j int) bool {
 		return doc.xref[i].Oid > doc.xref[j].Oid
 	})
-	doc.inner = rs
-	return &doc, doc.setupKey()
+	if err := doc.setupKey(); err != nil && !(doc.locked && isLocked(err)) {
+		return nil, err
+	}
+	doc.detectQuirks()
+	doc.cache = newObjectCache(doc.cacheSize)
+	return &doc, nil
+}
+
+func isLocked(err error) bool {
+	return errors.Is(err, ErrPasswordRequired) || errors.Is(err, ErrInvalidPassword) || errors.Is(err, ErrRecipientKey)
+}
+
+func (d *Document) section(offset, size int64) (*Reader, error) {
+	if offset < 0 || offset > d.size {
+		return nil, fmt.Errorf("offset %d outside of file", offset)
+	}
+	if offset+size > d.size {
+		size = d.size - offset
+	}
+	if r, ok := d.src.(*Reader); ok {
+		return r.Section(offset, size), nil
+	}
+	buf := make([]byte, size)
+	n, err := d.src.ReadAt(buf, offset)
+	if err != nil && !(errors.Is(err, io.EOF) && int64(n) == size) {
+		return nil, err
+	}
+	return NewReader(buf[:n]), nil
+}
+
+func (d *Document) readWindow(offset int64, fn func(*Reader) error) error {
+	size := int64(MinRead * 4)
+	if _, ok := d.src.(*Reader); ok {
+		size = d.size - offset
+	}
+	for {
+		r, err := d.section(offset, size)
+		if err != nil {
+			return err
+		}
+		if err = fn(r); err == nil || offset+size >= d.size || !r.truncated(err) {
+			return err
+		}
+		size *= 2
+	}
+}
+
+func (d *Document) readObjectAt(offset int64, keys cryptKeys, full bool) (Object, error) {
+	var obj Object
+	err := d.readWindow(offset, func(r *Reader) error {
+		var err error
+		obj, err = readObjectWith(r, keys, readOptions{
+			full:    full,
+			scan:    d.quirks.Has(QuirkStreamLength) || d.lenient(),
+			lenient: d.lenient(),
+			src:     d.src,
+			base:    offset,
+			key:     d.streamKey,
+		})
+		return err
+	})
+	if err == nil && obj.stream != nil {
+		if oid := obj.GetString("length"); isOid(oid) {
+			n := d.getObjectWithOid(oid, false)
+			obj.stream.length, _ = n.Data.(int64)
+		}
+	}
+	return obj, err
 }
 
-func readClassic(rs *Reader, doc *Document) error {
-	size := rs.Size()
+func readClassic(doc *Document) error {
+	if offset, err := doc.lastStartXRef(); err == nil && offset > 0 {
+		if err = doc.readChain(offset); err == nil {
+			return nil
+		}
+	}
+	size := doc.size
 	if size > MinRead {
 		size = MinRead
 	}
-	offset, err := readTrailer(rs.Section(rs.Size()-size, size), doc)
+	rs, err := doc.section(doc.size-size, size)
+	if err != nil {
+		return err
+	}
+	offset, err := readTrailer(rs, doc)
 	switch {
 	case err == nil:
 	case errors.Is(err, ErrTrailer):
-		rs.Seek(offset, io.SeekStart)
-		return readLinearized(rs, doc)
+		return doc.readChain(offset)
 	default:
 		return fmt.Errorf("read trailer: %s", err)
 	}
-
-	if doc.xref, err = readXRef(rs.Section(offset, rs.Size()-offset)); err != nil {
+	err = doc.readWindow(offset, func(r *Reader) error {
+		var list []Pointer
+		list, err = readXRef(r)
+		doc.xref, doc.free = splitXRef(list)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("read xref: %s", err)
 	}
 	return nil
 }
 
-func readLinearized(rs *Reader, doc *Document) error {
-	obj, err := readObject(rs, nil, true)
+// readChain reads the cross reference section found at offset and all the
+// sections reachable through the /Prev keys of their trailers. When an object
+// is found in more than one section, the entry of the newest one is kept.
+func (d *Document) readChain(offset int64) error {
+	var (
+		seen = make(map[int64]bool)
+		list []Pointer
+	)
+	for i := 0; offset > 0 && !seen[offset]; i++ {
+		seen[offset] = true
+		dict, entries, err := d.readXRefAt(offset)
+		if err != nil {
+			if i == 0 {
+				return err
+			}
+			break
+		}
+		if i == 0 {
+			d.setTrailer(dict)
+		} else {
+			d.mergeTrailer(dict)
+		}
+		list = mergeXRef(list, d.mergeXRefStm(dict, entries))
+		offset = dict.GetInt("prev")
+	}
+	d.xref, d.free = splitXRef(list)
+	return nil
+}
+
+// mergeXRefStm adds to the entries of a classic cross reference table the
+// ones of the stream referenced by the /XRefStm key of its trailer (hybrid
+// files). Entries of the stream replace the free entries of the table.
+func (d *Document) mergeXRefStm(dict Dict, list []Pointer) []Pointer {
+	offset := dict.GetInt("xrefstm")
+	if offset <= 0 {
+		return list
+	}
+	_, more, err := d.readXRefAt(offset)
 	if err != nil {
-		return fmt.Errorf("read object: %s", err)
+		return list
+	}
+	index := make(map[int]int)
+	for i, p := range list {
+		index[objectNumber(p.Oid)] = i
+	}
+	for _, p := range more {
+		i, ok := index[objectNumber(p.Oid)]
+		switch {
+		case !ok:
+			index[objectNumber(p.Oid)] = len(list)
+			list = append(list, p)
+		case list[i].free && !p.free:
+			list[i] = p
+		}
 	}
-	doc.encrypt = obj.GetString("encrypt")
-	doc.catalog = obj.GetString("root")
-	doc.info = obj.GetString("info")
-	doc.fileid = obj.GetStringArray("id")
+	return list
+}
+
+// mergeXRef adds to list the entries of an older section. Only the newest
+// entry of an object number is kept, including free entries so that objects
+// deleted by an update are not resolved from an older section.
+func mergeXRef(list, more []Pointer) []Pointer {
+	seen := make(map[int]bool)
+	for _, p := range list {
+		seen[objectNumber(p.Oid)] = true
+	}
+	for _, p := range more {
+		if n := objectNumber(p.Oid); !seen[n] {
+			list = append(list, p)
+			seen[n] = true
+		}
+	}
+	return list
+}
 
-	doc.xref, err = obj.readXRef()
+func splitXRef(list []Pointer) ([]Pointer, []Pointer) {
+	var live, free []Pointer
+	for _, p := range list {
+		if p.free {
+			free = append(free, p)
+		} else {
+			live = append(live, p)
+		}
+	}
+	return live, free
+}
+
+func readLinearized(doc *Document, offset int64) error {
+	if doc.linear.Length != doc.size {
+		// the file has been updated since it was linearized: the hint
+		// tables are no longer reliable and the last update has to be read
+		// first.
+		if last, err := doc.lastStartXRef(); err == nil && last > 0 {
+			if err := doc.readChain(last); err == nil {
+				return nil
+			}
+		}
+	}
+	dict, list, err := doc.readXRefAt(offset)
 	if err != nil {
 		return err
 	}
-	if offset := obj.GetInt("prev"); offset > 0 {
-		rs.Seek(offset, io.SeekStart)
-		if obj, err = readObject(rs, nil, true); err != nil {
+	doc.setTrailer(dict)
+	doc.xref, doc.free = splitXRef(doc.mergeXRefStm(dict, list))
+	doc.prev = dict.GetInt("prev")
+	return nil
+}
+
+func (d *Document) readXRefAt(offset int64) (Dict, []Pointer, error) {
+	var (
+		dict Dict
+		list []Pointer
+	)
+	err := d.readWindow(offset, func(r *Reader) error {
+		r.Skip()
+		if !r.StartsWith(ref) {
+			obj, err := readObject(r, cryptKeys{}, true)
+			if err != nil {
+				return fmt.Errorf("read object: %s", err)
+			}
+			if !obj.IsXRef() {
+				return fmt.Errorf("xref %w", ErrMissing)
+			}
+			dict = obj.Dict
+			list, err = obj.readXRef()
 			return err
 		}
-		others, err := obj.readXRef()
-		if err != nil {
+		var err error
+		if list, err = readXRef(r); err != nil {
 			return err
 		}
-		doc.xref = append(doc.xref, others...)
-		doc.encrypt = obj.GetString("encrypt")
-		doc.catalog = obj.GetString("root")
-		doc.info = obj.GetString("info")
-		doc.fileid = obj.GetStringArray("id")
+		r.Skip()
+		if !r.StartsWith(trailer) {
+			return fmt.Errorf("%s %w", trailer, ErrMissing)
+		}
+		r.Discard(len(trailer))
+		dict, err = parseValueAsDict(r, nil)
+		return err
+	})
+	return dict, list, err
+}
+
+// loadPending reads the next section of the cross reference not loaded yet
+// when the document is opened (linearized files) and merges its entries with
+// the ones already known.
+func (d *Document) loadPending() bool {
+	d.xmu.Lock()
+	defer d.xmu.Unlock()
+	if d.prev <= 0 || d.loaded[d.prev] {
+		return false
+	}
+	offset := d.prev
+	d.prev = 0
+	if d.loaded == nil {
+		d.loaded = make(map[int64]bool)
+	}
+	d.loaded[offset] = true
+
+	dict, list, err := d.readXRefAt(offset)
+	if err != nil {
+		return false
+	}
+	d.mergeTrailer(dict)
+	var (
+		known = append(append([]Pointer{}, d.xref...), d.free...)
+		xref  []Pointer
+	)
+	xref, d.free = splitXRef(mergeXRef(known, d.mergeXRefStm(dict, list)))
+	sort.Slice(xref, func(i, j int) bool {
+		return xref[i].Oid > xref[j].Oid
+	})
+	d.xref = xref
+	d.prev = dict.GetInt("prev")
+	return true
+}
+
+func (d *Document) setTrailer(dict Dict) {
+	d.trailer = dict
+	if str := dict.GetString("encrypt"); str != "" {
+		d.encrypt = str
+	}
+	if str := dict.GetString("root"); str != "" {
+		d.catalog = str
+	}
+	if str := dict.GetString("info"); str != "" {
+		d.info = str
+	}
+	if ids := dict.getRawStrings("id"); len(ids) > 0 {
+		d.fileid = ids
 	}
-	return nil
 }
 
-func readVersion(r *Reader) []byte {
-	r.Seek(0, io.SeekStart)
+// mergeTrailer sets the entries of the trailer of an older section that are
+// not yet known.
+func (d *Document) mergeTrailer(dict Dict) {
+	if d.trailer == nil {
+		d.trailer = dict
+	}
+	if d.encrypt == "" {
+		d.encrypt = dict.GetString("encrypt")
+	}
+	if d.catalog == "" {
+		d.catalog = dict.GetString("root")
+	}
+	if d.info == "" {
+		d.info = dict.GetString("info")
+	}
+	if len(d.fileid) == 0 {
+		d.fileid = dict.getRawStrings("id")
+	}
+}
+
+func readVersion(d *Document) []byte {
+	r, err := d.section(0, MinRead)
+	if err != nil {
+		return nil
+	}
 	line, _ := r.ReadLine()
 	return bytes.TrimSpace(line)
 }
 
-func readPreamble(r *Reader) (int64, error) {
+func readPreamble(r *Reader) (*linearization, int64, error) {
 	if !r.StartsWith(magic) {
-		return 0, fmt.Errorf("invalid pdf header! expected %s", magic)
+		return nil, 0, fmt.Errorf("invalid pdf header! expected %s", magic)
 	}
 	r.Discard(len(magic))
 	switch b, _ := r.ReadByte(); b {
 	case '0', '1', '2', '3', '4', '5', '6', '7':
 	default:
-		return 0, fmt.Errorf("invalid pdf version 1.%c", b)
+		return nil, 0, fmt.Errorf("invalid pdf version 1.%c", b)
 	}
 	r.Skip()
 	if _, err := r.ReadLine(); err != nil {
-		return 0, err
+		return nil, 0, err
 	}
 	for {
 		if b, _ := r.ReadByte(); b == percent {
@@ -140,14 +444,27 @@ func readPreamble(r *Reader) (int64, error) {
 			break
 		}
 	}
-	obj, err := readObject(r, nil, false)
+	obj, err := readObject(r, cryptKeys{}, false)
 	if err == nil && !obj.isZero() && obj.Linearized() {
-		return r.Tell(), nil
+		return readLinearization(obj), r.Tell(), nil
 	}
-	return 0, err
+	return nil, 0, nil
 }
 
-func readObject(r *Reader, key []byte, full bool) (Object, error) {
+type readOptions struct {
+	full    bool
+	scan    bool
+	lenient bool
+	src     io.ReaderAt
+	base    int64
+	key     func(Object, *cryptKey) *cryptKey
+}
+
+func readObject(r *Reader, keys cryptKeys, full bool) (Object, error) {
+	return readObjectWith(r, keys, readOptions{full: full})
+}
+
+func readObjectWith(r *Reader, keys cryptKeys, opts readOptions) (Object, error) {
 	r.Skip()
 	var (
 		oid int
@@ -158,15 +475,19 @@ func readObject(r *Reader, key []byte, full bool) (Object, error) {
 	if _, err := fmt.Fscanf(r, "%d %d %s", &oid, &rev, &typ); err != nil {
 		return obj, fmt.Errorf("fail to scan object header: %w", err)
 	}
+	if opts.lenient && len(typ) > len(begobj) && bytes.HasPrefix([]byte(typ), begobj) {
+		if x := bytes.LastIndex(r.buf[:r.Tell()], []byte(typ)); x >= 0 {
+			r.Seek(int64(x+len(begobj)), io.SeekStart)
+			typ = typ[:len(begobj)]
+		}
+	}
 	if !bytes.Equal([]byte(typ), begobj) {
 		return obj, fmt.Errorf("object keyword %w", ErrMissing)
 	}
-	if len(key) > 0 {
-		key = getEncryptionKey(key, oid, rev)
-	}
+	keys = keys.derive(oid, rev)
 	obj.Oid = fmt.Sprintf("%d/%d", oid, rev)
 
-	val, err := r.ReadValue(key)
+	val, err := r.ReadValue(keys.str)
 	if err != nil {
 		return obj, err
 	}
@@ -182,14 +503,29 @@ func readObject(r *Reader, key []byte, full bool) (Object, error) {
 	switch line, _ := r.ReadLine(); {
 	case bytes.Equal(line, endobj):
 	case bytes.Equal(line, begstream):
-		if !full {
+		if !opts.full {
 			break
 		}
-		tmp := make([]byte, obj.GetInt("length"))
+		if opts.key != nil {
+			keys.stm = opts.key(obj, keys.stm)
+		}
+		size := obj.GetInt("length")
+		if opts.scan {
+			size = scanStreamLength(r, size)
+		} else if opts.src != nil && size >= 0 {
+			obj.stream = &stream{
+				src:    opts.src,
+				offset: opts.base + r.Tell(),
+				length: size,
+				key:    keys.stm,
+			}
+			break
+		}
+		tmp := make([]byte, size)
 		if _, err := io.ReadFull(r, tmp); err != nil {
 			return obj, err
 		}
-		obj.Content = decryptBytes(key, tmp)
+		obj.Content = decryptBytes(keys.stm, tmp)
 		if line, _ = r.ReadLine(); !bytes.Equal(line, endstream) {
 			return obj, fmt.Errorf("%s %w", endstream, ErrMissing)
 		}
@@ -202,39 +538,63 @@ func readObject(r *Reader, key []byte, full bool) (Object, error) {
 	return obj, nil
 }
 
+func scanStreamLength(r *Reader, size int64) int64 {
+	buf := r.Bytes()
+	if size >= 0 && size <= int64(len(buf)) {
+		rest := bytes.TrimLeft(buf[size:], "\r\n")
+		if bytes.HasPrefix(rest, endstream) {
+			return size
+		}
+	}
+	x := bytes.Index(buf, endstream)
+	if x < 0 {
+		return size
+	}
+	end := x
+	if end > 0 && buf[end-1] == nl {
+		end--
+	}
+	if end > 0 && buf[end-1] == cr {
+		end--
+	}
+	return int64(end)
+}
+
 func readXRef(r *Reader) ([]Pointer, error) {
 	if !r.StartsWith(ref) {
 		return nil, fmt.Errorf("xref %w", ErrMissing)
 	}
 	r.Discard(len(ref))
 	r.Skip()
-	var (
-		first int
-		num   int
-	)
-	_, err := fmt.Fscanf(r, "%d %d", &first, &num)
-	if err != nil {
-		return nil, err
-	}
-	r.Skip()
-	ps := make([]Pointer, 0, num)
-	for i := 0; i < num; i++ {
+
+	var ps []Pointer
+	for !r.AtEOF() && !r.StartsWith(trailer) {
 		var (
-			off int
-			rev int
-			typ string
+			first int
+			num   int
 		)
-		if _, err = fmt.Fscanf(r, "%d %d %s\r\n", &off, &rev, &typ); err != nil {
+		_, err := fmt.Fscanf(r, "%d %d", &first, &num)
+		if err != nil {
 			return nil, err
 		}
-		if typ == "f" {
-			continue
-		}
-		p := Pointer{
-			Oid:    fmt.Sprintf("%d/%d", first+i, rev),
-			Offset: int64(off),
+		r.Skip()
+		for i := 0; i < num; i++ {
+			var (
+				off int
+				rev int
+				typ string
+			)
+			if _, err = fmt.Fscanf(r, "%d %d %s", &off, &rev, &typ); err != nil {
+				return nil, err
+			}
+			r.Skip()
+			p := Pointer{
+				Oid:    fmt.Sprintf("%d/%d", first+i, rev),
+				Offset: int64(off),
+				free:   typ == "f",
+			}
+			ps = append(ps, p)
 		}
-		ps = append(ps, p)
 	}
 	return ps, nil
 }
@@ -260,10 +620,11 @@ func readTrailer(r *Reader, doc *Document) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+	doc.trailer = dict
 	doc.encrypt = dict.GetString("encrypt")
 	doc.catalog = dict.GetString("root")
 	doc.info = dict.GetString("info")
-	doc.fileid = dict.GetStringArray("id")
+	doc.fileid = dict.getRawStrings("id")
 
 	r.Skip()
 
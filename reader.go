@@ -2,6 +2,7 @@ package pdf
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 )
@@ -9,6 +10,9 @@ import (
 type Reader struct {
 	buf []byte
 	ptr int
+	// past is set once a read needed more bytes than the buffer has
+	past bool
+	eof  bool
 }
 
 func NewReader(b []byte) *Reader {
@@ -30,6 +34,11 @@ func (r *Reader) AtEOF() bool {
 	return r.ptr >= len(r.buf)
 }
 
+// truncated tells whether err comes from reaching the end of the buffer.
+func (r *Reader) truncated(err error) bool {
+	return r.past || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
 func (r *Reader) Section(offset, size int64) *Reader {
 	return NewReader(r.buf[offset : offset+size])
 }
@@ -86,9 +95,14 @@ func (r *Reader) Skip() {
 
 func (r *Reader) StartsWith(b []byte) bool {
 	if r.ptr >= len(r.buf) {
+		r.past = true
 		return false
 	}
-	return bytes.HasPrefix(r.buf[r.ptr:], b)
+	rest := r.buf[r.ptr:]
+	if len(rest) < len(b) && bytes.HasPrefix(b, rest) {
+		r.past = true
+	}
+	return bytes.HasPrefix(rest, b)
 }
 
 func (r *Reader) EndsWith(b []byte) bool {
@@ -100,10 +114,12 @@ func (r *Reader) EndsWith(b []byte) bool {
 
 func (r *Reader) Peek(n int) ([]byte, error) {
 	if r.ptr > len(r.buf) {
+		r.past = true
 		return nil, io.EOF
 	}
 	if end := r.ptr + n; end > len(r.buf) {
 		n = len(r.buf) - r.ptr
+		r.past = true
 	}
 	buf := make([]byte, n)
 	copy(buf, r.buf[r.ptr:])
@@ -112,9 +128,13 @@ func (r *Reader) Peek(n int) ([]byte, error) {
 
 func (r *Reader) Discard(n int) (int, error) {
 	if r.ptr >= len(r.buf) {
+		r.past = true
 		return 0, io.EOF
 	}
 	r.ptr += n
+	if r.ptr > len(r.buf) {
+		r.past = true
+	}
 	if r.ptr >= len(r.buf) {
 		n = r.ptr - len(r.buf)
 		r.ptr = len(r.buf)
@@ -124,6 +144,7 @@ func (r *Reader) Discard(n int) (int, error) {
 
 func (r *Reader) Read(b []byte) (int, error) {
 	if r.ptr >= len(r.buf) {
+		r.past = true
 		return 0, io.EOF
 	}
 	n := copy(b, r.buf[r.ptr:])
@@ -139,7 +160,7 @@ func (r *Reader) ReadAt(b []byte, offset int64) (n int, err error) {
 		return 0, io.EOF
 	}
 	n = copy(b, r.buf[offset:])
-	if n < len(r.buf) {
+	if n < len(b) {
 		err = io.EOF
 	}
 	return n, err
@@ -178,12 +199,14 @@ func (r *Reader) ReadInt(n int64) int64 {
 	return z
 }
 
-func (r *Reader) ReadValue(key []byte) (Value, error) {
+func (r *Reader) ReadValue(key *cryptKey) (Value, error) {
 	return parseValue(r, key)
 }
 
 func (r *Reader) ReadByte() (byte, error) {
-	if r.ptr >= len(r.buf) {
+	r.eof = r.ptr >= len(r.buf)
+	if r.eof {
+		r.past = true
 		return 0, io.EOF
 	}
 	b := r.buf[r.ptr]
@@ -192,7 +215,8 @@ func (r *Reader) ReadByte() (byte, error) {
 }
 
 func (r *Reader) UnreadByte() error {
-	if r.ptr <= 0 {
+	if r.eof || r.ptr <= 0 {
+		r.eof = false
 		return nil
 	}
 	r.ptr--
@@ -201,8 +225,12 @@ func (r *Reader) UnreadByte() error {
 
 func (r *Reader) readLine() ([]byte, error) {
 	if r.ptr >= len(r.buf) {
+		r.past = true
 		return nil, io.EOF
 	}
+	if bytes.IndexAny(r.buf[r.ptr:], "\r\n") < 0 {
+		r.past = true
+	}
 	offset := indexNL(r.buf[r.ptr:]) + 1
 	if offset <= 0 {
 		offset = len(r.buf) - r.ptr
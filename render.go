@@ -0,0 +1,570 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const maxFormDepth = 16
+
+type RenderOptions struct {
+	DPI        float64
+	Background color.Color
+}
+
+func (d *Document) RenderPage(n int, opts RenderOptions) (image.Image, error) {
+	return d.RenderPageContext(context.Background(), n, opts)
+}
+
+// RenderPageContext is like RenderPage but stops drawing as soon as ctx is done.
+func (d *Document) RenderPageContext(ctx context.Context, n int, opts RenderOptions) (image.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	obj := d.getPage(n)
+	if obj.isZero() {
+		return nil, fmt.Errorf("page %d not found in document", n)
+	}
+	body, err := d.GetPageCode(n)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DPI <= 0 {
+		opts.DPI = 72
+	}
+	if opts.Background == nil {
+		opts.Background = color.White
+	}
+	var (
+		box    = d.getPageBox(obj)
+		scale  = opts.DPI / 72
+		width  = int(math.Ceil((box[2] - box[0]) * scale))
+		height = int(math.Ceil((box[3] - box[1]) * scale))
+		base   [6]float64
+	)
+	switch d.getPageRotation(obj) {
+	case 90:
+		width, height = height, width
+		base = [6]float64{0, scale, scale, 0, -box[1] * scale, -box[0] * scale}
+	case 180:
+		base = [6]float64{-scale, 0, 0, scale, box[2] * scale, -box[1] * scale}
+	case 270:
+		width, height = height, width
+		base = [6]float64{0, -scale, -scale, 0, box[3] * scale, box[2] * scale}
+	default:
+		base = [6]float64{scale, 0, 0, -scale, -box[0] * scale, box[3] * scale}
+	}
+	c := canvas{
+		ctx:    ctx,
+		doc:    d,
+		img:    image.NewRGBA(image.Rect(0, 0, width, height)),
+		base:   base,
+		glyphs: make(map[string]*glyphSet),
+	}
+	draw.Draw(c.img, c.img.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+
+	r := c.renderer(d.getPageResources(obj), 0)
+	if err := r.parser.Parse(body, io.Discard); err != nil {
+		return c.img, err
+	}
+	return c.img, nil
+}
+
+type canvas struct {
+	ctx    context.Context
+	doc    *Document
+	img    *image.RGBA
+	base   [6]float64
+	glyphs map[string]*glyphSet
+}
+
+func (c *canvas) renderer(res Dict, depth int) *renderer {
+	r := renderer{
+		canvas: c,
+		parser: NewContentParser(),
+		res:    res,
+		fonts:  c.doc.getResourceFonts(res),
+		depth:  depth,
+		paint: paintState{
+			fillAlpha:   1,
+			strokeAlpha: 1,
+		},
+	}
+	r.parser.ctx = c.ctx
+	for _, op := range []string{"m", "l", "c", "v", "y", "h", "re"} {
+		r.parser.Register(op, r.build)
+	}
+	for _, op := range []string{"S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n"} {
+		r.parser.Register(op, r.paintPath)
+	}
+	for _, op := range []string{"Tj", "TJ", "'", "\""} {
+		r.parser.Register(op, r.showText)
+	}
+	r.parser.Register("q", r.save)
+	r.parser.Register("Q", r.restore)
+	r.parser.Register("W", r.setClip)
+	r.parser.Register("W*", r.setClip)
+	r.parser.Register("gs", r.setState)
+	r.parser.Register("Do", r.drawObject)
+	r.parser.Register("BI", r.drawInline)
+	return &r
+}
+
+type paintState struct {
+	clip        *image.Alpha
+	fillAlpha   float64
+	strokeAlpha float64
+}
+
+type renderer struct {
+	*canvas
+	parser *ContentParser
+	res    Dict
+	fonts  map[string]*textFont
+	depth  int
+
+	path  pathBuilder
+	clip  string
+	paint paintState
+	stack []paintState
+}
+
+func (r *renderer) device() [6]float64 {
+	return multiplyMatrix(r.parser.state.CTM, r.base)
+}
+
+func (r *renderer) build(_ io.Writer, op Operator) error {
+	r.path.add(op.Name, tokenNumbers(op.Args))
+	return nil
+}
+
+func (r *renderer) paintPath(_ io.Writer, op Operator) error {
+	var (
+		name  = op.Name
+		paths []subpath
+	)
+	if strings.HasPrefix(name, "s") || strings.HasPrefix(name, "b") {
+		r.path.add("h", nil)
+	}
+	if name != "n" || r.clip != "" {
+		paths = transformPath(r.path.list, r.device())
+	}
+	evenOdd := strings.HasSuffix(name, "*")
+	switch name {
+	case "f", "F", "f*":
+		r.fill(paths, evenOdd)
+	case "S", "s":
+		r.stroke(paths)
+	case "B", "B*", "b", "b*":
+		r.fill(paths, evenOdd)
+		r.stroke(paths)
+	}
+	if r.clip != "" {
+		r.intersectClip(paths, r.clip == "W*")
+	}
+	r.path, r.clip = pathBuilder{}, ""
+	return nil
+}
+
+func (r *renderer) fill(paths []subpath, evenOdd bool) {
+	gs := r.parser.state
+	c, ok := r.color(gs.FillSpace, gs.FillColor)
+	if !ok {
+		return
+	}
+	r.fillColor(paths, evenOdd, c, r.paint.fillAlpha)
+}
+
+func (r *renderer) stroke(paths []subpath) {
+	gs := r.parser.state
+	c, ok := r.color(gs.StrokeSpace, gs.StrokeColor)
+	if !ok {
+		return
+	}
+	var (
+		m     = r.device()
+		width = gs.LineWidth * math.Sqrt(math.Abs(m[0]*m[3]-m[1]*m[2]))
+	)
+	r.fillColor(strokePath(paths, width), false, c, r.paint.strokeAlpha)
+}
+
+func (r *renderer) fillColor(paths []subpath, evenOdd bool, c color.RGBA, alpha float64) {
+	fillPath(paths, evenOdd, r.img.Bounds(), func(x, y int, a float64) {
+		r.blend(x, y, c, a*alpha)
+	})
+}
+
+func (r *renderer) blend(x, y int, c color.RGBA, a float64) {
+	if clip := r.paint.clip; clip != nil {
+		a *= float64(clip.Pix[clip.PixOffset(x, y)]) / 0xff
+	}
+	if a <= 0 {
+		return
+	}
+	var (
+		pix = r.img.Pix[r.img.PixOffset(x, y):]
+		src = [4]uint8{c.R, c.G, c.B, c.A}
+	)
+	for i := range src {
+		pix[i] = uint8(float64(src[i])*a + float64(pix[i])*(1-a) + 0.5)
+	}
+}
+
+func (r *renderer) color(space string, comps []float64) (color.RGBA, bool) {
+	cs := colorSpace{Name: space, N: len(comps)}
+	if spaces, ok := r.doc.resolve(r.res.getValue("colorspace")).(Dict); ok {
+		if v := spaces.getValue(space); v != nil {
+			if isPatternSpace(r.doc.resolve(v)) {
+				return color.RGBA{}, false
+			}
+			if c, err := r.doc.parseColorSpace(v); err == nil {
+				cs = c
+			}
+		}
+	}
+	if space == "Pattern" || len(comps) == 0 {
+		return color.RGBA{}, false
+	}
+	if cs.Palette != nil {
+		i := int(comps[0])
+		if i < 0 || i >= len(cs.Palette) {
+			i = 0
+		}
+		return color.RGBAModel.Convert(cs.Palette[i]).(color.RGBA), true
+	}
+	if cs.Name == "Separation" {
+		comps = []float64{1 - comps[0]}
+	}
+	buf := make([]byte, len(comps))
+	for i := range comps {
+		buf[i] = unitByte(comps[i])
+	}
+	return color.RGBAModel.Convert(makeColor(buf)).(color.RGBA), true
+}
+
+func isPatternSpace(v Value) bool {
+	switch v := v.(type) {
+	case NameValue:
+		return v == "Pattern"
+	case []interface{}:
+		return len(v) > 0 && v[0] == NameValue("Pattern")
+	default:
+		return false
+	}
+}
+
+func (r *renderer) save(_ io.Writer, _ Operator) error {
+	r.stack = append(r.stack, r.paint)
+	return nil
+}
+
+func (r *renderer) restore(_ io.Writer, _ Operator) error {
+	if n := len(r.stack); n > 0 {
+		r.paint, r.stack = r.stack[n-1], r.stack[:n-1]
+	}
+	return nil
+}
+
+func (r *renderer) setClip(_ io.Writer, op Operator) error {
+	r.clip = op.Name
+	return nil
+}
+
+func (r *renderer) intersectClip(paths []subpath, evenOdd bool) {
+	var (
+		prev = r.paint.clip
+		mask = image.NewAlpha(r.img.Bounds())
+	)
+	fillPath(paths, evenOdd, mask.Bounds(), func(x, y int, a float64) {
+		i := mask.PixOffset(x, y)
+		if prev != nil {
+			a *= float64(prev.Pix[i]) / 0xff
+		}
+		mask.Pix[i] = uint8(a*0xff + 0.5)
+	})
+	r.paint.clip = mask
+}
+
+func (r *renderer) setState(_ io.Writer, op Operator) error {
+	if len(op.Args) == 0 {
+		return nil
+	}
+	var (
+		states, _ = r.doc.resolve(r.res.getValue("extgstate")).(Dict)
+		state, _  = r.doc.resolve(states.getValue(op.Args[0].Literal)).(Dict)
+	)
+	if v := r.doc.resolve(state.getValue("ca")); v != nil {
+		r.paint.fillAlpha = getNumber(v)
+		r.paint.strokeAlpha = r.paint.fillAlpha
+	}
+	if v := r.doc.resolve(state.getValue("lw")); v != nil {
+		r.parser.state.LineWidth = getNumber(v)
+	}
+	return nil
+}
+
+func (r *renderer) showText(_ io.Writer, op Operator) error {
+	for _, a := range op.Args {
+		switch a.Type {
+		case String:
+			r.show(a.Literal)
+		case Number:
+			if op.Name != "TJ" {
+				break
+			}
+			gs := &r.parser.state
+			n, _ := strconv.ParseFloat(a.Literal, 64)
+			gs.advanceText(-n / 1000 * gs.FontSize * gs.Scale)
+		}
+	}
+	return nil
+}
+
+func (r *renderer) show(str string) {
+	var (
+		gs   = &r.parser.state
+		font = r.fonts[strings.ToLower(gs.Font)]
+	)
+	if font == nil {
+		font = &textFont{size: 1, missing: 500}
+	}
+	glyphs := r.getGlyphs(font)
+	for i, n := 0, 0; i < len(str); i += n {
+		var code uint32
+		if code, n = font.split(str[i:]); n == 0 {
+			break
+		}
+		if glyphs != nil && gs.Render%4 != 3 {
+			r.drawGlyph(glyphs, glyphs.get(code))
+		}
+		tx := font.width(code)/1000*gs.FontSize + gs.CharSpace
+		if n == 1 && code == ' ' {
+			tx += gs.WordSpace
+		}
+		gs.advanceText(tx * gs.Scale)
+	}
+}
+
+func (r *renderer) getGlyphs(f *textFont) *glyphSet {
+	if f.oid == "" {
+		return nil
+	}
+	g, ok := r.glyphs[f.oid]
+	if !ok {
+		g = r.doc.getGlyphSet(f)
+		r.glyphs[f.oid] = g
+	}
+	return g
+}
+
+func (r *renderer) drawGlyph(g *glyphSet, outline []PathOp) {
+	if len(outline) == 0 {
+		return
+	}
+	var (
+		gs  = r.parser.state
+		trm = [6]float64{gs.FontSize * gs.Scale, 0, 0, gs.FontSize, 0, gs.Rise}
+		m   = multiplyMatrix(g.matrix, multiplyMatrix(trm, multiplyMatrix(gs.TextMatrix, r.device())))
+	)
+	paths := transformPath(outline, m)
+	switch gs.Render % 4 {
+	case 0:
+		r.fill(paths, false)
+	case 1:
+		r.stroke(paths)
+	case 2:
+		r.fill(paths, false)
+		r.stroke(paths)
+	}
+}
+
+func (r *renderer) drawObject(_ io.Writer, op Operator) error {
+	if len(op.Args) == 0 {
+		return nil
+	}
+	var (
+		xobj, _ = r.doc.resolve(r.res.getValue("xobject")).(Dict)
+		obj     = r.doc.getObjectWithOid(xobj.GetString(op.Args[0].Literal), true)
+	)
+	switch obj.Subtype() {
+	case "Image":
+		r.drawImage(obj)
+	case "Form":
+		r.drawForm(obj)
+	}
+	return nil
+}
+
+func (r *renderer) drawInline(_ io.Writer, op Operator) error {
+	if op.Inline != nil {
+		r.drawImage(r.doc.getInlineObject(r.res, *op.Inline))
+	}
+	return nil
+}
+
+func (r *renderer) drawForm(obj Object) {
+	if r.depth >= maxFormDepth {
+		return
+	}
+	body, err := obj.Body()
+	if err != nil {
+		return
+	}
+	res, ok := r.doc.resolve(obj.getValue("resources")).(Dict)
+	if !ok {
+		res = r.res
+	}
+	child := r.canvas.renderer(res, r.depth+1)
+	child.parser.state = r.parser.state
+	child.paint = r.paint
+	if m, ok := r.doc.getMatrix(obj.getValue("matrix")); ok {
+		child.parser.state.CTM = multiplyMatrix(m, child.parser.state.CTM)
+	}
+	if bbox := getRect(r.doc.resolve(obj.getValue("bbox"))); bbox[0] < bbox[2] && bbox[1] < bbox[3] {
+		var b pathBuilder
+		b.add("re", []float64{bbox[0], bbox[1], bbox[2] - bbox[0], bbox[3] - bbox[1]})
+		child.intersectClip(transformPath(b.list, child.device()), false)
+	}
+	child.parser.Parse(body, io.Discard)
+}
+
+func (r *renderer) drawImage(obj Object) {
+	var (
+		mask = obj.GetBool("imagemask")
+		fill color.RGBA
+		img  image.Image
+		err  error
+	)
+	if mask {
+		gs := r.parser.state
+		c, ok := r.color(gs.FillSpace, gs.FillColor)
+		if !ok {
+			return
+		}
+		fill = c
+		img, err = r.doc.decodeImage(obj, ImageRaw)
+	} else {
+		img, err = r.doc.DecodeImage(obj, ImageRGB)
+	}
+	if err != nil {
+		return
+	}
+	var (
+		m       = r.device()
+		inv, ok = invertMatrix(m)
+		area    = image.Rectangle{Min: image.Pt(math.MaxInt32, math.MaxInt32), Max: image.Pt(math.MinInt32, math.MinInt32)}
+		src     = img.Bounds()
+	)
+	if !ok || src.Empty() {
+		return
+	}
+	for _, p := range []Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}} {
+		p = applyMatrix(m, p)
+		area.Min.X = minInt(area.Min.X, int(math.Floor(p.X)))
+		area.Min.Y = minInt(area.Min.Y, int(math.Floor(p.Y)))
+		area.Max.X = maxInt(area.Max.X, int(math.Ceil(p.X)))
+		area.Max.Y = maxInt(area.Max.Y, int(math.Ceil(p.Y)))
+	}
+	area = area.Intersect(r.img.Bounds())
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			p := applyMatrix(inv, Point{X: float64(x) + 0.5, Y: float64(y) + 0.5})
+			if p.X < 0 || p.X >= 1 || p.Y <= 0 || p.Y > 1 {
+				continue
+			}
+			var (
+				sx = src.Min.X + int(p.X*float64(src.Dx()))
+				sy = src.Min.Y + int((1-p.Y)*float64(src.Dy()))
+			)
+			if mask {
+				if g := color.GrayModel.Convert(img.At(sx, sy)).(color.Gray); g.Y < 0x80 {
+					r.blend(x, y, fill, r.paint.fillAlpha)
+				}
+				continue
+			}
+			c := color.NRGBAModel.Convert(img.At(sx, sy)).(color.NRGBA)
+			r.blend(x, y, color.RGBA{R: c.R, G: c.G, B: c.B, A: 0xff}, float64(c.A)/0xff*r.paint.fillAlpha)
+		}
+	}
+}
+
+func (d *Document) getMatrix(v Value) ([6]float64, bool) {
+	var m [6]float64
+	arr, _ := d.resolve(v).([]interface{})
+	if len(arr) != len(m) {
+		return m, false
+	}
+	for i := range arr {
+		m[i] = getNumber(d.resolve(arr[i]))
+	}
+	return m, true
+}
+
+func transformPath(ops []PathOp, m [6]float64) []subpath {
+	var (
+		list        []subpath
+		start, last Point
+	)
+	extend := func(ps ...Point) {
+		if n := len(list); n == 0 || list[n-1].closed {
+			list = append(list, subpath{points: []Point{last}})
+		}
+		n := len(list) - 1
+		list[n].points = append(list[n].points, ps...)
+	}
+	for _, op := range ops {
+		switch op.Op {
+		case "m":
+			start = applyMatrix(m, op.Points[0])
+			last = start
+			list = append(list, subpath{points: []Point{start}})
+		case "l":
+			p := applyMatrix(m, op.Points[0])
+			extend(p)
+			last = p
+		case "c":
+			var (
+				p1 = applyMatrix(m, op.Points[0])
+				p2 = applyMatrix(m, op.Points[1])
+				p3 = applyMatrix(m, op.Points[2])
+			)
+			extend(flattenCurve(last, p1, p2, p3)...)
+			last = p3
+		case "h":
+			if n := len(list); n > 0 {
+				list[n-1].closed = true
+			}
+			last = start
+		}
+	}
+	return list
+}
+
+func applyMatrix(m [6]float64, p Point) Point {
+	return Point{
+		X: m[0]*p.X + m[2]*p.Y + m[4],
+		Y: m[1]*p.X + m[3]*p.Y + m[5],
+	}
+}
+
+func invertMatrix(m [6]float64) ([6]float64, bool) {
+	det := m[0]*m[3] - m[1]*m[2]
+	if det == 0 {
+		return m, false
+	}
+	return [6]float64{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}, true
+}
@@ -0,0 +1,284 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type RepairReport struct {
+	Recovered bool
+	Objects   int
+	Dropped   []string
+	Fixed     []string
+}
+
+var inheritedKeys = []string{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+// Repair writes a new version of the document made of all the objects
+// reachable from its trailer. Objects are renumbered, the length of the
+// streams are recomputed, references to objects that can not be read are
+// replaced by null and the page tree is rebuilt when it does not match the
+// pages found in the file.
+func (d *Document) Repair(w io.Writer) (RepairReport, error) {
+	report := RepairReport{
+		Recovered: d.Recovered(),
+	}
+	if d.encrypt != "" {
+		return report, fmt.Errorf("repair encrypted document: %w", ErrUnsupported)
+	}
+	var (
+		pages   = d.pageIndex()
+		root    = d.getPageRoot()
+		rebuild = len(pages) > 0 && (root.isZero() || root.GetInt("count") != int64(len(pages)))
+		tree    string
+		catalog = d.catalog
+		isPage  = make(map[string]bool)
+		seen    = make(map[string]bool)
+	)
+	if rebuild {
+		var last int
+		for _, x := range d.xref {
+			if n := objectNumber(x.Oid); n > last {
+				last = n
+			}
+		}
+		tree = fmt.Sprintf("%d/0", last+1)
+		if d.catalog == "" {
+			catalog = fmt.Sprintf("%d/0", last+2)
+			seen[catalog] = true
+			report.Fixed = append(report.Fixed, "catalog created")
+		}
+		for _, oid := range pages {
+			isPage[oid] = true
+		}
+		report.Fixed = append(report.Fixed, fmt.Sprintf("page tree rebuilt with %d pages", len(pages)))
+	}
+
+	var (
+		list    []rawObject
+		valid   = make(map[string]bool)
+		todo    = []string{d.catalog, d.info}
+		renames = make(map[string]string)
+	)
+	if rebuild {
+		todo = append(todo, pages...)
+		seen[tree] = true
+	}
+	for len(todo) > 0 {
+		oid := todo[0]
+		todo = todo[1:]
+		if oid == "" || seen[oid] {
+			continue
+		}
+		seen[oid] = true
+		p, ok := d.lookupOid(oid)
+		if !ok {
+			report.Dropped = append(report.Dropped, fmt.Sprintf("%s: object not found", oid))
+			continue
+		}
+		raw, err := d.readRaw(p, false)
+		if err != nil && !p.isEmbed() {
+			if raw, err = d.readRaw(p, true); err == nil {
+				report.Fixed = append(report.Fixed, fmt.Sprintf("%s: stream length", oid))
+			}
+		}
+		if err != nil {
+			report.Dropped = append(report.Dropped, fmt.Sprintf("%s: %s", oid, err))
+			continue
+		}
+		if raw.Skip {
+			continue
+		}
+		if rebuild {
+			switch {
+			case oid == d.catalog:
+				raw.Value = setDictEntry(raw.Value, "Pages", formatRef(tree))
+			case isPage[oid]:
+				raw.Value = d.inheritPageEntries(raw.Value, oid)
+				raw.Value = setDictEntry(raw.Value, "Parent", formatRef(tree))
+			}
+		}
+		if raw.Stream != nil {
+			raw.Value = setDictEntry(raw.Value, "Length", strconv.Itoa(len(raw.Stream)))
+		}
+		valid[oid] = true
+		renames[oid] = fmt.Sprintf("%d/0", len(list)+1)
+		list = append(list, raw)
+		todo = append(todo, valueRefs(raw.Value)...)
+	}
+	if rebuild {
+		var kids []string
+		for _, oid := range pages {
+			if valid[oid] {
+				kids = append(kids, formatRef(oid))
+			}
+		}
+		raw := rawObject{
+			Oid:   tree,
+			Value: []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))),
+		}
+		valid[tree] = true
+		renames[tree] = fmt.Sprintf("%d/0", len(list)+1)
+		list = append(list, raw)
+	}
+	if catalog != d.catalog {
+		raw := rawObject{
+			Oid:   catalog,
+			Value: []byte(fmt.Sprintf("<< /Type /Catalog /Pages %s >>", formatRef(tree))),
+		}
+		valid[catalog] = true
+		renames[catalog] = fmt.Sprintf("%d/0", len(list)+1)
+		list = append(list, raw)
+	}
+
+	var (
+		ws     = writer{inner: bufio.NewWriter(w)}
+		rename = func(oid string) string {
+			if !valid[oid] {
+				return ""
+			}
+			return renames[oid]
+		}
+	)
+	ws.writeHeader(d.GetVersion())
+	for _, raw := range list {
+		raw.Oid = rename(raw.Oid)
+		raw.Value = renumberValue(raw.Value, rename)
+		ws.writeObject(raw)
+	}
+	ws.writeTrailer(rename(catalog), rename(d.info), "", d.fileid)
+	report.Objects = len(list)
+	return report, ws.Flush()
+}
+
+// inheritPageEntries copies in the dictionary of a page the inheritable
+// entries it gets from its ancestors.
+func (d *Document) inheritPageEntries(buf []byte, oid string) []byte {
+	page := d.getObjectWithOid(oid, false)
+	for _, key := range inheritedKeys {
+		if page.Has(key) {
+			continue
+		}
+		if val := d.getInheritedEntry(page, key); val != "" {
+			buf = setDictEntry(buf, key, val)
+		}
+	}
+	return buf
+}
+
+func (d *Document) getInheritedEntry(page Object, key string) string {
+	seen := map[string]bool{page.Oid: true}
+	for oid := page.GetString("parent"); oid != "" && !seen[oid]; {
+		seen[oid] = true
+		obj := d.getObjectWithOid(oid, false)
+		if obj.isZero() {
+			break
+		}
+		if obj.Has(key) {
+			p, _ := d.lookupOid(oid)
+			raw, err := d.readRaw(p, true)
+			if err != nil {
+				break
+			}
+			return getDictEntry(raw.Value, key)
+		}
+		oid = obj.GetString("parent")
+	}
+	return ""
+}
+
+func valueRefs(buf []byte) []string {
+	var (
+		toks = splitValue(buf)
+		list []string
+	)
+	for i := 0; i+2 < len(toks); i++ {
+		if bytes.Equal(toks[i+2], []byte("R")) && isInteger(toks[i]) && isInteger(toks[i+1]) {
+			list = append(list, fmt.Sprintf("%s/%s", toks[i], toks[i+1]))
+			i += 2
+		}
+	}
+	return list
+}
+
+// dictEntries returns the position of the tokens of the value of each key
+// found at the top level of a dictionary.
+func dictEntries(toks [][]byte) map[string][2]int {
+	entries := make(map[string][2]int)
+	if len(toks) == 0 || string(toks[0]) != "<<" {
+		return entries
+	}
+	for i := 1; i < len(toks) && string(toks[i]) != ">>"; {
+		key := toks[i]
+		if len(key) == 0 || key[0] != slash {
+			i++
+			continue
+		}
+		j := skipValue(toks, i+1)
+		entries[strings.ToLower(string(key[1:]))] = [2]int{i + 1, j}
+		i = j
+	}
+	return entries
+}
+
+func skipValue(toks [][]byte, i int) int {
+	if i >= len(toks) {
+		return i
+	}
+	switch string(toks[i]) {
+	case "<<", "[":
+		depth := 0
+		for ; i < len(toks); i++ {
+			switch string(toks[i]) {
+			case "<<", "[":
+				depth++
+			case ">>", "]":
+				depth--
+			}
+			if depth == 0 {
+				return i + 1
+			}
+		}
+		return i
+	}
+	if i+2 < len(toks) && bytes.Equal(toks[i+2], []byte("R")) && isInteger(toks[i]) && isInteger(toks[i+1]) {
+		return i + 3
+	}
+	return i + 1
+}
+
+func getDictEntry(buf []byte, key string) string {
+	var (
+		toks    = splitValue(buf)
+		pos, ok = dictEntries(toks)[strings.ToLower(key)]
+	)
+	if !ok {
+		return ""
+	}
+	return string(bytes.Join(toks[pos[0]:pos[1]], []byte{space}))
+}
+
+// setDictEntry sets the value of key in the dictionary, adding the entry when
+// it does not exist yet.
+func setDictEntry(buf []byte, key, value string) []byte {
+	var (
+		toks = splitValue(buf)
+		out  [][]byte
+	)
+	if len(toks) == 0 || string(toks[0]) != "<<" {
+		return buf
+	}
+	if pos, ok := dictEntries(toks)[strings.ToLower(key)]; ok {
+		out = append(out, toks[:pos[0]]...)
+		out = append(out, []byte(value))
+		out = append(out, toks[pos[1]:]...)
+	} else {
+		out = append(out, toks[0], []byte("/"+key), []byte(value))
+		out = append(out, toks[1:]...)
+	}
+	return bytes.Join(out, []byte{space})
+}
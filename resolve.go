@@ -0,0 +1,88 @@
+package pdf
+
+import (
+	"fmt"
+)
+
+const maxResolveDepth = 32
+
+func (d Dict) GetRef(key string) string {
+	oid, _ := d.getValue(key).(Reference)
+	return string(oid)
+}
+
+// Deref returns the object referenced by oid with its stream.
+func (d *Document) Deref(oid string) (Object, error) {
+	if !isOid(oid) {
+		return Object{}, fmt.Errorf("%s: not an object reference", oid)
+	}
+	obj := d.getObjectWithOid(oid, true)
+	if obj.isZero() {
+		return obj, fmt.Errorf("object %s %w", oid, ErrMissing)
+	}
+	return obj, nil
+}
+
+// ResolveValue follows the references of v and of the arrays it holds.
+func (d *Document) ResolveValue(v Value) Value {
+	return d.resolveValue(v, 0)
+}
+
+func (d *Document) resolveValue(v Value, depth int) Value {
+	if depth >= maxResolveDepth {
+		return nil
+	}
+	v = d.resolveRef(v)
+	arr, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	list := make([]interface{}, len(arr))
+	for i := range arr {
+		list[i] = d.resolveValue(arr[i], depth+1)
+	}
+	return list
+}
+
+func (d *Document) ResolveDict(v Value) Dict {
+	dict, _ := d.resolveRef(v).(Dict)
+	return dict
+}
+
+func (d *Document) ResolveArray(v Value) []interface{} {
+	arr, _ := d.ResolveValue(v).([]interface{})
+	return arr
+}
+
+// ResolveStream returns the object with a stream referenced by v.
+func (d *Document) ResolveStream(v Value) (Object, error) {
+	oid, _ := toOid(v)
+	obj, err := d.Deref(oid)
+	if err != nil {
+		return obj, err
+	}
+	if obj.stream == nil && obj.Content == nil {
+		return Object{}, fmt.Errorf("%s: stream %w", oid, ErrMissing)
+	}
+	return obj, nil
+}
+
+// resolveRef is like resolve but follows chains of references.
+func (d *Document) resolveRef(v Value) Value {
+	seen := make(map[string]bool)
+	for {
+		oid, ok := toOid(v)
+		if !ok || seen[oid] {
+			return v
+		}
+		seen[oid] = true
+		obj := d.getObjectWithOid(oid, false)
+		if obj.isZero() {
+			return nil
+		}
+		if obj.Dict != nil {
+			return obj.Dict
+		}
+		v = obj.Data
+	}
+}
@@ -0,0 +1,231 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type Revision struct {
+	Index   int
+	Start   int64
+	End     int64
+	XRef    int64
+	Objects int
+	Trailer Dict
+}
+
+// Revisions returns the revisions of the document from the oldest to the
+// newest. Each revision covers the bytes appended to the file by an update
+// including its cross reference section and trailer.
+func (d *Document) Revisions() ([]Revision, error) {
+	sections, err := d.readSections()
+	if err != nil {
+		return nil, err
+	}
+	return d.revisions(sections)
+}
+
+func (d *Document) revisions(sections []xrefSection) ([]Revision, error) {
+	ends, err := d.revisionEnds()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		list  []Revision
+		start int64
+	)
+	for _, s := range sections {
+		rev := Revision{
+			Start:   start,
+			End:     d.size,
+			XRef:    s.Offset,
+			Objects: len(s.Entries),
+			Trailer: s.Trailer,
+		}
+		for _, e := range ends {
+			if e > s.Offset {
+				rev.End = e
+				break
+			}
+		}
+		if n := len(list); n > 0 && rev.End <= start {
+			// first page section of a linearized file
+			list[n-1].Objects += rev.Objects
+			list[n-1].Trailer = rev.Trailer
+			continue
+		}
+		rev.Index = len(list)
+		list = append(list, rev)
+		start = rev.End
+	}
+	return list, nil
+}
+
+// OpenRevision returns a new Document made of the bytes of the file up to
+// the end of the given revision. The credentials used to open the current
+// document are reused.
+func (d *Document) OpenRevision(i int) (*Document, error) {
+	list, err := d.Revisions()
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= len(list) {
+		return nil, fmt.Errorf("revision %d not found in document", i)
+	}
+	var (
+		size = list[i].End
+		src  io.ReaderAt
+	)
+	if r, ok := d.src.(*Reader); ok {
+		src = r.Section(0, size)
+	} else {
+		src = io.NewSectionReader(d.src, 0, size)
+	}
+	return readDocument(src, size, func(rd *Document) error {
+		rd.password = d.password
+		rd.recipient = d.recipient
+		rd.locked = d.locked
+		return nil
+	})
+}
+
+type xrefSection struct {
+	Offset  int64
+	Trailer Dict
+	Entries []Pointer
+	Free    []Pointer
+}
+
+func (d *Document) ObjectHistory(num int) []Object {
+	sections, err := d.readSections()
+	if err != nil {
+		return nil
+	}
+	var list []Object
+	for i, s := range sections {
+		for _, p := range s.Entries {
+			if objectNumber(p.Oid) != num {
+				continue
+			}
+			obj := d.readPointer(p, sections[:i+1])
+			if obj.isZero() {
+				continue
+			}
+			obj.Revision = i
+			list = append(list, obj)
+		}
+	}
+	return list
+}
+
+func (d *Document) readSections() ([]xrefSection, error) {
+	offset, err := d.lastStartXRef()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		list []xrefSection
+		seen = make(map[int64]bool)
+	)
+	for offset > 0 && !seen[offset] {
+		seen[offset] = true
+		dict, entries, err := d.readXRefAt(offset)
+		if err != nil {
+			return nil, err
+		}
+		s := xrefSection{
+			Offset:  offset,
+			Trailer: dict,
+		}
+		s.Entries, s.Free = splitXRef(d.mergeXRefStm(dict, entries))
+		list = append(list, s)
+		offset = dict.GetInt("prev")
+	}
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	return list, nil
+}
+
+func (d *Document) lastStartXRef() (int64, error) {
+	size := d.size
+	if size > MinRead {
+		size = MinRead
+	}
+	r, err := d.section(d.size-size, size)
+	if err != nil {
+		return 0, err
+	}
+	buf := r.Bytes()
+	x := bytes.LastIndex(buf, startxref)
+	if x < 0 {
+		return 0, fmt.Errorf("%s %w", startxref, ErrMissing)
+	}
+	fields := bytes.Fields(buf[x+len(startxref):])
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("%s: offset %w", startxref, ErrMissing)
+	}
+	return strconv.ParseInt(string(fields[0]), 10, 64)
+}
+
+func (d *Document) readPointer(p Pointer, sections []xrefSection) Object {
+	if !p.isEmbed() {
+		obj, _ := d.readObjectAt(p.Offset, d.keyFor(p.Oid), true)
+		return obj
+	}
+	for i := len(sections) - 1; i >= 0; i-- {
+		for _, x := range sections[i].Entries {
+			if x.Oid == p.Owner && !x.isEmbed() {
+				obj, _ := d.readObjectAt(x.Offset, d.keyFor(x.Oid), true)
+				return obj.getEmbeddedObject(p.Oid, p.Offset)
+			}
+		}
+	}
+	return Object{}
+}
+
+// revisionEnds returns the offset just past the end of file marker (and its
+// end of line) of each revision found in the file. Markers not preceded by a
+// startxref keyword are ignored since they are likely part of a stream.
+func (d *Document) revisionEnds() ([]int64, error) {
+	const (
+		size = MinRead * 4
+		back = 64
+	)
+	var list []int64
+	for start := int64(0); start < d.size; start += size {
+		base := start - back
+		if base < 0 {
+			base = 0
+		}
+		r, err := d.section(base, start-base+size+int64(len(eof))+2)
+		if err != nil {
+			return nil, err
+		}
+		buf := r.Bytes()
+		for x := int(start - base); ; {
+			i := bytes.Index(buf[x:], eof)
+			if i < 0 {
+				break
+			}
+			i += x
+			x = i + len(eof)
+			if base+int64(i) >= start+size {
+				break
+			}
+			if j := bytes.LastIndex(buf[:i], startxref); j < 0 || i-j > back {
+				continue
+			}
+			if x < len(buf) && buf[x] == '\r' {
+				x++
+			}
+			if x < len(buf) && buf[x] == '\n' {
+				x++
+			}
+			list = append(list, base+int64(x))
+		}
+	}
+	return list, nil
+}
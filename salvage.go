@@ -0,0 +1,195 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var objHeader = regexp.MustCompile(`(\d+)[ \t\r\n]+(\d+)[ \t\r\n]+obj\b`)
+
+// WithRecovery rebuilds the cross reference table of the document by scanning
+// the whole file for objects instead of trusting the xref sections it
+// contains.
+func WithRecovery() Option {
+	return func(d *Document) error {
+		d.recovery = true
+		return nil
+	}
+}
+
+// Recovered reports whether the document could only be read after its cross
+// reference table was rebuilt.
+func (d *Document) Recovered() bool {
+	return d.salvaged
+}
+
+func readSalvage(doc *Document) error {
+	rs, err := doc.section(0, doc.size)
+	if err != nil {
+		return err
+	}
+	doc.salvaged = true
+	buf := rs.Bytes()
+	if !doc.recovery {
+		if err := doc.salvageXRef(buf); err == nil {
+			return nil
+		}
+	}
+	doc.xref, doc.free, doc.prev = nil, nil, 0
+	doc.catalog, doc.info = "", ""
+	return doc.scanObjects(buf)
+}
+
+func (d *Document) salvageXRef(buf []byte) error {
+	var offsets []int64
+	for i := 0; i < len(buf); {
+		x := bytes.Index(buf[i:], ref)
+		if x < 0 {
+			break
+		}
+		x += i
+		if x == 0 || buf[x-1] == nl || buf[x-1] == cr {
+			offsets = append(offsets, int64(x))
+		} else if x >= 5 && bytes.HasPrefix(buf[x-5:], startxref) {
+			fields := bytes.Fields(buf[x+len(ref):])
+			if len(fields) > 0 {
+				if n, err := strconv.ParseInt(string(fields[0]), 10, 64); err == nil {
+					offsets = append(offsets, n)
+				}
+			}
+		}
+		i = x + len(ref)
+	}
+	for i := len(offsets) - 1; i >= 0; i-- {
+		dict, list, err := d.readXRefAt(offsets[i])
+		if err != nil || dict.GetString("root") == "" {
+			continue
+		}
+		d.xref, d.free = splitXRef(d.mergeXRefStm(dict, list))
+		d.setTrailer(dict)
+		d.prev = dict.GetInt("prev")
+		for d.loadPending() {
+		}
+		return nil
+	}
+	return fmt.Errorf("xref %w", ErrMissing)
+}
+
+func (d *Document) scanObjects(buf []byte) error {
+	var (
+		seen = make(map[string]int)
+		list []Pointer
+	)
+	for _, m := range objHeader.FindAllSubmatchIndex(buf, -1) {
+		if m[0] > 0 && !isBlank(buf[m[0]-1]) && !isDelimiter(buf[m[0]-1]) {
+			continue
+		}
+		oid := fmt.Sprintf("%s/%s", buf[m[2]:m[3]], buf[m[4]:m[5]])
+		p := Pointer{
+			Oid:    oid,
+			Offset: int64(m[0]),
+		}
+		if i, ok := seen[oid]; ok {
+			list[i] = p
+			continue
+		}
+		seen[oid] = len(list)
+		list = append(list, p)
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("objects %w", ErrMissing)
+	}
+	d.xref = list
+	var (
+		catalog string
+		last    Dict
+		pages   []string
+	)
+	for _, p := range list {
+		obj, err := d.readObjectAt(p.Offset, cryptKeys{}, true)
+		if err != nil {
+			continue
+		}
+		switch {
+		case obj.IsObjectStream():
+			for i, oid := range embeddedOids(obj) {
+				if _, ok := seen[oid]; ok {
+					continue
+				}
+				seen[oid] = len(d.xref)
+				d.xref = append(d.xref, Pointer{Oid: oid, Owner: p.Oid, Offset: int64(i)})
+				switch e := obj.getEmbeddedObject(oid, int64(i)); {
+				case e.isType("Catalog"):
+					catalog = oid
+				case e.IsPage():
+					pages = append(pages, oid)
+				}
+			}
+		case obj.IsXRef():
+			last = obj.Dict
+		case obj.isType("Catalog"):
+			catalog = p.Oid
+		case obj.IsPage():
+			pages = append(pages, p.Oid)
+		}
+	}
+	sort.Slice(d.xref, func(i, j int) bool {
+		return d.xref[i].Oid > d.xref[j].Oid
+	})
+	for x := len(buf); x > 0; {
+		if x = bytes.LastIndex(buf[:x], trailer); x < 0 {
+			break
+		}
+		r := NewReader(buf[x+len(trailer):])
+		r.Skip()
+		if dict, err := parseValueAsDict(r, nil); err == nil {
+			d.mergeTrailer(dict)
+		}
+	}
+	if last != nil {
+		d.mergeTrailer(last)
+	}
+	if _, ok := d.lookupOid(d.catalog); d.catalog == "" || !ok {
+		d.catalog = catalog
+	}
+	if _, ok := d.lookupOid(d.info); d.info != "" && !ok {
+		d.info = ""
+	}
+	if d.catalog != "" && d.getPageRoot().GetInt("count") > 0 {
+		return nil
+	}
+	if len(pages) == 0 {
+		return fmt.Errorf("catalog %w", ErrMissing)
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return objectNumber(pages[i]) < objectNumber(pages[j])
+	})
+	d.pages = pages
+	d.pageNums = make(map[string]int, len(pages))
+	for i, oid := range pages {
+		d.pageNums[oid] = i + 1
+	}
+	return nil
+}
+
+func embeddedOids(obj Object) []string {
+	body, err := obj.Body()
+	if err != nil {
+		return nil
+	}
+	first := obj.GetInt("first")
+	if first > int64(len(body)) {
+		return nil
+	}
+	var (
+		pairs = bytes.Fields(body[:first])
+		list  []string
+	)
+	for i := 0; i+1 < len(pairs) && i/2 < int(obj.GetInt("n")); i += 2 {
+		list = append(list, fmt.Sprintf("%s/0", pairs[i]))
+	}
+	return list
+}
@@ -0,0 +1,239 @@
+package pdf
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+type colorSpace struct {
+	Name    string
+	N       int
+	Palette color.Palette
+}
+
+func (d *Document) getImageSpace(obj Object) (colorSpace, error) {
+	if obj.GetBool("imagemask") {
+		return colorSpace{Name: "DeviceGray", N: 1}, nil
+	}
+	return d.parseColorSpace(obj.getValue("colorspace"))
+}
+
+func (d *Document) parseColorSpace(v Value) (colorSpace, error) {
+	switch v := d.resolve(v).(type) {
+	case NameValue:
+		cs := colorSpace{Name: string(v)}
+		switch v {
+		case "DeviceGray", "CalGray", "G":
+			cs.N = 1
+		case "DeviceRGB", "CalRGB", "RGB":
+			cs.N = 3
+		case "DeviceCMYK", "CMYK":
+			cs.N = 4
+		default:
+			return cs, fmt.Errorf("color space %s: %w", v, ErrUnsupported)
+		}
+		return cs, nil
+	case []interface{}:
+		if len(v) == 0 {
+			break
+		}
+		name, _ := toString(d.resolve(v[0]))
+		cs := colorSpace{Name: name}
+		switch name {
+		case "CalGray", "CalRGB", "Lab":
+			if len(v) == 1 {
+				return d.parseColorSpace(name)
+			}
+			cs.N = 3
+			if name == "CalGray" {
+				cs.N = 1
+			}
+		case "ICCBased":
+			if len(v) < 2 {
+				break
+			}
+			oid, _ := toOid(v[1])
+			cs.N = int(d.getObjectWithOid(oid, false).GetInt("n"))
+		case "Separation":
+			cs.N = 1
+		case "Indexed", "I":
+			return d.parseIndexed(v)
+		default:
+			return cs, fmt.Errorf("color space %s: %w", name, ErrUnsupported)
+		}
+		if cs.N == 0 {
+			return cs, fmt.Errorf("color space %s: invalid number of components", name)
+		}
+		return cs, nil
+	}
+	return colorSpace{}, fmt.Errorf("color space %w", ErrMissing)
+}
+
+func (d *Document) parseIndexed(arr []interface{}) (colorSpace, error) {
+	cs := colorSpace{Name: "Indexed", N: 1}
+	if len(arr) < 4 {
+		return cs, fmt.Errorf("indexed color space: not enough elements")
+	}
+	base, err := d.parseColorSpace(arr[1])
+	if err != nil {
+		return cs, err
+	}
+	hival, _ := d.resolve(arr[2]).(int64)
+	var lookup []byte
+	switch v := arr[3].(type) {
+	case Reference:
+		obj := d.getObjectWithOid(string(v), true)
+		if lookup, err = obj.Body(); err != nil {
+			return cs, err
+		}
+	case StringValue:
+		lookup = []byte(v)
+	default:
+		return cs, fmt.Errorf("indexed color space: invalid lookup table")
+	}
+	for i := 0; i <= int(hival) && (i+1)*base.N <= len(lookup); i++ {
+		cs.Palette = append(cs.Palette, makeColor(lookup[i*base.N:(i+1)*base.N]))
+	}
+	if len(cs.Palette) == 0 {
+		return cs, fmt.Errorf("indexed color space: empty lookup table")
+	}
+	return cs, nil
+}
+
+func makeColor(comps []byte) color.Color {
+	switch len(comps) {
+	case 1:
+		return color.Gray{Y: comps[0]}
+	case 3:
+		return color.RGBA{R: comps[0], G: comps[1], B: comps[2], A: 0xff}
+	case 4:
+		r, g, b := color.CMYKToRGB(comps[0], comps[1], comps[2], comps[3])
+		return color.RGBA{R: r, G: g, B: b, A: 0xff}
+	default:
+		return color.Black
+	}
+}
+
+func (d *Document) decodeSamples(obj Object) (image.Image, error) {
+	for _, f := range obj.getFilters() {
+		switch f {
+		case filterFlate, "Fl", filterASCIIHex, "AHx", filterASCII85, "A85", "Crypt":
+		default:
+			return nil, fmt.Errorf("%s: %s %w", obj.Oid, f, ErrUnsupported)
+		}
+	}
+	cs, err := d.getImageSpace(obj)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		width  = int(obj.GetInt("width"))
+		height = int(obj.GetInt("height"))
+		bits   = int(obj.GetInt("bitspercomponent"))
+	)
+	if obj.GetBool("imagemask") {
+		bits = 1
+	}
+	switch bits {
+	case 1, 2, 4, 8, 16:
+	default:
+		return nil, fmt.Errorf("%s: %d bits per component %w", obj.Oid, bits, ErrUnsupported)
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("%s: invalid image size %dx%d", obj.Oid, width, height)
+	}
+	body, err := obj.Body()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		stride = (width*cs.N*bits + 7) / 8
+		max    = float64(int(1)<<bits - 1)
+		decode = d.getDecode(obj)
+		rect   = image.Rect(0, 0, width, height)
+	)
+	if len(decode) < 2*cs.N {
+		decode = make([]float64, 2*cs.N)
+		for i := 0; i < cs.N; i++ {
+			decode[2*i+1] = 1
+			if cs.Palette != nil {
+				decode[2*i+1] = max
+			}
+		}
+	}
+	if len(body) < stride*height {
+		body = append(body, make([]byte, stride*height-len(body))...)
+	}
+	sample := func(row []byte, i int) float64 {
+		var v int
+		switch bits {
+		case 8:
+			v = int(row[i])
+		case 16:
+			v = int(row[2*i])<<8 | int(row[2*i+1])
+		default:
+			var (
+				pos   = i * bits
+				shift = 8 - bits - pos%8
+			)
+			v = int(row[pos/8]>>shift) & (1<<bits - 1)
+		}
+		c := i % cs.N
+		return decode[2*c] + float64(v)*(decode[2*c+1]-decode[2*c])/max
+	}
+	if cs.Palette != nil {
+		img := image.NewPaletted(rect, cs.Palette)
+		for y := 0; y < height; y++ {
+			row := body[y*stride:]
+			for x := 0; x < width; x++ {
+				ix := int(sample(row, x) + 0.5)
+				if ix >= len(cs.Palette) {
+					ix = len(cs.Palette) - 1
+				} else if ix < 0 {
+					ix = 0
+				}
+				img.Pix[y*img.Stride+x] = uint8(ix)
+			}
+		}
+		return img, nil
+	}
+	pixel := func(row []byte, i int) uint8 {
+		return unitByte(sample(row, i))
+	}
+	switch cs.N {
+	case 1:
+		img := image.NewGray(rect)
+		for y := 0; y < height; y++ {
+			row := body[y*stride:]
+			for x := 0; x < width; x++ {
+				img.Pix[y*img.Stride+x] = pixel(row, x)
+			}
+		}
+		return img, nil
+	case 3:
+		img := image.NewRGBA(rect)
+		for y := 0; y < height; y++ {
+			row := body[y*stride:]
+			for x := 0; x < width; x++ {
+				i := y*img.Stride + x*4
+				img.Pix[i] = pixel(row, x*3)
+				img.Pix[i+1] = pixel(row, x*3+1)
+				img.Pix[i+2] = pixel(row, x*3+2)
+				img.Pix[i+3] = 0xff
+			}
+		}
+		return img, nil
+	case 4:
+		img := image.NewCMYK(rect)
+		for y := 0; y < height; y++ {
+			row := body[y*stride:]
+			for x := 0; x < width*4; x++ {
+				img.Pix[y*img.Stride+x] = pixel(row, x)
+			}
+		}
+		return img, nil
+	default:
+		return nil, fmt.Errorf("%s: %d color components %w", obj.Oid, cs.N, ErrUnsupported)
+	}
+}
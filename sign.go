@@ -0,0 +1,614 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	_ "crypto/md5"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var ErrSignature = errors.New("invalid signature")
+
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+
+	oidMD5    = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 5}
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA224 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 4}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidRSA    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidRSAPSS = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+)
+
+type Signature struct {
+	Oid       string
+	Who       string
+	When      time.Time
+	Reason    string
+	Filter    string
+	SubFilter string
+	ByteRange []int64
+	Pem       []byte
+
+	DocTimeStamp bool
+	TimeStamp    *TimeStamp
+}
+
+type SignatureCheck struct {
+	Signer   *x509.Certificate
+	Chain    []*x509.Certificate
+	Signed   time.Time
+	Digest   bool
+	Valid    bool
+	Trusted  bool
+	Modified bool
+}
+
+type SignatureCoverage struct {
+	Whole     bool
+	Covered   int64
+	Size      int64
+	Revision  int
+	Revisions int
+	Appended  []int64
+}
+
+func (c SignatureCheck) Verified() bool {
+	return c.Digest && c.Valid && c.Trusted && !c.Modified
+}
+
+func (d *Document) GetSignatures() []Signature {
+	var list []Signature
+	d.Walk(func(o Object) bool {
+		if o.IsSignature() {
+			list = append(list, d.getSignature(o))
+		}
+		return true
+	})
+	return list
+}
+
+func (d *Document) getSignature(o Object) Signature {
+	sig := Signature{
+		Oid:       o.Oid,
+		Who:       d.resolveString(o.getValue("name")),
+		Reason:    d.resolveString(o.getValue("reason")),
+		Filter:    o.GetString("filter"),
+		SubFilter: o.GetString("subfilter"),
+
+		DocTimeStamp: o.isType("DocTimeStamp"),
+	}
+	sig.When, _ = d.parseDate(d.resolveString(o.getValue("m")))
+	arr, _ := d.resolve(o.getValue("byterange")).([]interface{})
+	for i := range arr {
+		n, _ := d.resolve(arr[i]).(int64)
+		sig.ByteRange = append(sig.ByteRange, n)
+	}
+	contents := d.getSignatureContents(sig)
+	if sd, err := parseSignedData(contents); err == nil && len(sd.signers) > 0 {
+		if cert := sd.signers[0].certificate(sd.certs); cert != nil {
+			sig.Pem = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+		}
+		if sig.DocTimeStamp {
+			sig.TimeStamp, _ = parseTimeStamp(contents)
+		} else if token := findAttribute(sd.signers[0].unsigned, oidSignatureTimeStamp); token != nil {
+			sig.TimeStamp, _ = parseTimeStamp(token)
+		}
+	}
+	if sig.When.IsZero() && sig.TimeStamp != nil {
+		sig.When = sig.TimeStamp.Time
+	}
+	return sig
+}
+
+// VerifySignature checks the digest of the bytes covered by the signature, the
+// signature of the signer and its certificate chain against roots (or the
+// system pool when roots is nil). The returned error reports the first check
+// that failed; the SignatureCheck is filled as far as the checks went.
+func (d *Document) VerifySignature(sig Signature, roots *x509.CertPool) (SignatureCheck, error) {
+	var check SignatureCheck
+	if err := d.checkByteRange(sig.ByteRange); err != nil {
+		return check, fmt.Errorf("%s: %w", sig.Oid, err)
+	}
+	if cov, err := d.GetSignatureCoverage(sig); err == nil {
+		check.Modified = !cov.Whole
+	}
+
+	contents := d.getSignatureContents(sig)
+	if len(contents) == 0 {
+		return check, fmt.Errorf("%s: contents %w", sig.Oid, ErrMissing)
+	}
+	if !bytes.Equal(contents, d.getSignatureValue(sig.Oid)) {
+		return check, fmt.Errorf("%s: contents differ from byte range gap: %w", sig.Oid, ErrSignature)
+	}
+	if sig.SubFilter == "adbe.x509.rsa_sha1" {
+		return d.verifyRawSignature(sig, contents, roots)
+	}
+	sd, err := parseSignedData(contents)
+	if err != nil {
+		return check, fmt.Errorf("%s: %s", sig.Oid, err)
+	}
+	if len(sd.signers) == 0 {
+		return check, fmt.Errorf("%s: signer %w", sig.Oid, ErrMissing)
+	}
+	si := sd.signers[0]
+	if check.Signer = si.certificate(sd.certs); check.Signer == nil {
+		return check, fmt.Errorf("%s: signer certificate %w", sig.Oid, ErrMissing)
+	}
+	check.Signed = si.signingTime()
+
+	var (
+		content = sd.content
+		sum     []byte
+	)
+	switch sig.SubFilter {
+	case "adbe.pkcs7.sha1":
+		sum, err = d.digestByteRange(crypto.SHA1, sig.ByteRange)
+		if err != nil {
+			return check, err
+		}
+		if !bytes.Equal(sum, content) {
+			return check, fmt.Errorf("%s: digest mismatch: %w", sig.Oid, ErrSignature)
+		}
+		sum = digestContent(si.digest, content)
+	case "ETSI.RFC3161":
+		// the TSTInfo is signed, its imprint is the digest of the byte range
+		if err := d.verifyTimeStamp(sig, content); err != nil {
+			return check, fmt.Errorf("%s: %s: %w", sig.Oid, err, ErrSignature)
+		}
+		if sig.TimeStamp != nil && check.Signed.IsZero() {
+			check.Signed = sig.TimeStamp.Time
+		}
+		sum = digestContent(si.digest, content)
+	default:
+		if content != nil {
+			return check, fmt.Errorf("%s: encapsulated content in detached signature: %w", sig.Oid, ErrSignature)
+		}
+		if sum, err = d.digestByteRange(si.digest, sig.ByteRange); err != nil {
+			return check, err
+		}
+	}
+	if si.attrs != nil {
+		if !bytes.Equal(si.messageDigest(), sum) {
+			return check, fmt.Errorf("%s: digest mismatch: %w", sig.Oid, ErrSignature)
+		}
+		h := si.digest.New()
+		h.Write(si.attrs)
+		sum = h.Sum(nil)
+	}
+	check.Digest = true
+	if err := verifyDigest(check.Signer.PublicKey, si.digest, si.sigAlg, sum, si.signature); err != nil {
+		return check, fmt.Errorf("%s: %s: %w", sig.Oid, err, ErrSignature)
+	}
+	check.Valid = true
+	if err := check.verifyChain(sd.certs, roots); err != nil {
+		return check, fmt.Errorf("%s: %s", sig.Oid, err)
+	}
+	return check, nil
+}
+
+func (d *Document) verifyRawSignature(sig Signature, contents []byte, roots *x509.CertPool) (SignatureCheck, error) {
+	var (
+		check SignatureCheck
+		certs []*x509.Certificate
+		obj   = d.getObjectWithOid(sig.Oid, false)
+	)
+	if cov, err := d.GetSignatureCoverage(sig); err == nil {
+		check.Modified = !cov.Whole
+	}
+	switch v := d.resolve(obj.getValue("cert")).(type) {
+	case StringValue:
+		if c, err := x509.ParseCertificate([]byte(v)); err == nil {
+			certs = append(certs, c)
+		}
+	case []interface{}:
+		for i := range v {
+			str, _ := d.resolve(v[i]).(StringValue)
+			if c, err := x509.ParseCertificate([]byte(str)); err == nil {
+				certs = append(certs, c)
+			}
+		}
+	}
+	if len(certs) == 0 {
+		return check, fmt.Errorf("%s: signer certificate %w", sig.Oid, ErrMissing)
+	}
+	check.Signer = certs[0]
+
+	var signature []byte
+	if _, err := asn1.Unmarshal(contents, &signature); err != nil {
+		return check, fmt.Errorf("%s: %s", sig.Oid, err)
+	}
+	sum, err := d.digestByteRange(crypto.SHA1, sig.ByteRange)
+	if err != nil {
+		return check, err
+	}
+	check.Digest = true
+	if err := verifyDigest(check.Signer.PublicKey, crypto.SHA1, oidRSA, sum, signature); err != nil {
+		return check, fmt.Errorf("%s: %s: %w", sig.Oid, err, ErrSignature)
+	}
+	check.Valid = true
+	if err := check.verifyChain(certs, roots); err != nil {
+		return check, fmt.Errorf("%s: %s", sig.Oid, err)
+	}
+	return check, nil
+}
+
+func (c *SignatureCheck) verifyChain(certs []*x509.Certificate, roots *x509.CertPool) error {
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		CurrentTime:   c.Signed,
+	}
+	for _, cert := range certs {
+		if cert != c.Signer {
+			opts.Intermediates.AddCert(cert)
+		}
+	}
+	chains, err := c.Signer.Verify(opts)
+	if err != nil {
+		return err
+	}
+	c.Chain = chains[0]
+	c.Trusted = true
+	return nil
+}
+
+// getSignatureContents reads the /Contents string of the signature directly
+// from the gap left in the file by the byte range so that it is never altered
+// by decryption.
+func (d *Document) getSignatureContents(sig Signature) []byte {
+	if len(sig.ByteRange) < 4 {
+		return nil
+	}
+	var (
+		offset = sig.ByteRange[0] + sig.ByteRange[1]
+		size   = sig.ByteRange[2] - offset
+	)
+	if size <= 2 {
+		return nil
+	}
+	r, err := d.section(offset, size)
+	if err != nil {
+		return nil
+	}
+	buf := bytes.TrimSpace(r.Bytes())
+	if len(buf) < 2 || buf[0] != langle || buf[len(buf)-1] != rangle {
+		return nil
+	}
+	buf = bytes.Join(bytes.Fields(buf[1:len(buf)-1]), nil)
+	if len(buf)%2 != 0 {
+		buf = append(buf, '0')
+	}
+	out := make([]byte, hex.DecodedLen(len(buf)))
+	if _, err := hex.Decode(out, buf); err != nil {
+		return nil
+	}
+	return out
+}
+
+// getSignatureValue returns the /Contents string of the signature dictionary.
+// It is never encrypted so the object is read again without keys.
+func (d *Document) getSignatureValue(oid string) []byte {
+	p, ok := d.lookupOid(oid)
+	if !ok {
+		return nil
+	}
+	obj := d.getObjectWithOid(oid, false)
+	if !p.isEmbed() {
+		obj, _ = d.readObjectAt(p.Offset, cryptKeys{}, false)
+	}
+	str, _ := obj.getValue("contents").(StringValue)
+	return []byte(str)
+}
+
+// checkByteRange accepts only byte ranges made of two parts, starting at the
+// beginning of the file and ending at the end of a revision.
+func (d *Document) checkByteRange(br []int64) error {
+	if len(br) != 4 || br[0] != 0 || br[1] <= 0 || br[2] <= br[1] || br[3] < 0 {
+		return fmt.Errorf("byte range %v: %w", br, ErrSignature)
+	}
+	end := br[2] + br[3]
+	if end > d.size {
+		return fmt.Errorf("byte range %d-%d outside of file: %w", br[2], end, ErrSignature)
+	}
+	offset := end - 64
+	if offset < 0 {
+		offset = 0
+	}
+	r, err := d.section(offset, end-offset)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(bytes.TrimRight(r.Bytes(), "\r\n\t\f\x00 "), eof) {
+		return fmt.Errorf("byte range does not end with %s: %w", eof, ErrSignature)
+	}
+	return nil
+}
+
+func digestContent(hash crypto.Hash, content []byte) []byte {
+	h := hash.New()
+	h.Write(content)
+	return h.Sum(nil)
+}
+
+func (d *Document) digestByteRange(hash crypto.Hash, br []int64) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("digest %s %w", hash, ErrUnsupported)
+	}
+	h := hash.New()
+	for i := 0; i+1 < len(br); i += 2 {
+		if br[i] < 0 || br[i+1] < 0 || br[i]+br[i+1] > d.size {
+			return nil, fmt.Errorf("byte range %d-%d outside of file", br[i], br[i]+br[i+1])
+		}
+		if _, err := io.Copy(h, io.NewSectionReader(d.src, br[i], br[i+1])); err != nil {
+			return nil, err
+		}
+	}
+	return h.Sum(nil), nil
+}
+
+// GetSignatureCoverage reports up to where the byte range of the signature
+// covers the file, which revision was signed and the offsets at which each
+// revision appended after the signature starts.
+func (d *Document) GetSignatureCoverage(sig Signature) (SignatureCoverage, error) {
+	cov := SignatureCoverage{
+		Size: d.size,
+	}
+	if len(sig.ByteRange) < 2 || len(sig.ByteRange)%2 != 0 {
+		return cov, fmt.Errorf("%s: byte range %w", sig.Oid, ErrMissing)
+	}
+	cov.Covered = sig.ByteRange[len(sig.ByteRange)-2] + sig.ByteRange[len(sig.ByteRange)-1]
+	ends, err := d.revisionEnds()
+	if err != nil {
+		return cov, err
+	}
+	cov.Revisions = len(ends)
+	var last int64
+	for _, e := range ends {
+		if e-int64(len(eof)) < cov.Covered {
+			cov.Revision++
+			last = e
+			continue
+		}
+		if last < cov.Covered {
+			last = cov.Covered
+		}
+		cov.Appended = append(cov.Appended, last)
+		last = e
+	}
+	cov.Whole = len(cov.Appended) == 0
+	if cov.Whole && cov.Covered < d.size {
+		r, err := d.section(cov.Covered, d.size-cov.Covered)
+		if err != nil {
+			return cov, err
+		}
+		cov.Whole = len(bytes.TrimSpace(r.Bytes())) == 0
+	}
+	return cov, nil
+}
+
+type signedData struct {
+	contentType asn1.ObjectIdentifier
+	content     []byte
+	certs       []*x509.Certificate
+	signers     []signerInfo
+}
+
+type signerInfo struct {
+	sid       asn1.RawValue
+	digest    crypto.Hash
+	attrs     []byte
+	sigAlg    asn1.ObjectIdentifier
+	signature []byte
+	unsigned  []byte
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+func parseSignedData(buf []byte) (*signedData, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(buf, &ci); err != nil {
+		return nil, err
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("content type %s: %w", ci.ContentType, ErrUnsupported)
+	}
+	elems, err := asn1Elements(ci.Content.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) < 4 {
+		return nil, fmt.Errorf("signed data: too few elements")
+	}
+	var sd signedData
+	encap, err := asn1Elements(elems[2].FullBytes)
+	if err == nil && len(encap) > 0 {
+		asn1.Unmarshal(encap[0].FullBytes, &sd.contentType)
+	}
+	if len(encap) > 1 {
+		var inner asn1.RawValue
+		if _, err := asn1.Unmarshal(encap[1].Bytes, &inner); err == nil {
+			sd.content = inner.Bytes
+			if inner.IsCompound {
+				sd.content, _ = joinOctets(inner.Bytes)
+			}
+		}
+	}
+	for _, e := range elems[3 : len(elems)-1] {
+		if e.Class != asn1.ClassContextSpecific || e.Tag != 0 {
+			continue
+		}
+		rest := e.Bytes
+		for len(rest) > 0 {
+			var raw asn1.RawValue
+			if rest, err = asn1.Unmarshal(rest, &raw); err != nil {
+				break
+			}
+			if cert, err := x509.ParseCertificate(raw.FullBytes); err == nil {
+				sd.certs = append(sd.certs, cert)
+			}
+		}
+	}
+	infos, err := asn1Elements(elems[len(elems)-1].FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range infos {
+		si, err := parseSignerInfo(i.FullBytes)
+		if err != nil {
+			return nil, err
+		}
+		sd.signers = append(sd.signers, si)
+	}
+	return &sd, nil
+}
+
+func parseSignerInfo(buf []byte) (signerInfo, error) {
+	var si signerInfo
+	elems, err := asn1Elements(buf)
+	if err != nil {
+		return si, err
+	}
+	if len(elems) < 5 {
+		return si, fmt.Errorf("signer info: too few elements")
+	}
+	si.sid = elems[1]
+	var alg pkix.AlgorithmIdentifier
+	if _, err := asn1.Unmarshal(elems[2].FullBytes, &alg); err != nil {
+		return si, err
+	}
+	if si.digest = digestHash(alg.Algorithm); si.digest == 0 {
+		return si, fmt.Errorf("digest %s %w", alg.Algorithm, ErrUnsupported)
+	}
+	elems = elems[3:]
+	if elems[0].Class == asn1.ClassContextSpecific && elems[0].Tag == 0 {
+		si.attrs = append([]byte{}, elems[0].FullBytes...)
+		si.attrs[0] = 0x31
+		elems = elems[1:]
+	}
+	if len(elems) < 2 {
+		return si, fmt.Errorf("signer info: too few elements")
+	}
+	if _, err := asn1.Unmarshal(elems[0].FullBytes, &alg); err != nil {
+		return si, err
+	}
+	si.sigAlg = alg.Algorithm
+	si.signature = elems[1].Bytes
+	if len(elems) > 2 && elems[2].Class == asn1.ClassContextSpecific && elems[2].Tag == 1 {
+		si.unsigned = elems[2].FullBytes
+	}
+	return si, nil
+}
+
+func (si signerInfo) certificate(certs []*x509.Certificate) *x509.Certificate {
+	for _, c := range certs {
+		if si.sid.Class == asn1.ClassContextSpecific {
+			if bytes.Equal(si.sid.Bytes, c.SubjectKeyId) {
+				return c
+			}
+			continue
+		}
+		var is issuerAndSerial
+		if _, err := asn1.Unmarshal(si.sid.FullBytes, &is); err != nil {
+			return nil
+		}
+		if bytes.Equal(is.Issuer.FullBytes, c.RawIssuer) && is.Serial.Cmp(c.SerialNumber) == 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+func (si signerInfo) attribute(oid asn1.ObjectIdentifier) []byte {
+	if si.attrs == nil {
+		return nil
+	}
+	return findAttribute(si.attrs, oid)
+}
+
+func findAttribute(buf []byte, oid asn1.ObjectIdentifier) []byte {
+	elems, err := asn1Elements(buf)
+	if err != nil {
+		return nil
+	}
+	for _, e := range elems {
+		var attr attribute
+		if _, err := asn1.Unmarshal(e.FullBytes, &attr); err != nil {
+			continue
+		}
+		if attr.Type.Equal(oid) {
+			return attr.Values.Bytes
+		}
+	}
+	return nil
+}
+
+func (si signerInfo) messageDigest() []byte {
+	var sum []byte
+	asn1.Unmarshal(si.attribute(oidMessageDigest), &sum)
+	return sum
+}
+
+func (si signerInfo) signingTime() time.Time {
+	var when time.Time
+	asn1.Unmarshal(si.attribute(oidSigningTime), &when)
+	return when
+}
+
+func digestHash(oid asn1.ObjectIdentifier) crypto.Hash {
+	switch {
+	case oid.Equal(oidMD5):
+		return crypto.MD5
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1
+	case oid.Equal(oidSHA224):
+		return crypto.SHA224
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512
+	default:
+		return 0
+	}
+}
+
+func verifyDigest(key crypto.PublicKey, hash crypto.Hash, alg asn1.ObjectIdentifier, sum, signature []byte) error {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if alg.Equal(oidRSAPSS) {
+			return rsa.VerifyPSS(key, hash, sum, signature, nil)
+		}
+		return rsa.VerifyPKCS1v15(key, hash, sum, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, sum, signature) {
+			return fmt.Errorf("ecdsa verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("public key %T %w", key, ErrUnsupported)
+	}
+}
@@ -0,0 +1,204 @@
+package pdf
+
+import (
+	"strconv"
+)
+
+var identityMatrix = [6]float64{1, 0, 0, 1, 0, 0}
+
+type GraphicsState struct {
+	CTM        [6]float64
+	TextMatrix [6]float64
+	LineMatrix [6]float64
+
+	LineWidth   float64
+	StrokeSpace string
+	StrokeColor []float64
+	FillSpace   string
+	FillColor   []float64
+	ClipDepth   int
+
+	Font      string
+	FontSize  float64
+	CharSpace float64
+	WordSpace float64
+	Scale     float64
+	Leading   float64
+	Rise      float64
+	Render    int
+}
+
+func defaultGraphicsState() GraphicsState {
+	return GraphicsState{
+		CTM:         identityMatrix,
+		TextMatrix:  identityMatrix,
+		LineMatrix:  identityMatrix,
+		LineWidth:   1,
+		StrokeSpace: "DeviceGray",
+		StrokeColor: []float64{0},
+		FillSpace:   "DeviceGray",
+		FillColor:   []float64{0},
+		Scale:       1,
+	}
+}
+
+func (g GraphicsState) Transform(x, y float64) (float64, float64) {
+	return g.CTM[0]*x + g.CTM[2]*y + g.CTM[4], g.CTM[1]*x + g.CTM[3]*y + g.CTM[5]
+}
+
+func (g GraphicsState) TextPosition() (float64, float64) {
+	return g.Transform(g.TextMatrix[4], g.TextMatrix[5])
+}
+
+func (g *GraphicsState) moveText(x, y float64) {
+	g.LineMatrix[4] += x*g.LineMatrix[0] + y*g.LineMatrix[2]
+	g.LineMatrix[5] += x*g.LineMatrix[1] + y*g.LineMatrix[3]
+	g.TextMatrix = g.LineMatrix
+}
+
+func (g *GraphicsState) advanceText(tx float64) {
+	g.TextMatrix[4] += tx * g.TextMatrix[0]
+	g.TextMatrix[5] += tx * g.TextMatrix[1]
+}
+
+func (p *ContentParser) State() GraphicsState {
+	return p.state
+}
+
+func (p *ContentParser) update(op Operator) {
+	var (
+		g    = &p.state
+		nums = tokenNumbers(op.Args)
+	)
+	switch op.Name {
+	case "q":
+		p.stack = append(p.stack, p.state)
+	case "Q":
+		if n := len(p.stack); n > 0 {
+			p.state, p.stack = p.stack[n-1], p.stack[:n-1]
+		}
+	case "cm":
+		if len(nums) < 6 {
+			break
+		}
+		var m [6]float64
+		copy(m[:], nums)
+		g.CTM = multiplyMatrix(m, g.CTM)
+	case "w":
+		if len(nums) > 0 {
+			g.LineWidth = nums[0]
+		}
+	case "W", "W*":
+		g.ClipDepth++
+	case "G", "g":
+		setColor(g, op.Name == "G", "DeviceGray", nums)
+	case "RG", "rg":
+		setColor(g, op.Name == "RG", "DeviceRGB", nums)
+	case "K", "k":
+		setColor(g, op.Name == "K", "DeviceCMYK", nums)
+	case "CS", "cs":
+		if len(op.Args) == 0 {
+			break
+		}
+		setColor(g, op.Name == "CS", op.Args[0].Literal, nil)
+	case "SC", "SCN":
+		g.StrokeColor = nums
+	case "sc", "scn":
+		g.FillColor = nums
+	case "BT":
+		g.TextMatrix, g.LineMatrix = identityMatrix, identityMatrix
+	case "Tf":
+		if len(op.Args) < 2 || len(nums) == 0 {
+			break
+		}
+		g.Font, g.FontSize = op.Args[0].Literal, nums[len(nums)-1]
+	case "Tc":
+		if len(nums) > 0 {
+			g.CharSpace = nums[0]
+		}
+	case "Tw":
+		if len(nums) > 0 {
+			g.WordSpace = nums[0]
+		}
+	case "Tz":
+		if len(nums) > 0 {
+			g.Scale = nums[0] / 100
+		}
+	case "TL":
+		if len(nums) > 0 {
+			g.Leading = nums[0]
+		}
+	case "Ts":
+		if len(nums) > 0 {
+			g.Rise = nums[0]
+		}
+	case "Tr":
+		if len(nums) > 0 {
+			g.Render = int(nums[0])
+		}
+	case "Td", "TD":
+		if len(nums) < 2 {
+			break
+		}
+		if op.Name == "TD" {
+			g.Leading = -nums[1]
+		}
+		g.moveText(nums[0], nums[1])
+	case "Tm":
+		if len(nums) < 6 {
+			break
+		}
+		copy(g.TextMatrix[:], nums)
+		g.LineMatrix = g.TextMatrix
+	case "T*", "'":
+		g.moveText(0, -g.Leading)
+	case "\"":
+		if len(nums) >= 2 {
+			g.WordSpace, g.CharSpace = nums[0], nums[1]
+		}
+		g.moveText(0, -g.Leading)
+	}
+}
+
+func setColor(g *GraphicsState, stroke bool, space string, comps []float64) {
+	if comps == nil {
+		switch space {
+		case "DeviceCMYK":
+			comps = []float64{0, 0, 0, 1}
+		case "DeviceRGB":
+			comps = []float64{0, 0, 0}
+		default:
+			comps = []float64{0}
+		}
+	}
+	if stroke {
+		g.StrokeSpace, g.StrokeColor = space, comps
+	} else {
+		g.FillSpace, g.FillColor = space, comps
+	}
+}
+
+func multiplyMatrix(a, b [6]float64) [6]float64 {
+	return [6]float64{
+		a[0]*b[0] + a[1]*b[2],
+		a[0]*b[1] + a[1]*b[3],
+		a[2]*b[0] + a[3]*b[2],
+		a[2]*b[1] + a[3]*b[3],
+		a[4]*b[0] + a[5]*b[2] + b[4],
+		a[4]*b[1] + a[5]*b[3] + b[5],
+	}
+}
+
+func tokenNumbers(args []Token) []float64 {
+	var list []float64
+	for _, a := range args {
+		if a.Type != Number {
+			continue
+		}
+		n, err := strconv.ParseFloat(a.Literal, 64)
+		if err == nil {
+			list = append(list, n)
+		}
+	}
+	return list
+}
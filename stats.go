@@ -0,0 +1,99 @@
+package pdf
+
+import (
+	"strings"
+)
+
+type Usage struct {
+	Count int
+	// Bytes is the length of the streams as stored in the file.
+	Bytes int64
+}
+
+func (u Usage) add(size int64) Usage {
+	u.Count++
+	u.Bytes += size
+	return u
+}
+
+type Stats struct {
+	Size      int64
+	Revisions int
+	Objects   int
+	Pages     int
+	Fonts     int
+	Images    int
+	// Types is keyed by /Type, with the subtype for XObjects.
+	Types map[string]Usage
+	// Filters is keyed by the filters of the streams joined with a +.
+	Filters map[string]Usage
+}
+
+func (d *Document) Stats() (Stats, error) {
+	st := Stats{
+		Size:    d.size,
+		Pages:   len(d.pageIndex()),
+		Types:   make(map[string]Usage),
+		Filters: make(map[string]Usage),
+	}
+	if revs, err := d.Revisions(); err == nil {
+		st.Revisions = len(revs)
+	}
+	var (
+		roles   = make(map[string]string)
+		untyped = make(map[string]int64)
+	)
+	err := d.walkObjects(true, func(obj Object) bool {
+		st.Objects++
+		switch {
+		case obj.IsFont():
+			st.Fonts++
+		case obj.IsImage():
+			st.Images++
+		}
+		var size int64
+		if obj.HasStream() {
+			size = obj.streamLength()
+			filter := strings.Join(obj.getFilters(), "+")
+			st.Filters[filter] = st.Filters[filter].add(size)
+		}
+		typ := statsType(obj)
+		switch typ {
+		case "Page":
+			list := obj.GetStringArray("contents")
+			if len(list) == 0 {
+				list = append(list, obj.GetString("contents"))
+			}
+			for _, oid := range list {
+				roles[oid] = "Contents"
+			}
+		case "FontDescriptor":
+			for _, k := range []string{"fontfile", "fontfile2", "fontfile3"} {
+				if oid := obj.GetRef(k); oid != "" {
+					roles[oid] = "FontFile"
+				}
+			}
+		case "":
+			untyped[obj.Oid] = size
+			return true
+		}
+		st.Types[typ] = st.Types[typ].add(size)
+		return true
+	})
+	for oid, size := range untyped {
+		typ := roles[oid]
+		st.Types[typ] = st.Types[typ].add(size)
+	}
+	return st, err
+}
+
+func statsType(obj Object) string {
+	if obj.IsImage() {
+		return "XObject/Image"
+	}
+	typ := obj.GetString("type")
+	if typ == "XObject" {
+		typ += "/" + obj.GetString("subtype")
+	}
+	return typ
+}
@@ -37,11 +37,14 @@ func convertString(str string) string {
 	return str
 }
 
-func decryptBytes(key, str []byte) []byte {
-	if len(key) == 0 {
+func decryptBytes(key *cryptKey, str []byte) []byte {
+	if key == nil || len(key.key) == 0 {
 		return str
 	}
-	ciph, err := rc4.NewCipher(key)
+	if key.aes {
+		return decryptAES(key.key, str)
+	}
+	ciph, err := rc4.NewCipher(key.key)
 	if err != nil {
 		return nil
 	}
@@ -49,29 +52,38 @@ func decryptBytes(key, str []byte) []byte {
 	return str
 }
 
-func decryptString(key []byte, str string) string {
+func decryptString(key *cryptKey, str string) string {
 	s := decryptBytes(key, []byte(str))
 	return string(s)
 }
 
-func getEncryptionKey(key []byte, oid, rev int) []byte {
-	if len(key) == 0 {
+func getEncryptionKey(key *cryptKey, oid, rev int) *cryptKey {
+	if key == nil || len(key.key) == 0 {
 		return nil
 	}
-	decrypt := make([]byte, len(key))
-	copy(decrypt, key)
+	if key.aes && len(key.key) == 32 {
+		return key
+	}
+	decrypt := make([]byte, len(key.key))
+	copy(decrypt, key.key)
 
 	decrypt = append(decrypt, byte(oid), byte(oid>>8), byte(oid>>16))
 	decrypt = append(decrypt, byte(rev), byte(rev>>8))
+	if key.aes {
+		decrypt = append(decrypt, salt...)
+	}
 
 	var (
 		sum  = md5.Sum(decrypt)
-		size = len(decrypt)
+		size = len(key.key) + 5
 	)
 	if size > MaxKeyLength {
 		size = MaxKeyLength
 	}
-	return sum[:size]
+	return &cryptKey{
+		key: sum[:size],
+		aes: key.aes,
+	}
 }
 
 func fromHexChar(b byte) (byte, bool) {
@@ -109,42 +121,8 @@ func parseTime(str string) (time.Time, error) {
 }
 
 func getPageContent(body []byte) []byte {
-	var (
-		r     = NewReader(body)
-		w     bytes.Buffer
-		stack []Token
-		line  string
-	)
-	for r.Len() > 0 {
-		tok := readToken(r)
-		if !tok.IsOperator() {
-			stack = append(stack, tok)
-			continue
-		}
-		if tok.IsShow() {
-			var str []string
-			for i := range stack {
-				if stack[i].Type == String {
-					str = append(str, stack[i].Literal)
-				}
-			}
-			s := strings.Join(str, "")
-			if line == "" && w.Len() > 0 {
-				w.WriteByte('\n')
-			}
-			if s = strings.TrimSpace(s); len(s) > 0 {
-				w.WriteString(s)
-			}
-		}
-		if tok.IsMatrix() || tok.Literal == "Td" || tok.Literal == "TD" {
-			offset := stack[len(stack)-1].Literal
-			if line != offset && offset != "0" {
-				w.WriteByte('\n')
-			}
-			line = offset
-		}
-		stack = stack[:0]
-	}
+	var w bytes.Buffer
+	NewContentParser().Parse(body, &w)
 	return w.Bytes()
 }
 
@@ -192,7 +170,7 @@ func readIdent(r *Reader) Token {
 	var str bytes.Buffer
 	for r.Len() > 0 {
 		b, _ := r.ReadByte()
-		if !isLetter(b) && !isQuote(b) {
+		if !isLetter(b) && !isQuote(b) && !(str.Len() > 0 && (b == '*' || isDigit(b))) {
 			r.UnreadByte()
 			break
 		}
@@ -221,14 +199,45 @@ func readName(r *Reader) Token {
 }
 
 func readString(r *Reader) Token {
-	var str bytes.Buffer
+	var (
+		str    bytes.Buffer
+		parens = 1
+	)
 	for r.Len() > 0 {
 		b, _ := r.ReadByte()
-		if b == rparen {
+		if b == lparen {
+			parens++
+		} else if b == rparen {
+			parens--
+		}
+		if b == rparen && parens == 0 {
 			break
 		}
 		if b == backslash {
 			b, _ = r.ReadByte()
+			switch b {
+			case nl:
+				continue
+			case cr:
+				if c, err := r.ReadByte(); err == nil && c != nl {
+					r.UnreadByte()
+				}
+				continue
+			case 'n':
+				b = nl
+			case 'r':
+				b = cr
+			case 't':
+				b = tab
+			case 'b':
+				b = backspace
+			case 'f':
+				b = formfeed
+			default:
+				if isOctal(b) {
+					b = readOctal(r, b)
+				}
+			}
 		}
 		str.WriteByte(b)
 	}
@@ -483,6 +492,10 @@ func isDigit(b byte) bool {
 	return b >= '0' && b <= '9'
 }
 
+func isOctal(b byte) bool {
+	return b >= '0' && b <= '7'
+}
+
 func isHex(b byte) bool {
 	return isDigit(b) || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'F')
 }
@@ -0,0 +1,239 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rc4"
+	"fmt"
+	"io"
+)
+
+type stream struct {
+	src    io.ReaderAt
+	offset int64
+	length int64
+	key    *cryptKey
+}
+
+func (s *stream) reader() io.Reader {
+	var rs io.Reader = io.NewSectionReader(s.src, s.offset, s.length)
+	switch {
+	case s.key == nil || len(s.key.key) == 0:
+	case s.key.aes:
+		if s.length == 0 {
+			return bytes.NewReader(nil)
+		}
+		if s.length < aes.BlockSize || s.length%aes.BlockSize != 0 {
+			return errReader{err: fmt.Errorf("aes: stream length is not a multiple of the block size")}
+		}
+		rs = newAESReader(s.key.key, rs)
+	default:
+		if c, err := rc4.NewCipher(s.key.key); err == nil {
+			rs = cipher.StreamReader{S: c, R: rs}
+		}
+	}
+	return rs
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(_ []byte) (int, error) {
+	return 0, r.err
+}
+
+func (o Object) HasStream() bool {
+	return o.stream != nil || o.Content != nil
+}
+
+// streamLength returns the length of the stream as stored in the file.
+func (o Object) streamLength() int64 {
+	if o.stream == nil {
+		return int64(len(o.Content))
+	}
+	return o.stream.length
+}
+
+func (o Object) Encoded() ([]byte, error) {
+	if o.stream == nil {
+		return o.Content, nil
+	}
+	return io.ReadAll(o.stream.reader())
+}
+
+// RawStream returns the decrypted stream without applying its filters.
+func (o Object) RawStream() ([]byte, error) {
+	if !o.HasStream() {
+		return nil, fmt.Errorf("%s: stream %w", o.Oid, ErrMissing)
+	}
+	return o.Encoded()
+}
+
+func (o Object) Reader() (io.ReadCloser, error) {
+	var rs io.Reader
+	if o.stream != nil {
+		rs = o.stream.reader()
+	} else {
+		rs = bytes.NewReader(o.Content)
+	}
+	var (
+		params  = o.getDecodeParms()
+		closers []io.Closer
+	)
+	for i, f := range o.getFilters() {
+		var parms Dict
+		if i < len(params) {
+			parms = params[i]
+		}
+		switch {
+		case isTextFilter(f):
+			rs = textDecoder(f, rs)
+		case f == filterFlate || f == "Fl":
+			z, err := zlib.NewReader(rs)
+			if err != nil {
+				return nil, err
+			}
+			rs = predict(z, parms)
+			closers = append(closers, z)
+		case f == "Crypt":
+		case f == filterDCT || f == filterJPX:
+			return readCloser{Reader: rs, closers: closers}, nil
+		default:
+			return nil, fmt.Errorf("%s: filter %s %w", o.Oid, f, ErrUnsupported)
+		}
+	}
+	return readCloser{Reader: rs, closers: closers}, nil
+}
+
+func predict(rs io.Reader, parms Dict) io.Reader {
+	var (
+		predictor = parms.GetInt("predictor")
+		columns   = parms.GetInt("columns")
+		colors    = parms.GetInt("colors")
+		bits      = parms.GetInt("bitspercomponent")
+	)
+	if predictor <= 1 || columns <= 0 {
+		return rs
+	}
+	if colors <= 0 {
+		colors = 1
+	}
+	if bits <= 0 {
+		bits = 8
+	}
+	var (
+		size = (columns*colors*bits + 7) / 8
+		bpp  = int((colors*bits + 7) / 8)
+	)
+	if predictor == 2 {
+		return &predictReader{
+			inner: rs,
+			row:   make([]byte, size),
+			tmp:   make([]byte, size),
+			bpp:   bpp,
+			tiff:  true,
+		}
+	}
+	return &predictReader{
+		inner: rs,
+		row:   make([]byte, size),
+		tmp:   make([]byte, size+1),
+		bpp:   bpp,
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r readCloser) Close() error {
+	var err error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		if e := r.closers[i].Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+type predictReader struct {
+	inner io.Reader
+	row   []byte
+	tmp   []byte
+	ready []byte
+	bpp   int
+	tiff  bool
+}
+
+func (p *predictReader) Read(b []byte) (int, error) {
+	if len(p.ready) == 0 {
+		if _, err := io.ReadFull(p.inner, p.tmp); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		if p.tiff {
+			p.unpredictTIFF()
+		} else {
+			p.unpredictPNG()
+		}
+		p.ready = p.row
+	}
+	n := copy(b, p.ready)
+	p.ready = p.ready[n:]
+	return n, nil
+}
+
+func (p *predictReader) unpredictTIFF() {
+	copy(p.row, p.tmp)
+	for j := p.bpp; j < len(p.row); j++ {
+		p.row[j] += p.row[j-p.bpp]
+	}
+}
+
+func (p *predictReader) unpredictPNG() {
+	var (
+		typ = p.tmp[0]
+		cur = p.tmp[1:]
+	)
+	for j := range p.row {
+		var left, upleft byte
+		if j >= p.bpp {
+			left, upleft = cur[j-p.bpp], p.row[j-p.bpp]
+		}
+		up := p.row[j]
+		switch typ {
+		case 1:
+			cur[j] += left
+		case 2:
+			cur[j] += up
+		case 3:
+			cur[j] += byte((int(left) + int(up)) / 2)
+		case 4:
+			cur[j] += paeth(left, up, upleft)
+		}
+	}
+	copy(p.row, cur)
+}
+
+func paeth(a, b, c byte) byte {
+	var (
+		p  = int64(a) + int64(b) - int64(c)
+		pa = abs(p - int64(a))
+		pb = abs(p - int64(b))
+		pc = abs(p - int64(c))
+	)
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	default:
+		return c
+	}
+}
@@ -0,0 +1,163 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type ParseMode int
+
+const (
+	ParseDefault ParseMode = iota
+	ParseLenient
+	ParseStrict
+)
+
+// WithParseMode changes how deviations from the specification are handled.
+// In lenient mode, wrong stream lengths, junk before the header, missing
+// whitespace after object keywords and mismatching generation numbers are
+// tolerated. In strict mode, every object of the cross reference table is
+// checked when the document is opened and the deviations found are returned
+// as ParseErrors.
+func WithParseMode(mode ParseMode) Option {
+	return func(d *Document) error {
+		d.mode = mode
+		return nil
+	}
+}
+
+var ErrMalformed = errors.New("malformed")
+
+type ParseError struct {
+	Offset int64
+	Oid    string
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	if e.Oid == "" {
+		return fmt.Sprintf("offset %d: %s", e.Offset, e.Reason)
+	}
+	return fmt.Sprintf("%s (offset %d): %s", e.Oid, e.Offset, e.Reason)
+}
+
+func (e *ParseError) Unwrap() error {
+	return ErrMalformed
+}
+
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	var list []string
+	for _, err := range e {
+		list = append(list, err.Error())
+	}
+	return fmt.Sprintf("%d deviations found: %s", len(e), strings.Join(list, "; "))
+}
+
+func (e ParseErrors) Is(target error) bool {
+	return target == ErrMalformed
+}
+
+func (d *Document) lenient() bool {
+	return d.mode == ParseLenient
+}
+
+func (d *Document) strict() bool {
+	return d.mode == ParseStrict
+}
+
+// skipJunk looks for the header of the file in its first bytes and makes the
+// document starts at its position.
+func (d *Document) skipJunk() {
+	r, err := d.section(0, MinRead)
+	if err != nil {
+		return
+	}
+	x := bytes.Index(r.Bytes(), magic)
+	if x <= 0 {
+		return
+	}
+	offset := int64(x)
+	if r, ok := d.src.(*Reader); ok {
+		d.src = r.Section(offset, d.size-offset)
+	} else {
+		d.src = io.NewSectionReader(d.src, offset, d.size-offset)
+	}
+	d.size -= offset
+}
+
+// validate checks the header of each object of the cross reference table
+// and the length of their stream.
+func (d *Document) validate() error {
+	var errs ParseErrors
+	for _, p := range d.xref {
+		if p.isEmbed() {
+			continue
+		}
+		if err := d.validateObject(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (d *Document) validateObject(p Pointer) *ParseError {
+	fail := func(format string, args ...interface{}) *ParseError {
+		return &ParseError{
+			Offset: p.Offset,
+			Oid:    p.Oid,
+			Reason: fmt.Sprintf(format, args...),
+		}
+	}
+	r, err := d.section(p.Offset, MinRead)
+	if err != nil {
+		return fail("%s", err)
+	}
+	var (
+		buf    = r.Bytes()
+		fields = bytes.Fields(buf)
+	)
+	if len(fields) < 3 {
+		return fail("object header %s", ErrMissing)
+	}
+	num, err1 := strconv.Atoi(string(fields[0]))
+	gen, err2 := strconv.Atoi(string(fields[1]))
+	if err1 != nil || err2 != nil {
+		return fail("invalid object header")
+	}
+	switch id, rev := parseOid(p.Oid); {
+	case num != id:
+		return fail("object %d found instead of %d", num, id)
+	case gen != rev:
+		return fail("generation %d found instead of %d", gen, rev)
+	}
+	if !bytes.Equal(fields[2], begobj) {
+		if bytes.HasPrefix(fields[2], begobj) {
+			return fail("missing whitespace after %s", begobj)
+		}
+		return fail("%s keyword %s", begobj, ErrMissing)
+	}
+	obj, err := d.readObjectAt(p.Offset, d.keyFor(p.Oid), true)
+	if err != nil {
+		return fail("%s", err)
+	}
+	if obj.stream == nil {
+		return nil
+	}
+	r, err = d.section(obj.stream.offset, obj.stream.length+MinRead)
+	if err != nil {
+		return fail("%s", err)
+	}
+	if size := scanStreamLength(r, obj.stream.length); size != obj.stream.length {
+		return fail("stream length %d instead of %d", obj.stream.length, size)
+	}
+	return nil
+}
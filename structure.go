@@ -0,0 +1,234 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type markedContent struct {
+	tag  string
+	mcid int
+}
+
+type runMark struct {
+	mcid     int
+	artifact bool
+}
+
+func (t *textState) beginMarked(_ io.Writer, op Operator) error {
+	t.flush()
+	mc := markedContent{mcid: -1}
+	if len(op.Args) > 0 && op.Args[0].Type == Name {
+		mc.tag = op.Args[0].Literal
+	}
+	for i := 1; i+1 < len(op.Args); i++ {
+		if op.Args[i].Type == Name && op.Args[i].Literal == "MCID" && op.Args[i+1].Type == Number {
+			mc.mcid, _ = strconv.Atoi(op.Args[i+1].Literal)
+		}
+	}
+	if len(op.Args) == 2 && op.Args[1].Type == Name && t.props != nil {
+		if props, ok := t.props(op.Args[1].Literal); ok && props.Has("mcid") {
+			mc.mcid = int(props.GetInt("mcid"))
+		}
+	}
+	t.marks = append(t.marks, mc)
+	return nil
+}
+
+func (t *textState) endMarked(_ io.Writer, _ Operator) error {
+	t.flush()
+	if n := len(t.marks); n > 0 {
+		t.marks = t.marks[:n-1]
+	}
+	return nil
+}
+
+func (t *textState) currentMark() runMark {
+	m := runMark{mcid: -1}
+	for i := len(t.marks) - 1; i >= 0; i-- {
+		if t.marks[i].tag == "Artifact" {
+			m.artifact = true
+		}
+		if m.mcid < 0 {
+			m.mcid = t.marks[i].mcid
+		}
+	}
+	return m
+}
+
+// getStructuredText returns the text of the page following the order of the
+// structure tree. Block level elements are written on their own lines,
+// artifacts are dropped and the content that does not belong to the tree is
+// written at the end in stream order.
+func (d *Document) getStructuredText(page int) (string, error) {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return "", fmt.Errorf("page %d not found in document", page)
+	}
+	blocks := d.getStructBlocks(obj.Oid)
+	if len(blocks) == 0 {
+		return d.GetTextWith(page, TextReading)
+	}
+	t := newTextState(nil)
+	if err := d.runText(page, t, io.Discard); err != nil {
+		return "", err
+	}
+	var (
+		content = make(map[int][]TextRun)
+		rest    []TextRun
+	)
+	for i, r := range t.runs {
+		switch m := t.tags[i]; {
+		case m.artifact:
+		case m.mcid >= 0:
+			content[m.mcid] = append(content[m.mcid], r)
+		default:
+			rest = append(rest, r)
+		}
+	}
+	var buf strings.Builder
+	for _, b := range blocks {
+		var runs []TextRun
+		for _, mcid := range b {
+			runs = append(runs, content[mcid]...)
+			delete(content, mcid)
+		}
+		writeStructBlock(&buf, runs)
+	}
+	var orphans []TextRun
+	for i, r := range t.runs {
+		if m := t.tags[i]; !m.artifact && m.mcid >= 0 {
+			if _, ok := content[m.mcid]; ok {
+				orphans = append(orphans, r)
+			}
+		}
+	}
+	writeStructBlock(&buf, append(orphans, rest...))
+	return buf.String(), nil
+}
+
+func writeStructBlock(buf *strings.Builder, runs []TextRun) {
+	var written bool
+	for i, r := range runs {
+		text := r.Text
+		if i > 0 {
+			prev := runs[i-1]
+			if math.Abs(prev.Rect[1]-r.Rect[1]) > r.Size/2 || needSpace(prev, r) {
+				if !strings.HasSuffix(prev.Text, " ") && !strings.HasPrefix(text, " ") {
+					buf.WriteByte(space)
+				}
+			}
+		}
+		buf.WriteString(text)
+		written = written || strings.TrimSpace(text) != ""
+	}
+	if written {
+		buf.WriteByte(nl)
+	}
+}
+
+type structWalker struct {
+	doc    *Document
+	page   string
+	roles  Dict
+	seen   map[string]bool
+	blocks [][]int
+	cur    []int
+}
+
+func (d *Document) getStructBlocks(page string) [][]int {
+	root := d.getCatalog()
+	if root.isZero() {
+		return nil
+	}
+	tree, _ := d.resolve(root.getValue("structtreeroot")).(Dict)
+	if tree == nil {
+		return nil
+	}
+	w := structWalker{
+		doc:  d,
+		page: page,
+		seen: make(map[string]bool),
+	}
+	w.roles, _ = d.resolve(tree.getValue("rolemap")).(Dict)
+	w.walk(tree.getValue("k"), "")
+	w.breakBlock()
+	return w.blocks
+}
+
+func (w *structWalker) walk(v Value, pg string) {
+	if oid, ok := toOid(v); ok {
+		if w.seen[oid] {
+			return
+		}
+		w.seen[oid] = true
+	}
+	switch v := w.doc.resolve(v).(type) {
+	case int64:
+		if pg == w.page {
+			w.cur = append(w.cur, int(v))
+		}
+	case []interface{}:
+		for i := range v {
+			w.walk(v[i], pg)
+		}
+	case Dict:
+		if str := v.GetString("pg"); str != "" {
+			pg = str
+		}
+		switch v.Type() {
+		case "MCR":
+			if pg == w.page && !v.Has("stm") {
+				w.cur = append(w.cur, int(v.GetInt("mcid")))
+			}
+			return
+		case "OBJR":
+			return
+		}
+		role := w.role(v.GetString("s"))
+		if role == "Artifact" {
+			return
+		}
+		if isInlineElement(role) {
+			w.walk(v.getValue("k"), pg)
+			return
+		}
+		w.breakBlock()
+		w.walk(v.getValue("k"), pg)
+		w.breakBlock()
+	}
+}
+
+func (w *structWalker) breakBlock() {
+	if len(w.cur) > 0 {
+		w.blocks = append(w.blocks, w.cur)
+		w.cur = nil
+	}
+}
+
+func (w *structWalker) role(tag string) string {
+	seen := make(map[string]bool)
+	for !seen[tag] {
+		seen[tag] = true
+		next := w.roles.GetString(tag)
+		if next == "" {
+			break
+		}
+		tag = next
+	}
+	return tag
+}
+
+func isInlineElement(role string) bool {
+	switch role {
+	case "Span", "Quote", "Note", "Reference", "BibEntry", "Code", "Link", "Annot",
+		"Ruby", "RB", "RT", "RP", "Warichu", "WT", "WP", "Em", "Strong", "Sub",
+		"Lbl", "LBody":
+		return true
+	default:
+		return false
+	}
+}
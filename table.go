@@ -0,0 +1,172 @@
+package pdf
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+type Table struct {
+	Page int
+	Rect Rect
+	Rows [][]string
+}
+
+type tableCell struct {
+	Text string
+	Rect Rect
+}
+
+func (d *Document) GetTables(page int) ([]Table, error) {
+	t, err := d.extractText(page, nil, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		list  []Table
+		block [][]tableCell
+		prev  Rect
+	)
+	for _, line := range groupLines(t.runs) {
+		cells := splitCells(line, t.rules)
+		if len(block) > 0 {
+			height := prev[3] - prev[1]
+			if len(cells) < 2 || prev[1]-cells[0].Rect[3] > 2*height {
+				if tb, ok := makeTable(page, block); ok {
+					list = append(list, tb)
+				}
+				block = block[:0]
+			}
+		}
+		if len(cells) >= 2 {
+			block = append(block, cells)
+			prev = cells[0].Rect
+		}
+	}
+	if tb, ok := makeTable(page, block); ok {
+		list = append(list, tb)
+	}
+	return list, nil
+}
+
+func groupLines(runs []TextRun) [][]TextRun {
+	runs = append([]TextRun{}, runs...)
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Rect[1] > runs[j].Rect[1]
+	})
+	var lines [][]TextRun
+	for _, r := range runs {
+		n := len(lines)
+		if n > 0 {
+			var (
+				last = lines[n-1][0]
+				mid  = (r.Rect[1] + r.Rect[3]) / 2
+			)
+			if mid >= last.Rect[1] && mid <= last.Rect[3] {
+				lines[n-1] = append(lines[n-1], r)
+				continue
+			}
+		}
+		lines = append(lines, []TextRun{r})
+	}
+	for _, line := range lines {
+		sort.SliceStable(line, func(i, j int) bool {
+			return line[i].Rect[0] < line[j].Rect[0]
+		})
+	}
+	return lines
+}
+
+func splitCells(line []TextRun, rules []Rect) []tableCell {
+	var cells []tableCell
+	for _, r := range line {
+		n := len(cells)
+		if n > 0 {
+			var (
+				last = &cells[n-1]
+				gap  = r.Rect[0] - last.Rect[2]
+			)
+			if gap < r.Size*0.8 && !hasRuleBetween(rules, last.Rect, r.Rect) {
+				if gap > r.Size*0.1 && !strings.HasSuffix(last.Text, " ") {
+					last.Text += " "
+				}
+				last.Text += r.Text
+				last.Rect = last.Rect.union(r.Rect)
+				continue
+			}
+		}
+		cells = append(cells, tableCell{
+			Text: r.Text,
+			Rect: r.Rect,
+		})
+	}
+	for i := range cells {
+		cells[i].Text = strings.TrimSpace(cells[i].Text)
+	}
+	return cells
+}
+
+func hasRuleBetween(rules []Rect, left, right Rect) bool {
+	var (
+		bottom = math.Max(left[1], right[1])
+		top    = math.Min(left[3], right[3])
+	)
+	for _, r := range rules {
+		if r[2]-r[0] > 1 {
+			continue
+		}
+		if r[0] >= left[2] && r[0] <= right[0] && r[1] <= top && r[3] >= bottom {
+			return true
+		}
+	}
+	return false
+}
+
+func makeTable(page int, block [][]tableCell) (Table, bool) {
+	if len(block) < 2 {
+		return Table{}, false
+	}
+	var cols [][2]float64
+	for _, line := range block {
+		for _, c := range line {
+			cols = append(cols, [2]float64{c.Rect[0], c.Rect[2]})
+		}
+	}
+	sort.Slice(cols, func(i, j int) bool {
+		return cols[i][0] < cols[j][0]
+	})
+	var merged [][2]float64
+	for _, c := range cols {
+		n := len(merged)
+		if n > 0 && c[0] <= merged[n-1][1] {
+			merged[n-1][1] = math.Max(merged[n-1][1], c[1])
+			continue
+		}
+		merged = append(merged, c)
+	}
+	if len(merged) < 2 {
+		return Table{}, false
+	}
+	tb := Table{
+		Page: page,
+		Rect: block[0][0].Rect,
+	}
+	for _, line := range block {
+		row := make([]string, len(merged))
+		for _, c := range line {
+			tb.Rect = tb.Rect.union(c.Rect)
+			for i := range merged {
+				if c.Rect[0] >= merged[i][0] && c.Rect[0] <= merged[i][1] {
+					if row[i] != "" {
+						row[i] += " "
+					}
+					row[i] += c.Text
+					break
+				}
+			}
+		}
+		tb.Rows = append(tb.Rows, row)
+	}
+	return tb, true
+}
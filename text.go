@@ -0,0 +1,571 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type textFont struct {
+	oid   string
+	name  string
+	enc   *cmap
+	cmap  *cmap
+	codes map[uint32]rune
+	size  int
+
+	widths  map[uint32]float64
+	missing float64
+}
+
+func (f *textFont) width(code uint32) float64 {
+	if f.enc != nil {
+		switch cid, ok := f.enc.cid(code); {
+		case ok:
+			code = cid
+		case f.enc.text != nil:
+			return f.missing
+		}
+	}
+	if w, ok := f.widths[code]; ok {
+		return w
+	}
+	return f.missing
+}
+
+func (f *textFont) spaceWidth() float64 {
+	if w, ok := f.widths[' ']; ok && f.size == 1 && w > 0 {
+		return w
+	}
+	return 250
+}
+
+func (f *textFont) split(str string) (uint32, int) {
+	switch {
+	case f.enc != nil:
+		return f.enc.split(str)
+	case f.cmap != nil && len(f.cmap.ranges) > 0:
+		return f.cmap.split(str)
+	}
+	var (
+		code uint32
+		n    int
+	)
+	for ; n < f.size && n < len(str); n++ {
+		code = code<<8 | uint32(str[n])
+	}
+	return code, n
+}
+
+func (f *textFont) decode(str string) string {
+	var buf strings.Builder
+	for i, n := 0, 0; i < len(str); i += n {
+		var code uint32
+		if code, n = f.split(str[i:]); n == 0 {
+			break
+		}
+		buf.WriteString(f.decodeCode(code))
+	}
+	return buf.String()
+}
+
+func (f *textFont) decodeCode(code uint32) string {
+	for _, cm := range []*cmap{f.cmap, f.enc} {
+		if cm == nil {
+			continue
+		}
+		if s, ok := cm.lookup(code); ok {
+			return s
+		}
+	}
+	r, ok := f.codes[code]
+	switch {
+	case ok:
+	case f.size == 1:
+		r = rune(code)
+	default:
+		r = utf8.RuneError
+	}
+	return string(r)
+}
+
+type Rect [4]float64
+
+type Matrix [6]float64
+
+func (r Rect) union(other Rect) Rect {
+	return Rect{
+		math.Min(r[0], other[0]),
+		math.Min(r[1], other[1]),
+		math.Max(r[2], other[2]),
+		math.Max(r[3], other[3]),
+	}
+}
+
+func (r Rect) Contains(x, y float64) bool {
+	return x >= r[0] && x <= r[2] && y >= r[1] && y <= r[3]
+}
+
+func (d *Document) GetText(page int) (string, error) {
+	return d.getText(page, nil)
+}
+
+func (d *Document) GetTextInRect(page int, rect Rect) (string, error) {
+	if rect[0] > rect[2] {
+		rect[0], rect[2] = rect[2], rect[0]
+	}
+	if rect[1] > rect[3] {
+		rect[1], rect[3] = rect[3], rect[1]
+	}
+	return d.getText(page, &rect)
+}
+
+func (d *Document) GetTextRuns(page int) ([]TextRun, error) {
+	t, err := d.extractText(page, nil, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	return t.runs, nil
+}
+
+// GetTextContext is like GetText but gives up as soon as ctx is done.
+func (d *Document) GetTextContext(ctx context.Context, page int) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	var w bytes.Buffer
+	t := newTextState(nil)
+	t.ctx = ctx
+	if err := d.runText(page, t, &w); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+func (d *Document) getText(page int, rect *Rect) (string, error) {
+	var w bytes.Buffer
+	if _, err := d.extractText(page, rect, &w); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+func (d *Document) extractText(page int, rect *Rect, w io.Writer) (*textState, error) {
+	t := newTextState(nil)
+	t.rect = rect
+	return t, d.runText(page, t, w)
+}
+
+func (d *Document) runText(page int, t *textState, w io.Writer) error {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return fmt.Errorf("page %d not found in document", page)
+	}
+	body, err := d.GetPageCode(page)
+	if err != nil {
+		return err
+	}
+	t.page = page
+	if err := d.parseText(body, d.getPageResources(obj), t, defaultGraphicsState(), 0, w); err != nil {
+		return err
+	}
+	t.flush()
+	return nil
+}
+
+// parseText extracts the text of a content stream and of the forms it draws.
+func (d *Document) parseText(body []byte, res Dict, t *textState, gs GraphicsState, depth int, w io.Writer) error {
+	props, _ := d.resolve(res.getValue("properties")).(Dict)
+	t.fonts = d.getResourceFonts(res)
+	t.props = func(name string) (Dict, bool) {
+		dict, ok := d.resolve(props.getValue(name)).(Dict)
+		return dict, ok
+	}
+
+	p := NewContentParser()
+	p.state = gs
+	p.text = t
+	p.Register("BMC", t.beginMarked)
+	p.Register("BDC", t.beginMarked)
+	p.Register("EMC", t.endMarked)
+	p.Register("Do", func(w io.Writer, op Operator) error {
+		if len(op.Args) == 0 || depth >= maxFormDepth {
+			return nil
+		}
+		var (
+			xobj, _ = d.resolve(res.getValue("xobject")).(Dict)
+			form    = d.getObjectWithOid(xobj.GetString(op.Args[0].Literal), true)
+		)
+		if form.Subtype() != "Form" {
+			return nil
+		}
+		body, err := form.Body()
+		if err != nil {
+			return nil
+		}
+		sub, ok := d.resolve(form.getValue("resources")).(Dict)
+		if !ok {
+			sub = res
+		}
+		gs := p.state
+		if m, ok := d.getMatrix(form.getValue("matrix")); ok {
+			gs.CTM = multiplyMatrix(m, gs.CTM)
+		}
+		font, fonts, props := t.font, t.fonts, t.props
+		defer func() {
+			t.font, t.fonts, t.props, t.gs = font, fonts, props, &p.state
+		}()
+		return d.parseText(body, sub, t, gs, depth+1, w)
+	})
+	t.gs = &p.state
+	p.ctx = t.ctx
+	return p.Parse(body, w)
+}
+
+func (d *Document) getTextFonts(page Object) map[string]*textFont {
+	return d.getResourceFonts(d.getPageResources(page))
+}
+
+func (d *Document) getResourceFonts(res Dict) map[string]*textFont {
+	var (
+		fonts, _ = d.resolve(res.getValue("font")).(Dict)
+		list     = make(map[string]*textFont)
+	)
+	for name, v := range fonts {
+		var obj Object
+		if dict, ok := v.(Dict); ok {
+			obj.Dict = dict
+		} else if oid, ok := toOid(v); ok {
+			obj = d.getObjectWithOid(oid, false)
+		}
+		if obj.Dict == nil {
+			continue
+		}
+		list[name] = d.getTextFont(obj)
+	}
+	return list
+}
+
+func (d *Document) getTextFont(obj Object) *textFont {
+	f := textFont{
+		oid:  obj.Oid,
+		name: obj.GetString("basefont"),
+		cmap: d.getToUnicode(obj),
+		size: 1,
+	}
+	if obj.Subtype() == "Type0" {
+		f.enc = d.getEncodingCMap(obj)
+		f.codes = d.getCIDFontCodes(obj)
+		f.size = 2
+		d.getCIDWidths(obj, &f)
+	} else {
+		f.codes = d.getEncodingCodes(obj)
+		d.getSimpleWidths(obj, &f)
+	}
+	return &f
+}
+
+func (d *Document) getSimpleWidths(obj Object, f *textFont) {
+	var (
+		first     = obj.GetInt("firstchar")
+		widths, _ = d.resolve(obj.getValue("widths")).([]interface{})
+		desc      = d.getObjectWithOid(obj.GetString("fontdescriptor"), false)
+		scale     = 1.0
+	)
+	if obj.Subtype() == "Type3" {
+		scale = d.getFontMatrix(obj)[0] * 1000
+	}
+	f.widths = make(map[uint32]float64)
+	for i := range widths {
+		f.widths[uint32(first)+uint32(i)] = getNumber(d.resolve(widths[i])) * scale
+	}
+	if f.missing = getNumber(d.resolve(desc.getValue("missingwidth"))); f.missing == 0 && len(widths) == 0 {
+		f.missing = 500
+	}
+}
+
+func (d *Document) getCIDWidths(obj Object, f *textFont) {
+	f.widths = make(map[uint32]float64)
+	f.missing = 1000
+	kids, _ := d.resolve(obj.getValue("descendantfonts")).([]interface{})
+	if len(kids) == 0 {
+		return
+	}
+	cid, _ := d.resolve(kids[0]).(Dict)
+	if cid.Has("dw") {
+		f.missing = getNumber(d.resolve(cid.getValue("dw")))
+	}
+	list, _ := d.resolve(cid.getValue("w")).([]interface{})
+	for i := 0; i+1 < len(list); {
+		first, _ := d.resolve(list[i]).(int64)
+		switch next := d.resolve(list[i+1]).(type) {
+		case []interface{}:
+			for j := range next {
+				f.widths[uint32(first)+uint32(j)] = getNumber(d.resolve(next[j]))
+			}
+			i += 2
+		default:
+			if i+2 >= len(list) {
+				return
+			}
+			last, _ := next.(int64)
+			w := getNumber(d.resolve(list[i+2]))
+			for c := first; c <= last && last-first < 0x10000; c++ {
+				f.widths[uint32(c)] = w
+			}
+			i += 3
+		}
+	}
+}
+
+type TextRun struct {
+	Page int
+	Text string
+	Font string
+	Size float64
+	Rect Rect
+}
+
+type textRun struct {
+	TextRun
+	font *textFont
+}
+
+func (r *textRun) add(str string, box Rect) {
+	if r.Text == "" {
+		r.Rect = box
+	} else {
+		r.Rect = r.Rect.union(box)
+	}
+	r.Text += str
+}
+
+type textGlyph struct {
+	offset int
+	size   int
+	box    Rect
+}
+
+type textState struct {
+	fonts map[string]*textFont
+	font  *textFont
+	gs    *GraphicsState
+
+	page   int
+	offset int
+	glyphs []textGlyph
+	run    *textRun
+	runs   []TextRun
+	rules  []Rect
+	path   [2]float64
+
+	rect  *Rect
+	x, y  float64
+	shown bool
+	last  byte
+
+	marks []markedContent
+	tags  []runMark
+	props func(string) (Dict, bool)
+
+	ctx context.Context
+}
+
+func newTextState(fonts map[string]*textFont) *textState {
+	gs := defaultGraphicsState()
+	return &textState{
+		fonts: fonts,
+		font:  &textFont{size: 1, missing: 500},
+		gs:    &gs,
+	}
+}
+
+func (t *textState) apply(w io.Writer, op Operator) {
+	nums := tokenNumbers(op.Args)
+	switch op.Name {
+	case "m":
+		if len(nums) >= 2 {
+			t.path = [2]float64{nums[0], nums[1]}
+		}
+	case "l":
+		if len(nums) >= 2 {
+			t.rule(t.path[0], t.path[1], nums[0], nums[1])
+			t.path = [2]float64{nums[0], nums[1]}
+		}
+	case "re":
+		if len(nums) < 4 {
+			break
+		}
+		x, y, w, h := nums[0], nums[1], nums[2], nums[3]
+		t.rule(x, y, x+w, y)
+		t.rule(x+w, y, x+w, y+h)
+		t.rule(x, y+h, x+w, y+h)
+		t.rule(x, y, x, y+h)
+	case "Tf":
+		if f, ok := t.fonts[strings.ToLower(t.gs.Font)]; ok {
+			t.font = f
+		}
+	case "'", "\"":
+		if len(op.Args) == 0 || op.Args[len(op.Args)-1].Type != String {
+			break
+		}
+		t.show(w, op.Args[len(op.Args)-1].Literal)
+	case "Tj", "TJ":
+		for _, a := range op.Args {
+			switch a.Type {
+			case String:
+				t.show(w, a.Literal)
+			case Number:
+				n, _ := strconv.ParseFloat(a.Literal, 64)
+				t.gs.advanceText(-n / 1000 * t.gs.FontSize * t.gs.Scale)
+			}
+		}
+	}
+}
+
+func (t *textState) show(w io.Writer, str string) {
+	skipped := true
+	for i, n := 0, 0; i < len(str); i += n {
+		var code uint32
+		if code, n = t.font.split(str[i:]); n == 0 {
+			break
+		}
+		tx := t.font.width(code)/1000*t.gs.FontSize + t.gs.CharSpace
+		if n == 1 && code == ' ' {
+			tx += t.gs.WordSpace
+		}
+		tx *= t.gs.Scale
+		if !t.inside(t.point(tx/2, 0)) {
+			t.gs.advanceText(tx)
+			skipped = true
+			continue
+		}
+		if skipped && (t.separate(w) || t.run == nil || t.run.font != t.font || t.run.Size != t.fontSize()) {
+			t.flush()
+			t.run = &textRun{
+				TextRun: TextRun{
+					Page: t.page,
+					Font: t.font.name,
+					Size: t.fontSize(),
+				},
+				font: t.font,
+			}
+		}
+		var (
+			s   = t.font.decodeCode(code)
+			box = t.glyphBox(tx)
+		)
+		if len(s) > 0 {
+			io.WriteString(w, s)
+			t.last = s[len(s)-1]
+		}
+		if t.glyphs != nil {
+			t.glyphs = append(t.glyphs, textGlyph{
+				offset: t.offset,
+				size:   len(s),
+				box:    box,
+			})
+		}
+		t.offset += len(s)
+		t.run.add(s, box)
+		t.gs.advanceText(tx)
+		t.x, t.y, t.shown = t.gs.TextMatrix[4], t.gs.TextMatrix[5], true
+		skipped = false
+	}
+}
+
+func (t *textState) rule(x0, y0, x1, y1 float64) {
+	x0, y0 = t.gs.Transform(x0, y0)
+	x1, y1 = t.gs.Transform(x1, y1)
+	if math.Abs(x1-x0) > 1 && math.Abs(y1-y0) > 1 {
+		return
+	}
+	r := Rect{math.Min(x0, x1), math.Min(y0, y1), math.Max(x0, x1), math.Max(y0, y1)}
+	t.rules = append(t.rules, r)
+}
+
+func (t *textState) flush() {
+	if t.run != nil && t.run.Text != "" {
+		t.runs = append(t.runs, t.run.TextRun)
+		t.tags = append(t.tags, t.currentMark())
+	}
+	t.run = nil
+}
+
+func (t *textState) fontSize() float64 {
+	m := multiplyMatrix(t.gs.TextMatrix, t.gs.CTM)
+	return math.Abs(t.gs.FontSize) * math.Hypot(m[2], m[3])
+}
+
+func (t *textState) point(tx, ty float64) (float64, float64) {
+	var (
+		tm = t.gs.TextMatrix
+		x  = tm[0]*tx + tm[2]*ty + tm[4]
+		y  = tm[1]*tx + tm[3]*ty + tm[5]
+	)
+	return t.gs.Transform(x, y)
+}
+
+func (t *textState) glyphBox(tx float64) Rect {
+	var (
+		descent = -0.2*t.gs.FontSize + t.gs.Rise
+		ascent  = 0.8*t.gs.FontSize + t.gs.Rise
+		rect    = Rect{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	)
+	for _, c := range [][2]float64{{0, descent}, {tx, descent}, {0, ascent}, {tx, ascent}} {
+		x, y := t.point(c[0], c[1])
+		rect[0], rect[1] = math.Min(rect[0], x), math.Min(rect[1], y)
+		rect[2], rect[3] = math.Max(rect[2], x), math.Max(rect[3], y)
+	}
+	return rect
+}
+
+func (t *textState) inside(x, y float64) bool {
+	return t.rect == nil || t.rect.Contains(x, y)
+}
+
+func (t *textState) separate(w io.Writer) bool {
+	if !t.shown {
+		return false
+	}
+	var (
+		tm     = t.gs.TextMatrix
+		dx     = tm[4] - t.x
+		dy     = tm[5] - t.y
+		hs     = math.Hypot(tm[0], tm[1])
+		vs     = math.Hypot(tm[2], tm[3])
+		along  = dx
+		across = dy
+	)
+	if hs > 0 {
+		along = (dx*tm[0] + dy*tm[1]) / hs
+		across = (dy*tm[0] - dx*tm[1]) / hs
+	}
+	var (
+		height = math.Abs(t.gs.FontSize * vs)
+		gap    = t.font.spaceWidth() / 1000 * math.Abs(t.gs.FontSize*t.gs.Scale) * hs / 2
+	)
+	switch {
+	case math.Abs(across) > height/2:
+		t.write(w, nl)
+	case along > gap || along < -height:
+		t.write(w, space)
+	default:
+		return false
+	}
+	return true
+}
+
+func (t *textState) write(w io.Writer, b byte) {
+	if t.last == nl || (t.last == space && b == space) {
+		return
+	}
+	w.Write([]byte{b})
+	t.last = b
+	t.offset++
+}
@@ -0,0 +1,84 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Thread is an article, its beads given in reading order.
+type Thread struct {
+	Title   string
+	Author  string
+	Subject string
+	Beads   []Bead
+}
+
+type Bead struct {
+	Page int
+	Rect Rect
+}
+
+func (d *Document) GetThreads() []Thread {
+	root := d.getCatalog()
+	if root.isZero() {
+		return nil
+	}
+	var (
+		threads, _ = d.resolve(root.getValue("threads")).([]interface{})
+		pages      = d.getPageNumbers()
+		list       []Thread
+	)
+	for _, v := range threads {
+		dict, _ := d.resolve(v).(Dict)
+		if dict == nil {
+			continue
+		}
+		var t Thread
+		if info, _ := d.resolve(dict.getValue("i")).(Dict); info != nil {
+			t.Title = d.resolveString(info.getValue("title"))
+			t.Author = d.resolveString(info.getValue("author"))
+			t.Subject = d.resolveString(info.getValue("subject"))
+		}
+		t.Beads = d.getBeads(dict.GetString("f"), pages)
+		list = append(list, t)
+	}
+	return list
+}
+
+func (d *Document) getBeads(first string, pages map[string]int) []Bead {
+	var (
+		list []Bead
+		seen = make(map[string]bool)
+	)
+	for oid := first; oid != "" && !seen[oid]; {
+		seen[oid] = true
+		obj := d.getObjectWithOid(oid, false)
+		if obj.isZero() {
+			break
+		}
+		b := Bead{
+			Page: pages[obj.GetString("p")],
+			Rect: Rect(getRect(d.resolve(obj.getValue("r")))),
+		}
+		list = append(list, b)
+		oid = obj.GetString("n")
+	}
+	return list
+}
+
+func (d *Document) GetThreadText(t Thread) (string, error) {
+	var parts []string
+	for _, b := range t.Beads {
+		if b.Page == 0 {
+			continue
+		}
+		str, err := d.GetTextInRect(b.Page, b.Rect)
+		if err != nil {
+			return "", fmt.Errorf("bead on page %d: %w", b.Page, err)
+		}
+		if str = strings.TrimSpace(str); str != "" {
+			parts = append(parts, str)
+		}
+	}
+	return strings.Join(parts, "\n"), nil
+}
@@ -0,0 +1,190 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	oidTSTInfo            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidSignatureTimeStamp = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+)
+
+type TimeStamp struct {
+	Time      time.Time
+	Policy    string
+	Serial    *big.Int
+	Hash      crypto.Hash
+	Imprint   []byte
+	Authority string
+	Signer    *x509.Certificate
+}
+
+type DSS struct {
+	Certs []*x509.Certificate
+	CRLs  [][]byte
+	OCSPs [][]byte
+	VRI   map[string]VRI
+}
+
+type VRI struct {
+	Certs []*x509.Certificate
+	CRLs  [][]byte
+	OCSPs [][]byte
+	When  time.Time
+}
+
+// GetDSS returns the document security store of the catalog: the
+// certificates, CRLs and OCSP responses gathered for long term validation.
+// The VRI entries are keyed by the uppercase hex SHA-1 of the signature
+// contents they relate to.
+func (d *Document) GetDSS() (DSS, error) {
+	var dss DSS
+	root := d.getCatalog()
+	if root.isZero() {
+		return dss, fmt.Errorf("catalog %w", ErrMissing)
+	}
+	dict, ok := d.resolve(root.getValue("dss")).(Dict)
+	if !ok {
+		return dss, fmt.Errorf("dss %w", ErrMissing)
+	}
+	dss.Certs = d.getCertificates(dict.getValue("certs"))
+	dss.CRLs = d.getStreamBodies(dict.getValue("crls"))
+	dss.OCSPs = d.getStreamBodies(dict.getValue("ocsps"))
+
+	vri, _ := d.resolve(dict.getValue("vri")).(Dict)
+	if len(vri) > 0 {
+		dss.VRI = make(map[string]VRI)
+	}
+	for key, v := range vri {
+		entry, ok := d.resolve(v).(Dict)
+		if !ok {
+			continue
+		}
+		x := VRI{
+			Certs: d.getCertificates(entry.getValue("cert")),
+			CRLs:  d.getStreamBodies(entry.getValue("crl")),
+			OCSPs: d.getStreamBodies(entry.getValue("ocsp")),
+		}
+		x.When, _ = d.parseDate(d.resolveString(entry.getValue("tu")))
+		dss.VRI[strings.ToUpper(key)] = x
+	}
+	return dss, nil
+}
+
+func (d *Document) getCertificates(v Value) []*x509.Certificate {
+	var list []*x509.Certificate
+	for _, body := range d.getStreamBodies(v) {
+		if cert, err := x509.ParseCertificate(body); err == nil {
+			list = append(list, cert)
+		}
+	}
+	return list
+}
+
+func (d *Document) getStreamBodies(v Value) [][]byte {
+	arr, _ := d.resolve(v).([]interface{})
+	var list [][]byte
+	for i := range arr {
+		oid, _ := toOid(arr[i])
+		obj := d.getObjectWithOid(oid, true)
+		if obj.isZero() {
+			continue
+		}
+		if body, err := obj.Body(); err == nil {
+			list = append(list, body)
+		}
+	}
+	return list
+}
+
+// parseTimeStamp reads a RFC 3161 time stamp token: a CMS signed data whose
+// content is a TSTInfo structure.
+func parseTimeStamp(token []byte) (*TimeStamp, error) {
+	sd, err := parseSignedData(token)
+	if err != nil {
+		return nil, err
+	}
+	if !sd.contentType.Equal(oidTSTInfo) {
+		return nil, fmt.Errorf("content type %s: not a time stamp token", sd.contentType)
+	}
+	ts, err := parseTSTInfo(sd.content)
+	if err != nil {
+		return nil, err
+	}
+	if len(sd.signers) > 0 {
+		ts.Signer = sd.signers[0].certificate(sd.certs)
+	}
+	return ts, nil
+}
+
+func parseTSTInfo(buf []byte) (*TimeStamp, error) {
+	elems, err := asn1Elements(buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) < 5 {
+		return nil, fmt.Errorf("tst info: too few elements")
+	}
+	var (
+		ts      TimeStamp
+		policy  asn1.ObjectIdentifier
+		imprint struct {
+			Algorithm pkix.AlgorithmIdentifier
+			Digest    []byte
+		}
+	)
+	if _, err := asn1.Unmarshal(elems[1].FullBytes, &policy); err != nil {
+		return nil, err
+	}
+	if _, err := asn1.Unmarshal(elems[2].FullBytes, &imprint); err != nil {
+		return nil, err
+	}
+	if _, err := asn1.Unmarshal(elems[3].FullBytes, &ts.Serial); err != nil {
+		return nil, err
+	}
+	if _, err := asn1.Unmarshal(elems[4].FullBytes, &ts.Time); err != nil {
+		return nil, err
+	}
+	ts.Policy = policy.String()
+	ts.Hash = digestHash(imprint.Algorithm.Algorithm)
+	ts.Imprint = imprint.Digest
+	for _, e := range elems[5:] {
+		if e.Class != asn1.ClassContextSpecific || e.Tag != 0 {
+			continue
+		}
+		var name asn1.RawValue
+		if _, err := asn1.Unmarshal(e.Bytes, &name); err != nil || name.Tag != 4 {
+			continue
+		}
+		var rdn pkix.RDNSequence
+		if _, err := asn1.Unmarshal(name.Bytes, &rdn); err == nil {
+			var n pkix.Name
+			n.FillFromRDNSequence(&rdn)
+			ts.Authority = n.String()
+		}
+	}
+	return &ts, nil
+}
+
+func (d *Document) verifyTimeStamp(sig Signature, content []byte) error {
+	ts, err := parseTSTInfo(content)
+	if err != nil {
+		return err
+	}
+	sum, err := d.digestByteRange(ts.Hash, sig.ByteRange)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sum, ts.Imprint) {
+		return fmt.Errorf("message imprint mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package pdf
+
+import (
+	"fmt"
+)
+
+// Transition is the effect used when moving to a page.
+type Transition struct {
+	Style     string
+	Duration  float64
+	Dimension string
+	Motion    string
+	Direction int
+	Scale     float64
+	Opaque    bool
+}
+
+// GetTransition returns a one second replace for pages without transition.
+func (d *Document) GetTransition(page int) (Transition, error) {
+	t := Transition{
+		Style:     "R",
+		Duration:  1,
+		Dimension: "H",
+		Motion:    "I",
+		Scale:     1,
+	}
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return t, fmt.Errorf("page %d not found in document", page)
+	}
+	trans, _ := d.resolve(obj.getValue("trans")).(Dict)
+	if trans == nil {
+		return t, nil
+	}
+	if str := trans.GetName("s"); str != "" {
+		t.Style = str
+	}
+	if trans.Has("d") {
+		t.Duration = getNumber(d.resolve(trans.getValue("d")))
+	}
+	if str := trans.GetName("dm"); str != "" {
+		t.Dimension = str
+	}
+	if str := trans.GetName("m"); str != "" {
+		t.Motion = str
+	}
+	switch di := d.resolve(trans.getValue("di")).(type) {
+	case NameValue:
+		t.Direction = -1
+	case int64, float64:
+		t.Direction = int(getNumber(di))
+	}
+	if trans.Has("ss") {
+		t.Scale = getNumber(d.resolve(trans.getValue("ss")))
+	}
+	t.Opaque = trans.GetBool("b")
+	return t, nil
+}
+
+// GetPageDuration returns 0 when the viewer should wait for the user.
+func (d *Document) GetPageDuration(page int) (float64, error) {
+	obj := d.getPage(page)
+	if obj.isZero() {
+		return 0, fmt.Errorf("page %d not found in document", page)
+	}
+	return getNumber(d.resolve(obj.getValue("dur"))), nil
+}
@@ -0,0 +1,312 @@
+package pdf
+
+import (
+	"encoding/binary"
+)
+
+const maxCompositeDepth = 8
+
+type trueTypeFont struct {
+	glyf  []byte
+	loca  []uint32
+	units float64
+	cmaps map[[2]uint16]map[uint16]rune
+	buf   []byte
+}
+
+func parseTrueType(buf []byte) *trueTypeFont {
+	var (
+		head = sfntTable(buf, "head")
+		loca = sfntTable(buf, "loca")
+		glyf = sfntTable(buf, "glyf")
+	)
+	if len(head) < 54 || len(loca) == 0 || len(glyf) == 0 {
+		return nil
+	}
+	f := trueTypeFont{
+		glyf:  glyf,
+		units: float64(binary.BigEndian.Uint16(head[18:])),
+		cmaps: make(map[[2]uint16]map[uint16]rune),
+		buf:   buf,
+	}
+	if f.units == 0 {
+		f.units = 1000
+	}
+	if binary.BigEndian.Uint16(head[50:]) == 0 {
+		for i := 0; i+2 <= len(loca); i += 2 {
+			f.loca = append(f.loca, uint32(binary.BigEndian.Uint16(loca[i:]))*2)
+		}
+	} else {
+		for i := 0; i+4 <= len(loca); i += 4 {
+			f.loca = append(f.loca, binary.BigEndian.Uint32(loca[i:]))
+		}
+	}
+	return &f
+}
+
+func (f *trueTypeFont) matrix() [6]float64 {
+	return [6]float64{1 / f.units, 0, 0, 1 / f.units, 0, 0}
+}
+
+func (f *trueTypeFont) lookup(platform, encoding uint16, code uint32) (uint16, bool) {
+	key := [2]uint16{platform, encoding}
+	codes, ok := f.cmaps[key]
+	if !ok {
+		codes = make(map[uint16]rune)
+		for gid, c := range f.subtable(platform, encoding) {
+			codes[uint16(c)] = rune(gid)
+		}
+		f.cmaps[key] = codes
+	}
+	if code > 0xffff {
+		return 0, false
+	}
+	gid, ok := codes[uint16(code)]
+	return uint16(gid), ok
+}
+
+func (f *trueTypeFont) subtable(platform, encoding uint16) map[uint16]rune {
+	table := sfntTable(f.buf, "cmap")
+	if len(table) < 4 {
+		return nil
+	}
+	count := int(binary.BigEndian.Uint16(table[2:]))
+	for i := 0; i < count; i++ {
+		pos := 4 + i*8
+		if pos+8 > len(table) {
+			break
+		}
+		if binary.BigEndian.Uint16(table[pos:]) != platform || binary.BigEndian.Uint16(table[pos+2:]) != encoding {
+			continue
+		}
+		if offset := binary.BigEndian.Uint32(table[pos+4:]); int(offset) < len(table) {
+			return parseCmapSubtable(table[offset:])
+		}
+	}
+	return nil
+}
+
+func (f *trueTypeFont) outline(gid uint16) []PathOp {
+	return f.glyph(int(gid), 0)
+}
+
+func (f *trueTypeFont) glyph(gid, depth int) []PathOp {
+	if gid+1 >= len(f.loca) || depth > maxCompositeDepth {
+		return nil
+	}
+	var (
+		start = f.loca[gid]
+		end   = f.loca[gid+1]
+	)
+	if start >= end || int(end) > len(f.glyf) {
+		return nil
+	}
+	data := f.glyf[start:end]
+	if len(data) < 10 {
+		return nil
+	}
+	contours := int(int16(binary.BigEndian.Uint16(data)))
+	if contours < 0 {
+		return f.composite(data[10:], depth)
+	}
+	return simpleGlyph(data[10:], contours)
+}
+
+type trueTypePoint struct {
+	Point
+	on bool
+}
+
+func simpleGlyph(data []byte, contours int) []PathOp {
+	if len(data) < 2*contours+2 {
+		return nil
+	}
+	ends := make([]int, contours)
+	for i := range ends {
+		ends[i] = int(binary.BigEndian.Uint16(data[2*i:]))
+	}
+	if contours == 0 {
+		return nil
+	}
+	var (
+		count = ends[contours-1] + 1
+		pos   = 2*contours + 2 + int(binary.BigEndian.Uint16(data[2*contours:]))
+		flags = make([]byte, 0, count)
+	)
+	for len(flags) < count {
+		if pos >= len(data) {
+			return nil
+		}
+		flag := data[pos]
+		pos++
+		flags = append(flags, flag)
+		if flag&0x08 != 0 {
+			if pos >= len(data) {
+				return nil
+			}
+			for n := data[pos]; n > 0 && len(flags) < count; n-- {
+				flags = append(flags, flag)
+			}
+			pos++
+		}
+	}
+	points := make([]trueTypePoint, count)
+	coords := func(short, same byte, set func(*trueTypePoint, float64)) bool {
+		var v int
+		for i, flag := range flags {
+			switch {
+			case flag&short != 0:
+				if pos >= len(data) {
+					return false
+				}
+				if flag&same != 0 {
+					v += int(data[pos])
+				} else {
+					v -= int(data[pos])
+				}
+				pos++
+			case flag&same == 0:
+				if pos+2 > len(data) {
+					return false
+				}
+				v += int(int16(binary.BigEndian.Uint16(data[pos:])))
+				pos += 2
+			}
+			set(&points[i], float64(v))
+		}
+		return true
+	}
+	if !coords(0x02, 0x10, func(p *trueTypePoint, v float64) { p.X = v }) {
+		return nil
+	}
+	if !coords(0x04, 0x20, func(p *trueTypePoint, v float64) { p.Y = v }) {
+		return nil
+	}
+	for i := range points {
+		points[i].on = flags[i]&0x01 != 0
+	}
+	var (
+		list []PathOp
+		prev int
+	)
+	for _, end := range ends {
+		if end < prev || end >= count {
+			break
+		}
+		list = append(list, quadContour(points[prev:end+1])...)
+		prev = end + 1
+	}
+	return list
+}
+
+func quadContour(points []trueTypePoint) []PathOp {
+	n := len(points)
+	if n == 0 {
+		return nil
+	}
+	var (
+		start = points[0].Point
+		rest  = points[1:]
+	)
+	switch {
+	case points[0].on:
+	case points[n-1].on:
+		start, rest = points[n-1].Point, points[:n-1]
+	default:
+		start, rest = midPoint(points[0].Point, points[n-1].Point), points
+	}
+	var (
+		list = []PathOp{{Op: "m", Points: []Point{start}}}
+		curr = start
+		ctrl *Point
+	)
+	quad := func(c, p Point) {
+		list = append(list, PathOp{Op: "c", Points: []Point{
+			{X: curr.X + 2*(c.X-curr.X)/3, Y: curr.Y + 2*(c.Y-curr.Y)/3},
+			{X: p.X + 2*(c.X-p.X)/3, Y: p.Y + 2*(c.Y-p.Y)/3},
+			p,
+		}})
+		curr = p
+	}
+	for i := range rest {
+		p := rest[i].Point
+		if rest[i].on {
+			if ctrl != nil {
+				quad(*ctrl, p)
+				ctrl = nil
+			} else {
+				list = append(list, PathOp{Op: "l", Points: []Point{p}})
+				curr = p
+			}
+			continue
+		}
+		if ctrl != nil {
+			quad(*ctrl, midPoint(*ctrl, p))
+		}
+		ctrl = &p
+	}
+	if ctrl != nil {
+		quad(*ctrl, start)
+	}
+	return append(list, PathOp{Op: "h"})
+}
+
+func midPoint(a, b Point) Point {
+	return Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+}
+
+func (f *trueTypeFont) composite(data []byte, depth int) []PathOp {
+	var list []PathOp
+	for pos := 0; pos+4 <= len(data); {
+		var (
+			flags      = binary.BigEndian.Uint16(data[pos:])
+			gid        = int(binary.BigEndian.Uint16(data[pos+2:]))
+			dx, dy     float64
+			a, b, c, d = 1.0, 0.0, 0.0, 1.0
+		)
+		pos += 4
+		if flags&0x0001 != 0 {
+			if pos+4 > len(data) {
+				break
+			}
+			dx = float64(int16(binary.BigEndian.Uint16(data[pos:])))
+			dy = float64(int16(binary.BigEndian.Uint16(data[pos+2:])))
+			pos += 4
+		} else {
+			if pos+2 > len(data) {
+				break
+			}
+			dx, dy = float64(int8(data[pos])), float64(int8(data[pos+1]))
+			pos += 2
+		}
+		if flags&0x0002 == 0 {
+			dx, dy = 0, 0
+		}
+		f2dot14 := func(i int) float64 {
+			return float64(int16(binary.BigEndian.Uint16(data[pos+i:]))) / 16384
+		}
+		switch {
+		case flags&0x0008 != 0 && pos+2 <= len(data):
+			a = f2dot14(0)
+			d = a
+			pos += 2
+		case flags&0x0040 != 0 && pos+4 <= len(data):
+			a, d = f2dot14(0), f2dot14(2)
+			pos += 4
+		case flags&0x0080 != 0 && pos+8 <= len(data):
+			a, b, c, d = f2dot14(0), f2dot14(2), f2dot14(4), f2dot14(6)
+			pos += 8
+		}
+		for _, op := range f.glyph(gid, depth+1) {
+			ps := make([]Point, len(op.Points))
+			for i, p := range op.Points {
+				ps[i] = Point{X: a*p.X + c*p.Y + dx, Y: b*p.X + d*p.Y + dy}
+			}
+			list = append(list, PathOp{Op: op.Op, Points: ps})
+		}
+		if flags&0x0020 == 0 {
+			break
+		}
+	}
+	return list
+}
@@ -0,0 +1,454 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+)
+
+const (
+	eexecKey      = 55665
+	charStringKey = 4330
+	maxSubrDepth  = 10
+)
+
+type type1Font struct {
+	matrix [6]float64
+	subrs  [][]byte
+	glyphs map[string][]byte
+}
+
+func parseType1(buf []byte) *type1Font {
+	x := bytes.Index(buf, []byte("eexec"))
+	if x < 0 {
+		return nil
+	}
+	f := type1Font{
+		matrix: [6]float64{0.001, 0, 0, 0.001, 0, 0},
+		glyphs: make(map[string][]byte),
+	}
+	if m := bytes.Index(buf[:x], []byte("/FontMatrix")); m >= 0 {
+		var nums []float64
+		for _, tok := range tokenizeCMap(buf[m+len("/FontMatrix") : x]) {
+			if tok == "]" || len(nums) == len(f.matrix) {
+				break
+			}
+			if n, err := strconv.ParseFloat(tok, 64); err == nil {
+				nums = append(nums, n)
+			}
+		}
+		if len(nums) == len(f.matrix) {
+			copy(f.matrix[:], nums)
+		}
+	}
+	body := buf[x+len("eexec"):]
+	for len(body) > 0 && (isBlank(body[0]) || body[0] == formfeed) {
+		body = body[1:]
+	}
+	if isHexType1(body) {
+		body = decodeHexType1(body)
+	}
+	f.parsePrivate(decryptType1(body, eexecKey, 4))
+	if len(f.glyphs) == 0 {
+		return nil
+	}
+	return &f
+}
+
+func isHexType1(buf []byte) bool {
+	if len(buf) < 4 {
+		return false
+	}
+	for _, b := range buf[:4] {
+		if _, ok := fromHexChar(b); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeHexType1(buf []byte) []byte {
+	var (
+		out  = make([]byte, 0, len(buf)/2)
+		curr byte
+		half bool
+	)
+	for _, b := range buf {
+		if isBlank(b) {
+			continue
+		}
+		c, ok := fromHexChar(b)
+		if !ok {
+			break
+		}
+		if half {
+			out = append(out, curr|c)
+		} else {
+			curr = c << 4
+		}
+		half = !half
+	}
+	return out
+}
+
+func decryptType1(buf []byte, key uint16, skip int) []byte {
+	if skip < 0 {
+		return buf
+	}
+	out := make([]byte, len(buf))
+	for i, c := range buf {
+		out[i] = c ^ byte(key>>8)
+		key = (uint16(c)+key)*52845 + 22719
+	}
+	if skip > len(out) {
+		return nil
+	}
+	return out[skip:]
+}
+
+func (f *type1Font) parsePrivate(buf []byte) {
+	lenIV := 4
+	if x := bytes.Index(buf, []byte("/lenIV")); x >= 0 {
+		s := type1Scanner{buf: buf[x+len("/lenIV"):]}
+		if n, err := strconv.Atoi(s.token()); err == nil {
+			lenIV = n
+		}
+	}
+	if x := bytes.Index(buf, []byte("/Subrs")); x >= 0 {
+		s := type1Scanner{buf: buf[x+len("/Subrs"):]}
+		count, _ := strconv.Atoi(s.token())
+		if count < 0 || count > len(s.buf) {
+			count = 0
+		}
+		f.subrs = make([][]byte, count)
+		for i := 0; i < count; i++ {
+			if !s.skipTo("dup") {
+				break
+			}
+			index, _ := strconv.Atoi(s.token())
+			data, ok := s.binary()
+			if !ok {
+				break
+			}
+			if index >= 0 && index < count {
+				f.subrs[index] = decryptType1(data, charStringKey, lenIV)
+			}
+		}
+	}
+	x := bytes.Index(buf, []byte("/CharStrings"))
+	if x < 0 {
+		return
+	}
+	s := type1Scanner{buf: buf[x+len("/CharStrings"):]}
+	if !s.skipTo("begin") {
+		return
+	}
+	for {
+		tok := s.token()
+		if len(tok) < 2 || tok[0] != '/' {
+			break
+		}
+		data, ok := s.binary()
+		if !ok {
+			break
+		}
+		f.glyphs[tok[1:]] = decryptType1(data, charStringKey, lenIV)
+		s.token()
+	}
+}
+
+type type1Scanner struct {
+	buf []byte
+}
+
+func (s *type1Scanner) token() string {
+	for len(s.buf) > 0 && (isBlank(s.buf[0]) || s.buf[0] == formfeed) {
+		s.buf = s.buf[1:]
+	}
+	i := 0
+	for i < len(s.buf) && !isBlank(s.buf[i]) && (i == 0 || s.buf[i] != '/') {
+		i++
+	}
+	tok := string(s.buf[:i])
+	s.buf = s.buf[i:]
+	return tok
+}
+
+func (s *type1Scanner) skipTo(want string) bool {
+	for len(s.buf) > 0 {
+		if s.token() == want {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *type1Scanner) binary() ([]byte, bool) {
+	n, err := strconv.Atoi(s.token())
+	if err != nil || n < 0 {
+		return nil, false
+	}
+	s.token()
+	if len(s.buf) < n+1 {
+		return nil, false
+	}
+	data := s.buf[1 : n+1]
+	s.buf = s.buf[n+1:]
+	return data, true
+}
+
+func (f *type1Font) outline(name string) []PathOp {
+	cs, ok := f.glyphs[name]
+	if !ok {
+		return nil
+	}
+	t := type1Interp{font: f}
+	t.run(cs, 0)
+	return t.path
+}
+
+type type1Interp struct {
+	font  *type1Font
+	stack []float64
+	ps    []float64
+	path  []PathOp
+	flex  []Point
+	seac  bool
+
+	sbx, x, y float64
+	dx, dy    float64
+	flexing   bool
+}
+
+func (t *type1Interp) run(cs []byte, depth int) bool {
+	if depth > maxSubrDepth {
+		return true
+	}
+	for i := 0; i < len(cs); {
+		v := cs[i]
+		i++
+		switch {
+		case v >= 32 && v <= 246:
+			t.push(float64(int(v) - 139))
+			continue
+		case v >= 247 && v <= 250:
+			if i >= len(cs) {
+				return true
+			}
+			t.push(float64((int(v)-247)*256 + int(cs[i]) + 108))
+			i++
+			continue
+		case v >= 251 && v <= 254:
+			if i >= len(cs) {
+				return true
+			}
+			t.push(float64(-(int(v)-251)*256 - int(cs[i]) - 108))
+			i++
+			continue
+		case v == 255:
+			if i+4 > len(cs) {
+				return true
+			}
+			t.push(float64(int32(binary.BigEndian.Uint32(cs[i:]))))
+			i += 4
+			continue
+		case v == 12:
+			if i >= len(cs) {
+				return true
+			}
+			if t.escape(cs[i]) {
+				return true
+			}
+			i++
+			continue
+		}
+		args := t.stack
+		switch v {
+		case 13:
+			if len(args) >= 2 {
+				t.sbx, t.x, t.y = args[0], args[0], 0
+			}
+		case 9:
+			t.path = append(t.path, PathOp{Op: "h"})
+		case 21:
+			if len(args) >= 2 {
+				t.moveTo(args[0], args[1])
+			}
+		case 22:
+			if len(args) >= 1 {
+				t.moveTo(args[0], 0)
+			}
+		case 4:
+			if len(args) >= 1 {
+				t.moveTo(0, args[0])
+			}
+		case 5:
+			if len(args) >= 2 {
+				t.lineTo(args[0], args[1])
+			}
+		case 6:
+			if len(args) >= 1 {
+				t.lineTo(args[0], 0)
+			}
+		case 7:
+			if len(args) >= 1 {
+				t.lineTo(0, args[0])
+			}
+		case 8:
+			if len(args) >= 6 {
+				t.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+			}
+		case 30:
+			if len(args) >= 4 {
+				t.curveTo(0, args[0], args[1], args[2], args[3], 0)
+			}
+		case 31:
+			if len(args) >= 4 {
+				t.curveTo(args[0], 0, args[1], args[2], 0, args[3])
+			}
+		case 10:
+			n := len(args)
+			if n == 0 {
+				break
+			}
+			index := int(args[n-1])
+			t.stack = args[:n-1]
+			if index >= 0 && index < len(t.font.subrs) && t.run(t.font.subrs[index], depth+1) {
+				return true
+			}
+			continue
+		case 11:
+			return false
+		case 14:
+			return true
+		}
+		t.stack = t.stack[:0]
+	}
+	return false
+}
+
+func (t *type1Interp) escape(op byte) bool {
+	args := t.stack
+	switch op {
+	case 6:
+		if len(args) >= 5 && !t.seac {
+			t.accent(args[0], args[1], args[2], byte(args[3]), byte(args[4]))
+			return true
+		}
+	case 7:
+		if len(args) >= 4 {
+			t.sbx, t.x, t.y = args[0], args[0], args[1]
+		}
+	case 12:
+		if n := len(args); n >= 2 && args[n-1] != 0 {
+			t.stack = append(args[:n-2], args[n-2]/args[n-1])
+			return false
+		}
+	case 16:
+		t.otherSubr()
+		return false
+	case 17:
+		if n := len(t.ps); n > 0 {
+			t.push(t.ps[n-1])
+			t.ps = t.ps[:n-1]
+		}
+		return false
+	case 33:
+		if len(args) >= 2 {
+			t.x, t.y = args[0], args[1]
+		}
+	}
+	t.stack = t.stack[:0]
+	return false
+}
+
+func (t *type1Interp) otherSubr() {
+	n := len(t.stack)
+	if n < 2 {
+		t.stack = t.stack[:0]
+		return
+	}
+	var (
+		which = int(t.stack[n-1])
+		count = int(t.stack[n-2])
+	)
+	if count < 0 || count > n-2 {
+		count = n - 2
+	}
+	args := append([]float64{}, t.stack[n-2-count:n-2]...)
+	t.stack = t.stack[:n-2-count]
+	switch which {
+	case 0:
+		if len(t.flex) >= 7 {
+			p := t.flex
+			t.path = append(t.path,
+				PathOp{Op: "c", Points: []Point{t.point(p[1]), t.point(p[2]), t.point(p[3])}},
+				PathOp{Op: "c", Points: []Point{t.point(p[4]), t.point(p[5]), t.point(p[6])}},
+			)
+		}
+		t.flexing, t.flex = false, nil
+		if len(args) >= 3 {
+			t.ps = []float64{args[2], args[1]}
+		}
+	case 1:
+		t.flexing, t.flex = true, nil
+	case 2:
+		t.flex = append(t.flex, Point{X: t.x, Y: t.y})
+	default:
+		t.ps = args
+	}
+}
+
+func (t *type1Interp) accent(asb, adx, ady float64, base, accent byte) {
+	var (
+		bname = standardName(base)
+		aname = standardName(accent)
+		sbx   = t.sbx
+	)
+	if cs, ok := t.font.glyphs[bname]; ok {
+		u := type1Interp{font: t.font, seac: true, dx: t.dx, dy: t.dy}
+		u.run(cs, 0)
+		t.path = append(t.path, u.path...)
+	}
+	if cs, ok := t.font.glyphs[aname]; ok {
+		u := type1Interp{font: t.font, seac: true, dx: t.dx + sbx + adx - asb, dy: t.dy + ady}
+		u.run(cs, 0)
+		t.path = append(t.path, u.path...)
+	}
+}
+
+func (t *type1Interp) push(n float64) {
+	t.stack = append(t.stack, n)
+}
+
+func (t *type1Interp) point(p Point) Point {
+	return Point{X: p.X + t.dx, Y: p.Y + t.dy}
+}
+
+func (t *type1Interp) moveTo(dx, dy float64) {
+	t.x += dx
+	t.y += dy
+	if t.flexing {
+		return
+	}
+	t.path = append(t.path, PathOp{Op: "m", Points: []Point{t.point(Point{X: t.x, Y: t.y})}})
+}
+
+func (t *type1Interp) lineTo(dx, dy float64) {
+	t.x += dx
+	t.y += dy
+	t.path = append(t.path, PathOp{Op: "l", Points: []Point{t.point(Point{X: t.x, Y: t.y})}})
+}
+
+func (t *type1Interp) curveTo(dx1, dy1, dx2, dy2, dx3, dy3 float64) {
+	var (
+		x1, y1 = t.x + dx1, t.y + dy1
+		x2, y2 = x1 + dx2, y1 + dy2
+	)
+	t.x, t.y = x2+dx3, y2+dy3
+	t.path = append(t.path, PathOp{Op: "c", Points: []Point{
+		t.point(Point{X: x1, Y: y1}),
+		t.point(Point{X: x2, Y: y2}),
+		t.point(Point{X: t.x, Y: t.y}),
+	}})
+}
@@ -0,0 +1,172 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+type Point struct {
+	X float64
+	Y float64
+}
+
+type PathOp struct {
+	Op     string
+	Points []Point
+}
+
+type Glyph struct {
+	Name    string
+	Code    uint32
+	Rune    rune
+	Width   float64
+	Content []byte
+	Path    []PathOp
+}
+
+func (d *Document) GetGlyphs(f Font) ([]Glyph, error) {
+	if f.Sub != "Type3" {
+		return nil, fmt.Errorf("%s: glyphs of %s font: %w", f.Base, f.Sub, ErrUnsupported)
+	}
+	obj := d.getObjectWithOid(f.oid, false)
+	if obj.isZero() {
+		return nil, fmt.Errorf("font %s %w", f.Name, ErrMissing)
+	}
+	var (
+		procs, _  = d.resolve(obj.getValue("charprocs")).(Dict)
+		widths, _ = d.resolve(obj.getValue("widths")).([]interface{})
+		first     = obj.GetInt("firstchar")
+		names     = d.getDifferences(obj)
+		list      []Glyph
+	)
+	codes := make([]uint32, 0, len(names))
+	for c := range names {
+		codes = append(codes, c)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	for _, c := range codes {
+		g := Glyph{
+			Name: names[c],
+			Code: c,
+			Rune: f.codes[c],
+		}
+		if i := int64(c) - first; i >= 0 && i < int64(len(widths)) {
+			g.Width = getNumber(d.resolve(widths[i])) * f.Matrix[0]
+		}
+		proc := d.getObjectWithOid(procs.GetString(g.Name), true)
+		if !proc.isZero() {
+			g.Content, _ = proc.Body()
+			g.Path = parsePath(g.Content)
+		}
+		list = append(list, g)
+	}
+	return list, nil
+}
+
+func (d *Document) getDifferences(obj Object) map[uint32]string {
+	var (
+		enc, _   = d.resolve(obj.getValue("encoding")).(Dict)
+		diffs, _ = d.resolve(enc.getValue("differences")).([]interface{})
+		names    = make(map[uint32]string)
+		code     uint32
+	)
+	for _, v := range diffs {
+		switch v := v.(type) {
+		case int64:
+			code = uint32(v)
+		case NameValue:
+			names[code] = string(v)
+			code++
+		}
+	}
+	return names
+}
+
+func (d *Document) getFontMatrix(obj Object) [6]float64 {
+	matrix := [6]float64{0.001, 0, 0, 0.001, 0, 0}
+	arr, _ := d.resolve(obj.getValue("fontmatrix")).([]interface{})
+	if len(arr) != len(matrix) {
+		return matrix
+	}
+	for i := range arr {
+		matrix[i] = getNumber(d.resolve(arr[i]))
+	}
+	return matrix
+}
+
+func parsePath(body []byte) []PathOp {
+	var (
+		b    pathBuilder
+		nums []float64
+	)
+	for _, tok := range tokenizeCMap(body) {
+		if n, err := strconv.ParseFloat(tok, 64); err == nil {
+			nums = append(nums, n)
+			continue
+		}
+		b.add(tok, nums)
+		nums = nums[:0]
+	}
+	return b.list
+}
+
+type pathBuilder struct {
+	list []PathOp
+	last Point
+}
+
+func (b *pathBuilder) add(name string, nums []float64) {
+	points := func(n int) []Point {
+		if len(nums) < n*2 {
+			return nil
+		}
+		var (
+			args = nums[len(nums)-n*2:]
+			ps   = make([]Point, n)
+		)
+		for i := range ps {
+			ps[i] = Point{X: args[i*2], Y: args[i*2+1]}
+		}
+		return ps
+	}
+	var op PathOp
+	switch name {
+	case "m", "l":
+		op = PathOp{Op: name, Points: points(1)}
+	case "c":
+		op = PathOp{Op: name, Points: points(3)}
+	case "v":
+		if ps := points(2); ps != nil {
+			op = PathOp{Op: "c", Points: []Point{b.last, ps[0], ps[1]}}
+		}
+	case "y":
+		if ps := points(2); ps != nil {
+			op = PathOp{Op: "c", Points: []Point{ps[0], ps[1], ps[1]}}
+		}
+	case "h":
+		op = PathOp{Op: name}
+	case "re":
+		if ps := points(2); ps != nil {
+			var (
+				x, y = ps[0].X, ps[0].Y
+				w, h = ps[1].X, ps[1].Y
+			)
+			b.list = append(b.list,
+				PathOp{Op: "m", Points: []Point{{X: x, Y: y}}},
+				PathOp{Op: "l", Points: []Point{{X: x + w, Y: y}}},
+				PathOp{Op: "l", Points: []Point{{X: x + w, Y: y + h}}},
+				PathOp{Op: "l", Points: []Point{{X: x, Y: y + h}}},
+			)
+			op = PathOp{Op: "h"}
+			b.last = ps[0]
+		}
+	}
+	if op.Op == "" || (op.Op != "h" && op.Points == nil) {
+		return
+	}
+	if n := len(op.Points); n > 0 {
+		b.last = op.Points[n-1]
+	}
+	b.list = append(b.list, op)
+}
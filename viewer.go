@@ -0,0 +1,68 @@
+package pdf
+
+type ViewerPreferences struct {
+	PageLayout string
+	PageMode   string
+
+	HideToolbar     bool
+	HideMenubar     bool
+	HideWindowUI    bool
+	FitWindow       bool
+	CenterWindow    bool
+	DisplayDocTitle bool
+
+	NonFullScreenPageMode string
+	Direction             string
+	PrintScaling          string
+	Duplex                string
+	PickTrayByPDFSize     bool
+	PrintPageRange        []int64
+	NumCopies             int64
+}
+
+func (d *Document) GetViewerPreferences() ViewerPreferences {
+	vp := ViewerPreferences{
+		PageLayout:            "SinglePage",
+		PageMode:              "UseNone",
+		NonFullScreenPageMode: "UseNone",
+		Direction:             "L2R",
+		PrintScaling:          "AppDefault",
+		NumCopies:             1,
+	}
+	root := d.getCatalog()
+	if root.isZero() {
+		return vp
+	}
+	if str := root.GetName("pagelayout"); str != "" {
+		vp.PageLayout = str
+	}
+	if str := root.GetName("pagemode"); str != "" {
+		vp.PageMode = str
+	}
+	prefs, _ := d.resolve(root.getValue("viewerpreferences")).(Dict)
+	if prefs == nil {
+		return vp
+	}
+	vp.HideToolbar = prefs.GetBool("hidetoolbar")
+	vp.HideMenubar = prefs.GetBool("hidemenubar")
+	vp.HideWindowUI = prefs.GetBool("hidewindowui")
+	vp.FitWindow = prefs.GetBool("fitwindow")
+	vp.CenterWindow = prefs.GetBool("centerwindow")
+	vp.DisplayDocTitle = prefs.GetBool("displaydoctitle")
+	vp.PickTrayByPDFSize = prefs.GetBool("picktraybypdfsize")
+	if str := prefs.GetName("nonfullscreenpagemode"); str != "" {
+		vp.NonFullScreenPageMode = str
+	}
+	if str := prefs.GetName("direction"); str != "" {
+		vp.Direction = str
+	}
+	if str := prefs.GetName("printscaling"); str != "" {
+		vp.PrintScaling = str
+	}
+	vp.Duplex = prefs.GetName("duplex")
+	vp.PrintPageRange = prefs.GetIntArray("printpagerange")
+	if n := prefs.GetInt("numcopies"); n > 0 {
+		vp.NumCopies = n
+	}
+	return vp
+}
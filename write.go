@@ -0,0 +1,472 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var ErrUnsupported = errors.New("unsupported")
+
+type WriteMode int
+
+const (
+	WriteNormalized WriteMode = iota
+	// WriteExact copies the file verbatim so that its signatures stay valid.
+	WriteExact
+)
+
+type rawObject struct {
+	Oid    string
+	Value  []byte
+	Stream []byte
+	Full   []byte
+	Skip   bool
+}
+
+func (d *Document) Save(w io.Writer, mode WriteMode) error {
+	if mode == WriteExact {
+		_, err := io.Copy(w, io.NewSectionReader(d.src, 0, d.size))
+		return err
+	}
+	ws := writer{inner: bufio.NewWriter(w)}
+	list := d.sortedXRef()
+	if d.encrypt != "" {
+		for _, x := range list {
+			if x.isEmbed() {
+				return fmt.Errorf("save encrypted document with object streams: %w", ErrUnsupported)
+			}
+		}
+	}
+	ws.writeHeader(d.GetVersion())
+	for _, x := range list {
+		raw, err := d.readRawObject(x)
+		if err != nil {
+			return fmt.Errorf("%s: %w", x.Oid, err)
+		}
+		if raw.Skip {
+			continue
+		}
+		ws.writeObject(raw)
+	}
+	ws.writeTrailer(d.catalog, d.info, d.encrypt, d.fileid)
+	return ws.Flush()
+}
+
+func (d *Document) Compact(w io.Writer) error {
+	if d.encrypt != "" {
+		return fmt.Errorf("compact encrypted document: %w", ErrUnsupported)
+	}
+	var (
+		ws      = writer{inner: bufio.NewWriter(w)}
+		list    []string
+		renames = make(map[string]string)
+	)
+	d.walkReachable(func(o Object) bool {
+		list = append(list, o.Oid)
+		renames[o.Oid] = fmt.Sprintf("%d/0", len(list))
+		return true
+	})
+	rename := func(oid string) string {
+		return renames[oid]
+	}
+	ws.writeHeader(d.GetVersion())
+	for _, oid := range list {
+		p, ok := d.lookupOid(oid)
+		if !ok {
+			continue
+		}
+		raw, err := d.readRawObject(p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", oid, err)
+		}
+		raw.Oid = rename(oid)
+		raw.Value = renumberValue(raw.Value, rename)
+		ws.writeObject(raw)
+	}
+	ws.writeTrailer(rename(d.catalog), rename(d.info), "", d.fileid)
+	return ws.Flush()
+}
+
+func (d *Document) sortedXRef() []Pointer {
+	for d.loadPending() {
+	}
+	list := make([]Pointer, len(d.xref))
+	copy(list, d.xref)
+	sort.Slice(list, func(i, j int) bool {
+		return objectNumber(list[i].Oid) < objectNumber(list[j].Oid)
+	})
+	return list
+}
+
+// RawObjectBytes returns the bytes of the object as written in the file.
+func (d *Document) RawObjectBytes(oid string) ([]byte, error) {
+	p, ok := d.lookupOid(oid)
+	if !ok {
+		return nil, fmt.Errorf("object %s %w", oid, ErrMissing)
+	}
+	raw, err := d.readRaw(p, d.quirks.Has(QuirkStreamLength) || d.lenient())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", oid, err)
+	}
+	buf := raw.Full
+	if p.isEmbed() {
+		buf = raw.Value
+	}
+	return append([]byte{}, buf...), nil
+}
+
+func (d *Document) readRawObject(p Pointer) (rawObject, error) {
+	return d.readRaw(p, false)
+}
+
+// readRaw reads the bytes of the object p points to. When scan is set, the
+// length of streams is computed from the position of the endstream keyword
+// instead of their /Length and a missing endobj is tolerated.
+func (d *Document) readRaw(p Pointer, scan bool) (rawObject, error) {
+	raw := rawObject{Oid: p.Oid}
+	if p.isEmbed() {
+		obj := d.getObjectWithOid(p.Owner, true)
+		val, err := obj.getEmbeddedBytes(p.Oid, p.Offset)
+		if err == nil {
+			raw.Value = val
+		}
+		return raw, err
+	}
+	err := d.readWindow(p.Offset, func(r *Reader) error {
+		return d.readRawAt(r, &raw, scan)
+	})
+	return raw, err
+}
+
+func (d *Document) readRawAt(r *Reader, raw *rawObject, scan bool) error {
+	r.Skip()
+	var (
+		pos = r.Tell()
+		oid int
+		rev int
+		typ string
+	)
+	if _, err := fmt.Fscanf(r, "%d %d %s", &oid, &rev, &typ); err != nil {
+		return fmt.Errorf("fail to scan object header: %w", err)
+	}
+	if !bytes.Equal([]byte(typ), begobj) {
+		return fmt.Errorf("object keyword %w", ErrMissing)
+	}
+	r.Skip()
+	start := r.Tell()
+	val, err := r.ReadValue(nil)
+	if err != nil {
+		return err
+	}
+	raw.Value = r.buf[start:r.Tell()]
+
+	dict, _ := val.(Dict)
+	switch dict.Type() {
+	case "XRef", "ObjStm":
+		raw.Skip = true
+	}
+	switch line, _ := r.ReadLine(); {
+	case bytes.Equal(line, endobj):
+	case bytes.Equal(line, begstream):
+		size := dict.GetInt("length")
+		if oid := dict.GetString("length"); oid != "" {
+			obj := d.getObjectWithOid(oid, false)
+			size, _ = obj.Data.(int64)
+		}
+		offset := r.Tell()
+		if scan {
+			size = scanStreamLength(r, size)
+		}
+		if size < 0 || offset+size > r.Size() {
+			return fmt.Errorf("stream %w", ErrMissing)
+		}
+		raw.Stream = r.buf[offset : offset+size]
+		r.Seek(offset+size, io.SeekStart)
+		if line, _ = r.ReadLine(); !bytes.Equal(line, endstream) {
+			return fmt.Errorf("%s %w", endstream, ErrMissing)
+		}
+		if line, _ = r.ReadLine(); !bytes.Equal(line, endobj) && !scan {
+			return fmt.Errorf("%s %w", endobj, ErrMissing)
+		}
+	default:
+		return fmt.Errorf("unexpected keyword %s", line)
+	}
+	raw.Full = bytes.TrimSpace(r.buf[pos:r.Tell()])
+	return nil
+}
+
+type writer struct {
+	inner  *bufio.Writer
+	offset int64
+	xref   []Pointer
+	err    error
+}
+
+func (w *writer) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.inner.Write(b)
+	w.offset += int64(n)
+	w.err = err
+	return n, err
+}
+
+func (w *writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.inner.Flush()
+}
+
+func (w *writer) writeHeader(version string) {
+	if version == "" {
+		version = "1.7"
+	}
+	fmt.Fprintf(w, "%%PDF-%s\n%%\xe2\xe3\xcf\xd3\n", version)
+}
+
+func (w *writer) writeObject(raw rawObject) {
+	w.xref = append(w.xref, Pointer{Oid: raw.Oid, Offset: w.offset})
+
+	id, rev := parseOid(raw.Oid)
+	fmt.Fprintf(w, "%d %d obj\n", id, rev)
+	w.Write(normalizeValue(raw.Value))
+	if raw.Stream != nil {
+		w.Write([]byte("\nstream\n"))
+		w.Write(raw.Stream)
+		w.Write([]byte("\nendstream"))
+	}
+	w.Write([]byte("\nendobj\n"))
+}
+
+func (w *writer) writeTrailer(root, info, encrypt string, fileid []string) {
+	var size int
+	for _, x := range w.xref {
+		if n := objectNumber(x.Oid); n >= size {
+			size = n + 1
+		}
+	}
+	var (
+		offset  = w.offset
+		entries = make([]int64, size)
+		revs    = make([]int, size)
+	)
+	for i := range entries {
+		entries[i] = -1
+	}
+	for _, x := range w.xref {
+		id, rev := parseOid(x.Oid)
+		entries[id], revs[id] = x.Offset, rev
+	}
+	fmt.Fprintf(w, "xref\n0 %d\n", size)
+	for i := range entries {
+		if entries[i] < 0 {
+			rev := 0
+			if i == 0 {
+				rev = 65535
+			}
+			fmt.Fprintf(w, "%010d %05d f\r\n", 0, rev)
+			continue
+		}
+		fmt.Fprintf(w, "%010d %05d n\r\n", entries[i], revs[i])
+	}
+	fmt.Fprintf(w, "trailer\n<< /Size %d", size)
+	if root != "" {
+		fmt.Fprintf(w, " /Root %s", formatRef(root))
+	}
+	if info != "" {
+		fmt.Fprintf(w, " /Info %s", formatRef(info))
+	}
+	if encrypt != "" {
+		fmt.Fprintf(w, " /Encrypt %s", formatRef(encrypt))
+	}
+	if len(fileid) > 0 {
+		w.Write([]byte(" /ID ["))
+		for _, id := range fileid {
+			fmt.Fprintf(w, "<%s>", hex.EncodeToString([]byte(id)))
+		}
+		w.Write([]byte("]"))
+	}
+	fmt.Fprintf(w, " >>\nstartxref\n%d\n%%%%EOF\n", offset)
+}
+
+func formatRef(oid string) string {
+	id, rev := parseOid(oid)
+	return fmt.Sprintf("%d %d R", id, rev)
+}
+
+func parseOid(oid string) (int, int) {
+	var id, rev int
+	fmt.Sscanf(strings.Replace(oid, "/", " ", 1), "%d %d", &id, &rev)
+	return id, rev
+}
+
+func isOid(str string) bool {
+	x := strings.IndexByte(str, '/')
+	if x <= 0 || x == len(str)-1 {
+		return false
+	}
+	for i := range str {
+		if i != x && !isDigit(str[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func objectNumber(oid string) int {
+	id, _ := parseOid(oid)
+	return id
+}
+
+func normalizeValue(buf []byte) []byte {
+	var (
+		out bytes.Buffer
+		sep = func() {
+			if n := out.Len(); n > 0 {
+				out.WriteByte(space)
+			}
+		}
+	)
+	for i := 0; i < len(buf); {
+		switch b := buf[i]; {
+		case isBlank(b) || b == formfeed || b == 0:
+			i++
+		case b == percent:
+			for i < len(buf) && buf[i] != nl && buf[i] != cr {
+				i++
+			}
+		case b == lparen:
+			j := scanLiteral(buf, i)
+			sep()
+			out.Write(buf[i:j])
+			i = j
+		case b == langle && i+1 < len(buf) && buf[i+1] == langle:
+			sep()
+			out.WriteString("<<")
+			i += 2
+		case b == rangle && i+1 < len(buf) && buf[i+1] == rangle:
+			sep()
+			out.WriteString(">>")
+			i += 2
+		case b == langle:
+			sep()
+			for ; i < len(buf); i++ {
+				if isBlank(buf[i]) {
+					continue
+				}
+				out.WriteByte(buf[i])
+				if buf[i] == rangle {
+					i++
+					break
+				}
+			}
+		case b == lsquare || b == rsquare || b == rangle:
+			sep()
+			out.WriteByte(b)
+			i++
+		default:
+			j := i + 1
+			for ; j < len(buf); j++ {
+				if isBlank(buf[j]) || isDelimiter(buf[j]) {
+					break
+				}
+			}
+			sep()
+			out.Write(buf[i:j])
+			i = j
+		}
+	}
+	return out.Bytes()
+}
+
+func renumberValue(buf []byte, rename func(string) string) []byte {
+	var (
+		toks = splitValue(buf)
+		out  [][]byte
+	)
+	for i := 0; i < len(toks); i++ {
+		if i+2 < len(toks) && bytes.Equal(toks[i+2], []byte("R")) && isInteger(toks[i]) && isInteger(toks[i+1]) {
+			oid := rename(fmt.Sprintf("%s/%s", toks[i], toks[i+1]))
+			if oid == "" {
+				out = append(out, []byte("null"))
+			} else {
+				out = append(out, []byte(formatRef(oid)))
+			}
+			i += 2
+			continue
+		}
+		out = append(out, toks[i])
+	}
+	return bytes.Join(out, []byte{space})
+}
+
+func splitValue(buf []byte) [][]byte {
+	var (
+		norm = normalizeValue(buf)
+		toks [][]byte
+	)
+	for i := 0; i < len(norm); {
+		j := i + 1
+		switch norm[i] {
+		case space:
+			i++
+			continue
+		case lparen:
+			j = scanLiteral(norm, i)
+		default:
+			for j < len(norm) && norm[j] != space {
+				j++
+			}
+		}
+		toks = append(toks, norm[i:j])
+		i = j
+	}
+	return toks
+}
+
+func isInteger(str []byte) bool {
+	if len(str) == 0 {
+		return false
+	}
+	for _, b := range str {
+		if !isDigit(b) {
+			return false
+		}
+	}
+	return true
+}
+
+func scanLiteral(buf []byte, i int) int {
+	var parens int
+	for ; i < len(buf); i++ {
+		switch buf[i] {
+		case backslash:
+			i++
+		case lparen:
+			parens++
+		case rparen:
+			parens--
+			if parens == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+func isDelimiter(b byte) bool {
+	switch b {
+	case lparen, rparen, langle, rangle, lsquare, rsquare, slash, percent, '{', '}':
+		return true
+	default:
+		return false
+	}
+}
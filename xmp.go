@@ -0,0 +1,336 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	nsRDF    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsXML    = "http://www.w3.org/XML/1998/namespace"
+	nsDC     = "http://purl.org/dc/elements/1.1/"
+	nsXMP    = "http://ns.adobe.com/xap/1.0/"
+	nsXMPMM  = "http://ns.adobe.com/xap/1.0/mm/"
+	nsPDF    = "http://ns.adobe.com/pdf/1.3/"
+	nsPDFAID = "http://www.aiim.org/pdfa/ns/id/"
+)
+
+var xmpPrefixes = map[string]string{
+	nsDC:     "dc",
+	nsXMP:    "xmp",
+	nsXMPMM:  "xmpMM",
+	nsPDF:    "pdf",
+	nsPDFAID: "pdfaid",
+}
+
+type xmpValue struct {
+	Lang  string
+	Value string
+}
+
+type xmpProperties map[string][]xmpValue
+
+func (p xmpProperties) Get(key string) string {
+	vs := p[key]
+	if len(vs) == 0 {
+		return ""
+	}
+	for _, v := range vs {
+		if v.Lang == "x-default" {
+			return v.Value
+		}
+	}
+	return vs[0].Value
+}
+
+func (p xmpProperties) Alt(key string) LangAlt {
+	var list LangAlt
+	for _, v := range p[key] {
+		list = append(list, LangText{Lang: v.Lang, Text: v.Value})
+	}
+	return list
+}
+
+func (p xmpProperties) All(key string) []string {
+	var list []string
+	for _, v := range p[key] {
+		list = append(list, v.Value)
+	}
+	return list
+}
+
+func parseXMPProperties(buf []byte) (xmpProperties, error) {
+	var (
+		dec   = xml.NewDecoder(bytes.NewReader(buf))
+		props = make(xmpProperties)
+		stack []xml.Name
+		prop  string
+		lang  string
+		text  strings.Builder
+	)
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return props, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, tok.Name)
+			if tok.Name.Space == nsRDF && tok.Name.Local == "Description" {
+				for _, a := range tok.Attr {
+					if key := xmpKey(a.Name); key != "" {
+						props[key] = append(props[key], xmpValue{Value: a.Value})
+					}
+				}
+				continue
+			}
+			if key := xmpKey(tok.Name); key != "" && prop == "" {
+				prop = key
+				for _, a := range tok.Attr {
+					if a.Name.Space == nsRDF && a.Name.Local == "resource" {
+						props[prop] = append(props[prop], xmpValue{Value: a.Value})
+					}
+				}
+			}
+			lang = ""
+			for _, a := range tok.Attr {
+				if a.Name.Local == "lang" && (a.Name.Space == nsXML || a.Name.Space == "xml") {
+					lang = a.Value
+				}
+			}
+			text.Reset()
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if prop == "" {
+				continue
+			}
+			switch key := xmpKey(tok.Name); {
+			case tok.Name.Space == nsRDF && tok.Name.Local == "li":
+				props[prop] = append(props[prop], xmpValue{Lang: lang, Value: strings.TrimSpace(text.String())})
+			case key == prop:
+				if str := strings.TrimSpace(text.String()); str != "" && len(props[prop]) == 0 {
+					props[prop] = append(props[prop], xmpValue{Lang: lang, Value: str})
+				}
+				prop = ""
+			}
+			text.Reset()
+		}
+	}
+	return props, nil
+}
+
+func xmpKey(name xml.Name) string {
+	prefix, ok := xmpPrefixes[name.Space]
+	if !ok {
+		return ""
+	}
+	return prefix + ":" + name.Local
+}
+
+type LangText struct {
+	Lang string
+	Text string
+}
+
+// LangAlt holds the alternatives of a language dependant property in the
+// order of the metadata stream.
+type LangAlt []LangText
+
+func (a LangAlt) Get(lang string) string {
+	for _, t := range a {
+		if strings.EqualFold(t.Lang, lang) {
+			return t.Text
+		}
+	}
+	return ""
+}
+
+func (a LangAlt) Default() string {
+	if len(a) == 0 {
+		return ""
+	}
+	if str := a.Get("x-default"); str != "" {
+		return str
+	}
+	return a[0].Text
+}
+
+type XMP struct {
+	Title        LangAlt
+	Description  LangAlt
+	Rights       LangAlt
+	Creators     []string
+	Contributors []string
+	Publishers   []string
+	Subjects     []string
+	Languages    []string
+	Format       string
+	Identifier   string
+
+	CreatorTool  string
+	CreateDate   time.Time
+	ModifyDate   time.Time
+	MetadataDate time.Time
+
+	DocumentID string
+	InstanceID string
+
+	Producer   string
+	Keywords   string
+	PDFVersion string
+	Trapped    string
+
+	PDFAPart        int
+	PDFAConformance string
+}
+
+// ParseXMP decodes the properties of the dc, xmp, xmpMM, pdf and pdfaid
+// schemas found in an XMP packet.
+func ParseXMP(buf []byte) (XMP, error) {
+	var x XMP
+	props, err := parseXMPProperties(buf)
+	if err != nil && len(props) == 0 {
+		return x, fmt.Errorf("xmp: %s", err)
+	}
+	x.Title = props.Alt("dc:title")
+	x.Description = props.Alt("dc:description")
+	x.Rights = props.Alt("dc:rights")
+	x.Creators = props.All("dc:creator")
+	x.Contributors = props.All("dc:contributor")
+	x.Publishers = props.All("dc:publisher")
+	x.Subjects = props.All("dc:subject")
+	x.Languages = props.All("dc:language")
+	x.Format = props.Get("dc:format")
+	x.Identifier = props.Get("dc:identifier")
+
+	x.CreatorTool = props.Get("xmp:CreatorTool")
+	x.CreateDate, _ = parseXMPDate(props.Get("xmp:CreateDate"))
+	x.ModifyDate, _ = parseXMPDate(props.Get("xmp:ModifyDate"))
+	x.MetadataDate, _ = parseXMPDate(props.Get("xmp:MetadataDate"))
+
+	x.DocumentID = props.Get("xmpMM:DocumentID")
+	x.InstanceID = props.Get("xmpMM:InstanceID")
+
+	x.Producer = props.Get("pdf:Producer")
+	x.Keywords = props.Get("pdf:Keywords")
+	x.PDFVersion = props.Get("pdf:PDFVersion")
+	x.Trapped = props.Get("pdf:Trapped")
+
+	x.PDFAPart, _ = strconv.Atoi(props.Get("pdfaid:part"))
+	x.PDFAConformance = props.Get("pdfaid:conformance")
+	return x, nil
+}
+
+func (d *Document) GetXMP() (XMP, error) {
+	buf := d.GetDocumentMetadata()
+	if len(buf) == 0 {
+		return XMP{}, fmt.Errorf("xmp metadata %w", ErrMissing)
+	}
+	return ParseXMP(buf)
+}
+
+// MetadataConflict reports an entry of the Info dictionary whose value
+// differs from the equivalent property of the XMP metadata.
+type MetadataConflict struct {
+	Field string
+	Info  string
+	XMP   string
+}
+
+func (d *Document) GetMetadataConflicts() []MetadataConflict {
+	x, err := d.GetXMP()
+	if err != nil {
+		return nil
+	}
+	var (
+		fi   = d.getInfoDict()
+		list []MetadataConflict
+		cmp  = func(field, info, xmp string) {
+			if info != "" && xmp != "" && info != xmp {
+				list = append(list, MetadataConflict{Field: field, Info: info, XMP: xmp})
+			}
+		}
+		date = func(field string, info, xmp time.Time) {
+			if !info.IsZero() && !xmp.IsZero() && !info.Equal(xmp) {
+				list = append(list, MetadataConflict{
+					Field: field,
+					Info:  info.Format(time.RFC3339),
+					XMP:   xmp.Format(time.RFC3339),
+				})
+			}
+		}
+	)
+	cmp("Title", fi.Title, x.Title.Default())
+	cmp("Author", fi.Author, strings.Join(x.Creators, ", "))
+	cmp("Subject", fi.Subject, x.Description.Default())
+	cmp("Keywords", d.resolveString(Dict(fi.Fields).GetString("keywords")), x.Keywords)
+	cmp("Creator", fi.Creator, x.CreatorTool)
+	cmp("Producer", fi.Producer, x.Producer)
+	cmp("Trapped", Dict(fi.Fields).GetString("trapped"), x.Trapped)
+	date("CreationDate", fi.Created, x.CreateDate)
+	date("ModDate", fi.Modified, x.ModifyDate)
+	return list
+}
+
+// mergeXMP fills the FileInfo with the properties found in the XMP metadata
+// stream. XMP values take precedence over the ones of the Info dictionary,
+// except when the Info dictionary was modified after the metadata stream.
+func (d *Document) mergeXMP(fi *FileInfo) {
+	x, err := d.GetXMP()
+	if err != nil {
+		return
+	}
+	var (
+		stale = !x.MetadataDate.IsZero() && !fi.Modified.IsZero() && fi.Modified.After(x.MetadataDate)
+		set   = func(field *string, value string) {
+			if value != "" && (*field == "" || !stale) {
+				*field = value
+			}
+		}
+	)
+	set(&fi.Title, x.Title.Default())
+	set(&fi.Author, strings.Join(x.Creators, ", "))
+	set(&fi.Subject, x.Description.Default())
+	set(&fi.Creator, x.CreatorTool)
+	set(&fi.Producer, x.Producer)
+
+	fi.DocumentID = x.DocumentID
+	fi.PDFAPart = x.PDFAPart
+	fi.PDFAConformance = x.PDFAConformance
+}
+
+var xmpTimePatterns = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+func parseXMPDate(str string) (time.Time, error) {
+	var (
+		when time.Time
+		err  error
+	)
+	for _, pat := range xmpTimePatterns {
+		if when, err = time.Parse(pat, str); err == nil {
+			break
+		}
+	}
+	return when, err
+}
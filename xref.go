@@ -0,0 +1,78 @@
+package pdf
+
+import (
+	"sort"
+)
+
+type XRefEntry struct {
+	Number     int
+	Generation int
+	// Offset is the index in the object stream Owner for compressed objects.
+	Offset int64
+	Owner  string
+	Free   bool
+	// Revision is -1 when unknown (recovered documents).
+	Revision int
+}
+
+func (e XRefEntry) Compressed() bool {
+	return e.Owner != ""
+}
+
+// XRef returns the newest entry of each object ordered by number.
+func (d *Document) XRef() []XRefEntry {
+	list := d.sortedXRef()
+	d.xmu.RLock()
+	list = append(list, d.free...)
+	d.xmu.RUnlock()
+
+	origin := d.xrefOrigin()
+	entries := make([]XRefEntry, 0, len(list))
+	for _, p := range list {
+		num, gen := parseOid(p.Oid)
+		e := XRefEntry{
+			Number:     num,
+			Generation: gen,
+			Offset:     p.Offset,
+			Owner:      p.Owner,
+			Free:       p.free,
+			Revision:   -1,
+		}
+		if rev, ok := origin[num]; ok {
+			e.Revision = rev
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Number < entries[j].Number
+	})
+	return entries
+}
+
+func (d *Document) xrefOrigin() map[int]int {
+	origin := make(map[int]int)
+	sections, err := d.readSections()
+	if err != nil {
+		return origin
+	}
+	revs, err := d.revisions(sections)
+	if err != nil {
+		return origin
+	}
+	for _, s := range sections {
+		var rev int
+		for _, r := range revs {
+			if s.Offset >= r.Start && s.Offset < r.End {
+				rev = r.Index
+				break
+			}
+		}
+		for _, p := range s.Entries {
+			origin[objectNumber(p.Oid)] = rev
+		}
+		for _, p := range s.Free {
+			origin[objectNumber(p.Oid)] = rev
+		}
+	}
+	return origin
+}